@@ -0,0 +1,143 @@
+package frames
+
+import (
+	"sync"
+	"time"
+)
+
+// AdaptiveSamplerConfig configures an AdaptiveSampler.
+type AdaptiveSamplerConfig struct {
+	// BytesPerSecond is the budget of bytes of frames kept every second.
+	BytesPerSecond int
+
+	// LowFrequency is the rate, in frames per second, up to which all frames
+	// with the same header are kept, even if it exceeds the budget.
+	LowFrequency float64
+
+	// HeaderLowFrequency overrides LowFrequency for specific headers.
+	HeaderLowFrequency map[[2]byte]float64
+}
+
+// AdaptiveSampler selects frames to be logged or recorded, adjusting the
+// fraction of frames kept so that their volume stays within a budget. Frames
+// with headers that occur rarely, e.g. status reports, are all kept, while
+// frames with frequent headers, e.g. LIDAR measurements, are sampled. Its Keep
+// method is a Filter.
+//
+// The fraction is adjusted every second, based on the traffic of the previous
+// second. Within a second, frequent frames are never kept beyond the budget.
+//
+// AdaptiveSampler is safe for concurrent use.
+type AdaptiveSampler struct {
+	config AdaptiveSamplerConfig
+
+	mu     sync.Mutex
+	window time.Time
+
+	// rates holds frames per second of every header in the previous window
+	rates map[[2]byte]float64
+
+	// counts holds the number of frames of every header in the current
+	// window, and bytes the number of bytes of rare and frequent frames
+	counts        map[[2]byte]int
+	rareBytes     int
+	frequentBytes int
+
+	// kept is the number of bytes kept in the current window
+	kept int
+
+	// ratio is the fraction of frequent frames kept; credit accumulates it
+	// until a whole frame can be kept
+	ratio  float64
+	credit float64
+}
+
+// NewAdaptiveSampler creates an AdaptiveSampler as configured by config.
+func NewAdaptiveSampler(config AdaptiveSamplerConfig) *AdaptiveSampler {
+	return &AdaptiveSampler{
+		config: config,
+		window: time.Now(),
+		rates:  make(map[[2]byte]float64),
+		counts: make(map[[2]byte]int),
+		ratio:  1,
+	}
+}
+
+// Ratio returns the fraction of frames with frequent headers that is currently
+// kept.
+func (s *AdaptiveSampler) Ratio() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ratio
+}
+
+// Keep reports whether frame should be kept.
+func (s *AdaptiveSampler) Keep(frame Frame) bool {
+	var header [2]byte
+	copy(header[:], frame)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elapsed := time.Since(s.window); elapsed >= time.Second {
+		s.adjust(elapsed)
+	}
+
+	s.counts[header]++
+	if s.rare(header) {
+		s.rareBytes += len(frame)
+		s.kept += len(frame)
+		return true
+	}
+
+	s.frequentBytes += len(frame)
+	if s.kept+len(frame) > s.config.BytesPerSecond {
+		return false
+	}
+
+	s.credit += s.ratio
+	if s.credit < 1 {
+		return false
+	}
+	s.credit--
+	s.kept += len(frame)
+
+	return true
+}
+
+// rare reports whether frames with header occur rarely enough to be all kept.
+func (s *AdaptiveSampler) rare(header [2]byte) bool {
+	limit, ok := s.config.HeaderLowFrequency[header]
+	if !ok {
+		limit = s.config.LowFrequency
+	}
+
+	return s.rates[header] <= limit && float64(s.counts[header]) <= limit
+}
+
+// adjust starts a new window, computing the ratio from the traffic in the
+// previous one, which lasted for elapsed.
+func (s *AdaptiveSampler) adjust(elapsed time.Duration) {
+	seconds := elapsed.Seconds()
+
+	s.rates = make(map[[2]byte]float64, len(s.counts))
+	for header, n := range s.counts {
+		s.rates[header] = float64(n) / seconds
+	}
+
+	s.ratio = 1
+	if s.frequentBytes > 0 {
+		available := float64(s.config.BytesPerSecond) - float64(s.rareBytes)/seconds
+		s.ratio = available / (float64(s.frequentBytes) / seconds)
+		if s.ratio > 1 {
+			s.ratio = 1
+		} else if s.ratio < 0 {
+			s.ratio = 0
+		}
+	}
+
+	s.window = time.Now()
+	s.counts = make(map[[2]byte]int, len(s.counts))
+	s.rareBytes, s.frequentBytes, s.kept = 0, 0, 0
+}
@@ -0,0 +1,50 @@
+package frames_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestAdaptiveSampler(t *testing.T) {
+	sampler := frames.NewAdaptiveSampler(frames.AdaptiveSamplerConfig{
+		BytesPerSecond: 2000,
+		LowFrequency:   5,
+		HeaderLowFrequency: map[[2]byte]float64{
+			{'E', 'R'}: math.Inf(1),
+		},
+	})
+
+	lidar := frames.Create([2]byte{'L', 'D'}, bytes.Repeat([]byte{'x'}, 94))
+	status := frames.Create([2]byte{'S', 'T'}, []byte("ok"))
+	failure := frames.Create([2]byte{'E', 'R'}, []byte("failure"))
+
+	var lidarBytes, statusKept, failureKept int
+	for i := 0; i < 1000; i++ {
+		if sampler.Keep(lidar) {
+			lidarBytes += len(lidar)
+		}
+
+		if i%200 == 0 && sampler.Keep(status) {
+			statusKept++
+		}
+
+		if i%50 == 0 && sampler.Keep(failure) {
+			failureKept++
+		}
+	}
+
+	if statusKept != 5 {
+		t.Errorf("got %d status frames kept, want 5", statusKept)
+	}
+
+	if failureKept != 20 {
+		t.Errorf("got %d failure frames kept, want 20", failureKept)
+	}
+
+	if lidarBytes == 0 || lidarBytes > 2000 {
+		t.Errorf("got %d bytes of LIDAR frames kept, want at most 2000", lidarBytes)
+	}
+}
@@ -0,0 +1,126 @@
+package frames
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrAggregatorClosed is returned by Aggregator's methods after it is closed.
+var ErrAggregatorClosed = errors.New("frames: aggregator closed")
+
+// Aggregator reads frames concurrently from several sources, e.g. redundant
+// radio links, and merges them into a single stream in the order of arrival.
+// Every frame is tagged with the name of its source.
+//
+// Aggregator is safe for concurrent use.
+type Aggregator struct {
+	envelopes chan Envelope
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu   sync.Mutex
+	errs map[string]error
+}
+
+// NewAggregator starts reading frames from sources, which map names of the
+// sources to functions reading frames from them, e.g. Session.ReadFrame. A
+// source is read until it returns an error. If dedup is not nil, frames it
+// reports as duplicates, e.g. ones already received over another link, are
+// dropped.
+func NewAggregator(sources map[string]func() (Frame, error), dedup *Dedup) *Aggregator {
+	a := &Aggregator{
+		envelopes: make(chan Envelope, subscriptionBufferSize),
+		done:      make(chan struct{}),
+		errs:      make(map[string]error),
+	}
+
+	var wg sync.WaitGroup
+	for source, read := range sources {
+		wg.Add(1)
+		go func(source string, read func() (Frame, error)) {
+			defer wg.Done()
+			a.serve(source, read, dedup)
+		}(source, read)
+	}
+
+	go func() {
+		wg.Wait()
+		close(a.envelopes)
+	}()
+
+	return a
+}
+
+// serve reads frames from a single source until it fails or the aggregator
+// is closed.
+func (a *Aggregator) serve(source string, read func() (Frame, error), dedup *Dedup) {
+	for {
+		frame, err := read()
+		if err != nil {
+			a.mu.Lock()
+			a.errs[source] = err
+			a.mu.Unlock()
+			return
+		}
+
+		if dedup != nil && dedup.Duplicate(frame) {
+			continue
+		}
+
+		envelope := Envelope{Frame: frame, Time: time.Now(), Source: source, Valid: true}
+		select {
+		case a.envelopes <- envelope:
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// ReadEnvelope returns the next frame received from any of the sources. Once
+// all sources have failed and their frames have been read, it returns io.EOF.
+// Errors of individual sources are reported by Err.
+func (a *Aggregator) ReadEnvelope() (Envelope, error) {
+	select {
+	case <-a.done:
+		return Envelope{}, ErrAggregatorClosed
+	default:
+	}
+
+	select {
+	case envelope, ok := <-a.envelopes:
+		if !ok {
+			return Envelope{}, io.EOF
+		}
+		return envelope, nil
+	case <-a.done:
+		return Envelope{}, ErrAggregatorClosed
+	}
+}
+
+// ReadFrame works like ReadEnvelope, but returns only the frame.
+func (a *Aggregator) ReadFrame() (Frame, error) {
+	envelope, err := a.ReadEnvelope()
+	return envelope.Frame, err
+}
+
+// Err returns the error that stopped reading from source, or nil if source is
+// still being read.
+func (a *Aggregator) Err(source string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.errs[source]
+}
+
+// Close stops the aggregator. Sources blocked reading a frame are not
+// interrupted, so they should be closed as well, e.g. by closing the sessions
+// they read from.
+func (a *Aggregator) Close() error {
+	a.closeOnce.Do(func() {
+		close(a.done)
+	})
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package frames_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestAggregator(t *testing.T) {
+	var stream []byte
+	for _, tc := range testCases {
+		stream = append(stream, tc.frame...)
+	}
+	extra := frames.Create([2]byte{'X', 'D'}, []byte("usb"))
+
+	sources := map[string]func() (frames.Frame, error){
+		"radio1": frames.NewReader(bytes.NewReader(stream), frames.Codec{}, frames.Limits{}).ReadFrame,
+		"radio2": frames.NewReader(bytes.NewReader(stream), frames.Codec{}, frames.Limits{}).ReadFrame,
+		"usb":    frames.NewReader(bytes.NewReader(extra), frames.Codec{}, frames.Limits{}).ReadFrame,
+	}
+
+	aggregator := frames.NewAggregator(sources, frames.NewDedup(len(testCases)+1, nil))
+	defer aggregator.Close()
+
+	counts := make(map[string]int)
+	for {
+		envelope, err := aggregator.ReadEnvelope()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		counts[string(envelope.Frame)]++
+		if bytes.Equal(envelope.Frame, extra) && envelope.Source != "usb" {
+			t.Errorf("got frame % x from %q, want it from %q", []byte(extra), envelope.Source, "usb")
+		}
+	}
+
+	for i, tc := range testCases {
+		if n := counts[string(tc.frame)]; n != 1 {
+			t.Errorf("test %d: got frame %d times, want once", i, n)
+		}
+	}
+
+	if n := counts[string(extra)]; n != 1 {
+		t.Errorf("got extra frame %d times, want once", n)
+	}
+
+	for source := range sources {
+		if err := aggregator.Err(source); err != io.EOF {
+			t.Errorf("source %q: got error %v, want %v", source, err, io.EOF)
+		}
+	}
+}
+
+func TestAggregatorClose(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+
+	sources := map[string]func() (frames.Frame, error){
+		"pipe": frames.NewReader(r, frames.Codec{}, frames.Limits{}).ReadFrame,
+	}
+
+	aggregator := frames.NewAggregator(sources, nil)
+	aggregator.Close()
+
+	if _, err := aggregator.ReadFrame(); err != frames.ErrAggregatorClosed {
+		t.Errorf("got error %v, want %v", err, frames.ErrAggregatorClosed)
+	}
+}
@@ -0,0 +1,97 @@
+package frames
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// BitErrorEstimator estimates the bit error rate of a link from the most
+// recent observations of received data: valid frames, bytes skipped while
+// resynchronizing (e.g. frames with a bad checksum) and test frames with a
+// known pattern.
+//
+// BitErrorEstimator is safe for concurrent use.
+type BitErrorEstimator struct {
+	mu      sync.Mutex
+	samples []berSample
+	next    int
+
+	// sums over samples
+	bits   uint64
+	errors uint64
+}
+
+type berSample struct {
+	bits   uint64
+	errors uint64
+}
+
+// NewBitErrorEstimator creates a BitErrorEstimator taking the last window
+// observations into account.
+func NewBitErrorEstimator(window int) *BitErrorEstimator {
+	return &BitErrorEstimator{samples: make([]berSample, 0, window)}
+}
+
+// ObserveFrame records a valid frame, received without errors.
+func (e *BitErrorEstimator) ObserveFrame(frame Frame) {
+	e.observe(berSample{bits: 8 * uint64(len(frame))})
+}
+
+// ObserveSkipped records n bytes skipped while looking for a valid frame. As
+// the number of corrupted bits among them is unknown, a single bit error is
+// assumed, so the estimate is a lower bound.
+func (e *BitErrorEstimator) ObserveSkipped(n int) {
+	e.observe(berSample{bits: 8 * uint64(n), errors: 1})
+}
+
+// ObserveTestFrame records a test frame received as got, that was sent as want.
+// Every differing bit is counted as an error, and so is every bit of bytes
+// missing from got or unexpected in got.
+func (e *BitErrorEstimator) ObserveTestFrame(got, want []byte) {
+	n, extra := len(got), len(want)-len(got)
+	if extra < 0 {
+		n, extra = len(want), -extra
+	}
+
+	sample := berSample{bits: 8 * uint64(n+extra), errors: 8 * uint64(extra)}
+	for i := 0; i < n; i++ {
+		sample.errors += uint64(bits.OnesCount8(got[i] ^ want[i]))
+	}
+
+	e.observe(sample)
+}
+
+// BitErrorRate returns the estimated ratio of corrupted bits to all received
+// bits. It returns 0 if nothing has been observed yet.
+func (e *BitErrorEstimator) BitErrorRate() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.bits == 0 {
+		return 0
+	}
+
+	return float64(e.errors) / float64(e.bits)
+}
+
+func (e *BitErrorEstimator) observe(sample berSample) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if cap(e.samples) == 0 {
+		return
+	}
+
+	if len(e.samples) < cap(e.samples) {
+		e.samples = append(e.samples, sample)
+	} else {
+		old := e.samples[e.next]
+		e.bits -= old.bits
+		e.errors -= old.errors
+		e.samples[e.next] = sample
+		e.next = (e.next + 1) % len(e.samples)
+	}
+
+	e.bits += sample.bits
+	e.errors += sample.errors
+}
@@ -0,0 +1,31 @@
+package frames_test
+
+import (
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestBitErrorEstimator(t *testing.T) {
+	e := frames.NewBitErrorEstimator(3)
+	if got := e.BitErrorRate(); got != 0 {
+		t.Errorf("got bit error rate %v before observations, want 0", got)
+	}
+
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("test"))
+	corrupted := frames.Recreate(frame)
+	corrupted[4] ^= 0x81
+
+	e.ObserveFrame(frame)                // 80 bits, 0 errors
+	e.ObserveSkipped(2)                  // 16 bits, 1 error
+	e.ObserveTestFrame(corrupted, frame) // 80 bits, 2 errors
+	if got, want := e.BitErrorRate(), 3.0/176; got != want {
+		t.Errorf("got bit error rate %v, want %v", got, want)
+	}
+
+	// the valid frame leaves the window
+	e.ObserveTestFrame(frame[:8], frame) // 80 bits, 16 errors
+	if got, want := e.BitErrorRate(), 19.0/176; got != want {
+		t.Errorf("got bit error rate %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package frames
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrBitName is returned when a Bitfield has no bit of the given name.
+var ErrBitName = errors.New("frames: unknown bit name")
+
+// Bitfield is a set of named flags carried in frames' data, e.g. a device's
+// status. Bit i is bit i%8 (counting from the least significant) of data byte
+// i/8.
+type Bitfield struct {
+	names []string
+	index map[string]int
+	data  []byte
+}
+
+// NewBitfield returns a Bitfield with all bits cleared, whose bit i is named
+// names[i]. Empty names mark reserved bits, which cannot be accessed by name.
+// The data is as many bytes as needed to hold all bits.
+func NewBitfield(names ...string) *Bitfield {
+	b := &Bitfield{
+		names: names,
+		index: make(map[string]int, len(names)),
+		data:  make([]byte, (len(names)+7)/8),
+	}
+	for i, name := range names {
+		if name != "" {
+			b.index[name] = i
+		}
+	}
+
+	return b
+}
+
+// Get reports whether the bit name is set.
+func (b *Bitfield) Get(name string) (bool, error) {
+	i, ok := b.index[name]
+	if !ok {
+		return false, ErrBitName
+	}
+
+	return b.data[i/8]&(1<<(i%8)) != 0, nil
+}
+
+// Set sets or clears the bit name.
+func (b *Bitfield) Set(name string, value bool) error {
+	i, ok := b.index[name]
+	if !ok {
+		return ErrBitName
+	}
+
+	if value {
+		b.data[i/8] |= 1 << (i % 8)
+	} else {
+		b.data[i/8] &^= 1 << (i % 8)
+	}
+
+	return nil
+}
+
+// Names returns the names of set bits in order.
+func (b *Bitfield) Names() []string {
+	var names []string
+	for i, name := range b.names {
+		if name != "" && b.data[i/8]&(1<<(i%8)) != 0 {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Bytes returns a copy of the bitfield's data.
+func (b *Bitfield) Bytes() []byte {
+	return append([]byte{}, b.data...)
+}
+
+// Create creates a new frame carrying the bitfield.
+func (b *Bitfield) Create(header [2]byte) Frame {
+	return Create(header, b.data)
+}
+
+// Decode sets the bits from frame created with Create. Reserved bits are kept
+// as received. If the frame is invalid, it returns a *VerifyError, see
+// Validate. If the frame's data length does not match the bitfield's, it
+// returns ErrPayloadLength.
+func (b *Bitfield) Decode(frame Frame) error {
+	if err := Validate(frame); err != nil {
+		return err
+	}
+
+	if len(frame.Data()) != len(b.data) {
+		return ErrPayloadLength
+	}
+	copy(b.data, frame.Data())
+
+	return nil
+}
+
+// String returns the names of set bits separated by vertical bars, e.g.
+// "ready|moving".
+func (b *Bitfield) String() string {
+	return strings.Join(b.Names(), "|")
+}
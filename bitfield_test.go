@@ -0,0 +1,64 @@
+package frames_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestBitfield(t *testing.T) {
+	status := frames.NewBitfield("ready", "moving", "", "error", "", "", "", "", "calibrated")
+
+	for _, name := range []string{"ready", "error", "calibrated"} {
+		if err := status.Set(name, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := status.Set("ready", false); err != nil {
+		t.Fatal(err)
+	}
+
+	frame := status.Create([2]byte{'S', 'T'})
+	if want := []byte{0x08, 0x01}; !bytes.Equal(frame.Data(), want) {
+		t.Fatalf("got data % x, want % x", frame.Data(), want)
+	}
+
+	received := frames.NewBitfield("ready", "moving", "", "error", "", "", "", "", "calibrated")
+	if err := received.Decode(frame); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{"ready": false, "moving": false, "error": true, "calibrated": true} {
+		if got, err := received.Get(name); err != nil || got != want {
+			t.Errorf("bit %q: got %t, %v, want %t, nil", name, got, err, want)
+		}
+	}
+	if got, want := received.String(), "error|calibrated"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestBitfieldErrors(t *testing.T) {
+	status := frames.NewBitfield("ready", "")
+
+	if _, err := status.Get(""); err != frames.ErrBitName {
+		t.Errorf("got error %v, want %v", err, frames.ErrBitName)
+	}
+	if err := status.Set("busy", true); err != frames.ErrBitName {
+		t.Errorf("got error %v, want %v", err, frames.ErrBitName)
+	}
+
+	frame := frames.Create([2]byte{'S', 'T'}, []byte{0x01, 0x00})
+	if err := status.Decode(frame); err != frames.ErrPayloadLength {
+		t.Errorf("got error %v, want %v", err, frames.ErrPayloadLength)
+	}
+
+	// reserved bits are kept
+	frame = frames.Create([2]byte{'S', 'T'}, []byte{0x03})
+	if err := status.Decode(frame); err != nil {
+		t.Fatal(err)
+	}
+	if got := status.Bytes(); !bytes.Equal(got, []byte{0x03}) {
+		t.Errorf("got data % x, want 03", got)
+	}
+}
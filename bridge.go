@@ -0,0 +1,124 @@
+package frames
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// BridgeStats holds counters describing one direction of a Bridge.
+type BridgeStats struct {
+	// Forwarded counts frames written to the other transport.
+	Forwarded uint64
+
+	// BytesForwarded counts bytes of forwarded frames.
+	BytesForwarded uint64
+
+	// Filtered counts frames rejected by the direction's filter.
+	Filtered uint64
+
+	// SkippedBytes counts received bytes that were not part of any valid
+	// frame. They are not forwarded.
+	SkippedBytes uint64
+}
+
+// BridgeConfig configures a Bridge.
+type BridgeConfig struct {
+	// FilterAB and FilterBA, if not nil, select frames forwarded from A to B
+	// and from B to A.
+	FilterAB Filter
+	FilterBA Filter
+
+	// RewriteAB and RewriteBA, if not nil, transform frames accepted by the
+	// filters before forwarding them, e.g. HeaderMap.Rewrite or
+	// RemapTable.Rewrite turns the bridge into a gateway rewriting headers.
+	RewriteAB func(Frame) Frame
+	RewriteBA func(Frame) Frame
+}
+
+// Bridge forwards frames in both directions between two transports, e.g. a
+// serial port and a TCP connection.
+type Bridge struct {
+	ab BridgeStats // first fields for 64-bit alignment of atomic operations
+	ba BridgeStats
+
+	a, b   io.ReadWriteCloser
+	config BridgeConfig
+}
+
+// NewBridge creates a Bridge between a and b. Forwarding starts with Run.
+func NewBridge(a, b io.ReadWriteCloser, config BridgeConfig) *Bridge {
+	return &Bridge{a: a, b: b, config: config}
+}
+
+// Run forwards frames until reading or writing fails in either direction. Both
+// transports are then closed. Run returns the first error, or nil if it was
+// io.EOF.
+func (br *Bridge) Run() error {
+	errs := make(chan error, 2)
+	var once sync.Once
+	stop := func(err error) {
+		errs <- err
+		once.Do(func() {
+			br.a.Close()
+			br.b.Close()
+		})
+	}
+
+	go func() { stop(forward(br.a, br.b, br.config.FilterAB, br.config.RewriteAB, &br.ab)) }()
+	go func() { stop(forward(br.b, br.a, br.config.FilterBA, br.config.RewriteBA, &br.ba)) }()
+
+	err := <-errs
+	<-errs
+
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// Stats returns the current values of the counters of both directions.
+func (br *Bridge) Stats() (ab, ba BridgeStats) {
+	return br.ab.load(), br.ba.load()
+}
+
+func (s *BridgeStats) load() BridgeStats {
+	return BridgeStats{
+		Forwarded:      atomic.LoadUint64(&s.Forwarded),
+		BytesForwarded: atomic.LoadUint64(&s.BytesForwarded),
+		Filtered:       atomic.LoadUint64(&s.Filtered),
+		SkippedBytes:   atomic.LoadUint64(&s.SkippedBytes),
+	}
+}
+
+// forward copies frames accepted by filter from src to dst, transformed by
+// rewrite.
+func forward(src io.Reader, dst io.Writer, filter Filter, rewrite func(Frame) Frame, stats *BridgeStats) error {
+	reader := newFrameReader(src, Codec{}, Limits{})
+	reader.onSkip = func(n int) {
+		atomic.AddUint64(&stats.SkippedBytes, uint64(n))
+	}
+
+	for {
+		frame, err := reader.ReadFrame()
+		if err != nil {
+			return err
+		}
+
+		if filter != nil && !filter(frame) {
+			atomic.AddUint64(&stats.Filtered, 1)
+			continue
+		}
+
+		if rewrite != nil {
+			frame = rewrite(frame)
+		}
+
+		if _, err := dst.Write(frame); err != nil {
+			return err
+		}
+		atomic.AddUint64(&stats.Forwarded, 1)
+		atomic.AddUint64(&stats.BytesForwarded, uint64(len(frame)))
+	}
+}
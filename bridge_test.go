@@ -0,0 +1,64 @@
+package frames_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestBridge(t *testing.T) {
+	deviceA, bridgeA := net.Pipe()
+	deviceB, bridgeB := net.Pipe()
+
+	onlyLD, err := frames.ParseFilter(`header == "LD"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bridge := frames.NewBridge(bridgeA, bridgeB, frames.BridgeConfig{FilterBA: onlyLD})
+	done := make(chan error)
+	go func() { done <- bridge.Run() }()
+
+	lamp := frames.Create([2]byte{'L', 'D'}, []byte("on"))
+	motor := frames.Create([2]byte{'M', 'T'}, []byte{0x10})
+
+	// A to B, with garbage
+	go deviceA.Write(append(frames.Frame{'x', 'd'}, motor...))
+	got := make([]byte, len(motor))
+	if _, err := io.ReadFull(deviceB, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, motor) {
+		t.Errorf("got % x from A, want % x", got, []byte(motor))
+	}
+
+	// B to A, the motor frame is filtered out
+	go deviceB.Write(append(motor, lamp...))
+	got = make([]byte, len(lamp))
+	if _, err := io.ReadFull(deviceA, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, lamp) {
+		t.Errorf("got % x from B, want % x", got, []byte(lamp))
+	}
+
+	deviceA.Close()
+	if err := <-done; err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+
+	ab, ba := bridge.Stats()
+	if want := (frames.BridgeStats{Forwarded: 1, BytesForwarded: uint64(len(motor)), SkippedBytes: 2}); ab != want {
+		t.Errorf("got A to B stats %+v, want %+v", ab, want)
+	}
+	if want := (frames.BridgeStats{Forwarded: 1, BytesForwarded: uint64(len(lamp)), Filtered: 1}); ba != want {
+		t.Errorf("got B to A stats %+v, want %+v", ba, want)
+	}
+
+	if _, err := deviceB.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("got error %v reading from B, want %v", err, io.EOF)
+	}
+}
@@ -0,0 +1,123 @@
+package frames
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// BroadcastStats holds counters describing writes to one target of a
+// Broadcaster.
+type BroadcastStats struct {
+	// FramesWritten counts frames written to the target.
+	FramesWritten uint64
+
+	// BytesWritten counts bytes of written frames.
+	BytesWritten uint64
+
+	// Failures counts frames that could not be written to the target.
+	Failures uint64
+}
+
+// BroadcastError is returned by Broadcaster.WriteFrame when the frame could not
+// be written to some of the targets.
+type BroadcastError struct {
+	// Errs maps names of the failed targets to their errors.
+	Errs map[string]error
+}
+
+func (e *BroadcastError) Error() string {
+	names := make([]string, 0, len(e.Errs))
+	for name := range e.Errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msgs := make([]string, len(names))
+	for i, name := range names {
+		msgs[i] = fmt.Sprintf("%s: %v", name, e.Errs[name])
+	}
+
+	return "frames: broadcast failed: " + strings.Join(msgs, "; ")
+}
+
+// broadcastTarget is a single target of a Broadcaster.
+type broadcastTarget struct {
+	stats BroadcastStats // first field for 64-bit alignment of atomic operations
+
+	writer *Writer
+}
+
+// Broadcaster writes every frame to several targets, e.g. a local log file and
+// a radio uplink. A target that fails does not prevent writing to the others.
+//
+// Broadcaster is safe for concurrent use.
+type Broadcaster struct {
+	targets map[string]*broadcastTarget
+}
+
+// NewBroadcaster creates a Broadcaster writing to targets, which map names of
+// the targets to writers.
+func NewBroadcaster(targets map[string]io.Writer) *Broadcaster {
+	b := &Broadcaster{targets: make(map[string]*broadcastTarget, len(targets))}
+	for name, w := range targets {
+		b.targets[name] = &broadcastTarget{writer: NewWriter(w)}
+	}
+
+	return b
+}
+
+// WriteFrame writes frame to all targets concurrently and waits until all
+// writes are finished. If some of them fail, it returns a *BroadcastError.
+func (b *Broadcaster) WriteFrame(frame Frame) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := make(map[string]error)
+
+	for name, target := range b.targets {
+		wg.Add(1)
+		go func(name string, target *broadcastTarget) {
+			defer wg.Done()
+
+			if err := target.writer.WriteFrame(frame); err != nil {
+				atomic.AddUint64(&target.stats.Failures, 1)
+
+				mu.Lock()
+				errs[name] = err
+				mu.Unlock()
+				return
+			}
+
+			atomic.AddUint64(&target.stats.FramesWritten, 1)
+			atomic.AddUint64(&target.stats.BytesWritten, uint64(len(frame)))
+		}(name, target)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return &BroadcastError{Errs: errs}
+	}
+
+	return nil
+}
+
+// Stats returns the current values of the counters of the target called name.
+func (b *Broadcaster) Stats(name string) BroadcastStats {
+	target, ok := b.targets[name]
+	if !ok {
+		return BroadcastStats{}
+	}
+
+	return target.stats.load()
+}
+
+func (s *BroadcastStats) load() BroadcastStats {
+	return BroadcastStats{
+		FramesWritten: atomic.LoadUint64(&s.FramesWritten),
+		BytesWritten:  atomic.LoadUint64(&s.BytesWritten),
+		Failures:      atomic.LoadUint64(&s.Failures),
+	}
+}
@@ -0,0 +1,54 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestBroadcaster(t *testing.T) {
+	var log, radio bytes.Buffer
+	broadcaster := frames.NewBroadcaster(map[string]io.Writer{
+		"log":    &log,
+		"radio":  &radio,
+		"broken": failingWriter{},
+	})
+
+	var want []byte
+	for i, tc := range testCases {
+		err := broadcaster.WriteFrame(tc.frame)
+
+		var broadcastErr *frames.BroadcastError
+		if !errors.As(err, &broadcastErr) || len(broadcastErr.Errs) != 1 || broadcastErr.Errs["broken"] != io.ErrClosedPipe {
+			t.Fatalf("test %d: got error %v, want a failure of broken", i, err)
+		}
+
+		want = append(want, tc.frame...)
+	}
+
+	if !bytes.Equal(log.Bytes(), want) || !bytes.Equal(radio.Bytes(), want) {
+		t.Errorf("got log % x and radio % x, want % x", log.Bytes(), radio.Bytes(), want)
+	}
+
+	wantStats := frames.BroadcastStats{FramesWritten: uint64(len(testCases)), BytesWritten: uint64(len(want))}
+	for _, name := range []string{"log", "radio"} {
+		if got := broadcaster.Stats(name); got != wantStats {
+			t.Errorf("%s: got stats %+v, want %+v", name, got, wantStats)
+		}
+	}
+
+	wantStats = frames.BroadcastStats{Failures: uint64(len(testCases))}
+	if got := broadcaster.Stats("broken"); got != wantStats {
+		t.Errorf("broken: got stats %+v, want %+v", got, wantStats)
+	}
+}
@@ -0,0 +1,92 @@
+package frames
+
+import "sync"
+
+// Broker is a lightweight in-process publish/subscribe broker. Frames are
+// published to topics derived from them, by default from their headers, and
+// every subscriber receives the frames of a single topic. Unlike Mux, routing
+// a frame costs a single lookup regardless of the number of subscribers.
+//
+// Broker is safe for concurrent use.
+type Broker struct {
+	topic func(Frame) string
+
+	mu   sync.Mutex
+	subs map[string]map[chan Frame]struct{}
+}
+
+// NewBroker creates a new Broker without any subscribers. If topic is nil,
+// frame's header is its topic.
+func NewBroker(topic func(Frame) string) *Broker {
+	if topic == nil {
+		topic = headerTopic
+	}
+
+	return &Broker{topic: topic, subs: make(map[string]map[chan Frame]struct{})}
+}
+
+// headerTopic returns frame's header as a string, or an empty string if the
+// frame is too short to have a header.
+func headerTopic(frame Frame) string {
+	if len(frame) < 2 {
+		return ""
+	}
+
+	return string(frame.Header())
+}
+
+// Subscribe returns a channel receiving frames published to topic. Calling
+// cancel unsubscribes and closes the channel.
+func (b *Broker) Subscribe(topic string) (frames <-chan Frame, cancel func()) {
+	c := make(chan Frame, subscriptionBufferSize)
+
+	b.mu.Lock()
+	if b.subs[topic] == nil {
+		b.subs[topic] = make(map[chan Frame]struct{})
+	}
+	b.subs[topic][c] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return c, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subs[topic], c)
+			if len(b.subs[topic]) == 0 {
+				delete(b.subs, topic)
+			}
+			close(c)
+		})
+	}
+}
+
+// Publish delivers frame to all subscribers of its topic. It never blocks:
+// subscribers that do not keep up miss frames.
+func (b *Broker) Publish(frame Frame) {
+	topic := b.topic(frame)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for c := range b.subs[topic] {
+		select {
+		case c <- frame:
+		default:
+		}
+	}
+}
+
+// Serve reads frames with read, e.g. Session.ReadFrame, and publishes them
+// until read returns an error, which is then returned.
+func (b *Broker) Serve(read func() (Frame, error)) error {
+	for {
+		frame, err := read()
+		if err != nil {
+			return err
+		}
+
+		b.Publish(frame)
+	}
+}
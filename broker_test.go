@@ -0,0 +1,66 @@
+package frames_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestBroker(t *testing.T) {
+	broker := frames.NewBroker(nil)
+
+	ld1, cancel1 := broker.Subscribe("LD")
+	defer cancel1()
+	ld2, cancel2 := broker.Subscribe("LD")
+	mt, cancelMT := broker.Subscribe("MT")
+	defer cancelMT()
+
+	lamp := frames.Create([2]byte{'L', 'D'}, []byte("on"))
+	motor := frames.Create([2]byte{'M', 'T'}, []byte{0x10})
+
+	broker.Publish(lamp)
+	broker.Publish(motor)
+	broker.Publish(frames.Frame{'x'})
+
+	for _, c := range []<-chan frames.Frame{ld1, ld2} {
+		if got := <-c; !bytes.Equal(got, lamp) {
+			t.Errorf("got frame % x, want frame % x", []byte(got), []byte(lamp))
+		}
+	}
+
+	if got := <-mt; !bytes.Equal(got, motor) {
+		t.Errorf("got frame % x, want frame % x", []byte(got), []byte(motor))
+	}
+
+	cancel2()
+	cancel2()
+	broker.Publish(lamp)
+
+	if _, ok := <-ld2; ok {
+		t.Error("got frame from cancelled subscription")
+	}
+	if got := <-ld1; !bytes.Equal(got, lamp) {
+		t.Errorf("got frame % x, want frame % x", []byte(got), []byte(lamp))
+	}
+	if n := len(mt); n != 0 {
+		t.Errorf("got %d unexpected frames in MT subscription", n)
+	}
+}
+
+func TestBrokerTopic(t *testing.T) {
+	// topics by the first data byte, e.g. a device address
+	broker := frames.NewBroker(func(frame frames.Frame) string {
+		return string(frame.Data()[:1])
+	})
+
+	c, cancel := broker.Subscribe("\x01")
+	defer cancel()
+
+	broker.Publish(frames.Create([2]byte{'L', 'D'}, []byte{0x02, 'a'}))
+	broker.Publish(frames.Create([2]byte{'M', 'T'}, []byte{0x01, 'b'}))
+
+	if got := <-c; string(got.Header()) != "MT" {
+		t.Errorf("got frame % x, want MT frame", []byte(got))
+	}
+}
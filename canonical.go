@@ -0,0 +1,75 @@
+package frames
+
+import (
+	"encoding/hex"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Canonical returns the canonical text representation of frame: a single line
+// of space-separated key=value fields, sorted by key, with fixed formatting,
+// e.g.
+//
+//	checksum=12 data=74657374 header="LD" length=4 valid=true
+//
+// Fields are checksum (in hex), data (in hex), header (quoted), length and
+// valid. Invalid frames additionally carry all their bytes in hex as raw.
+// Frames shorter than an empty frame carry only raw and valid.
+//
+// The representation is stable, so captures written with WriteCanonical can
+// be compared with plain diff, e.g. in regression tests.
+func Canonical(frame Frame) string {
+	return joinCanonical(canonicalFields(frame))
+}
+
+// WriteCanonical writes records to w, one per line, in the canonical text
+// representation of Canonical with an additional direction field. Timestamps
+// are omitted, as they differ between captures.
+func WriteCanonical(w io.Writer, records []Record) error {
+	for _, record := range records {
+		fields := append(canonicalFields(record.Frame), [2]string{"direction", record.Direction.String()})
+		if _, err := io.WriteString(w, joinCanonical(fields)+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// canonicalFields returns the keys and values of frame's fields.
+func canonicalFields(frame Frame) [][2]string {
+	ok := Verify(frame)
+	valid := strconv.FormatBool(ok)
+	if len(frame) < minFrameLen {
+		return [][2]string{{"raw", hex.EncodeToString(frame)}, {"valid", valid}}
+	}
+
+	fields := [][2]string{
+		{"checksum", hex.EncodeToString([]byte{frame.Checksum()})},
+		{"data", hex.EncodeToString(frame.Data())},
+		{"header", strconv.Quote(string(frame.Header()))},
+		{"length", strconv.Itoa(frame.LenData())},
+		{"valid", valid},
+	}
+	if !ok {
+		fields = append(fields, [2]string{"raw", hex.EncodeToString(frame)})
+	}
+
+	return fields
+}
+
+// joinCanonical sorts fields by key and joins them into a single line.
+func joinCanonical(fields [][2]string) string {
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i][0] < fields[j][0]
+	})
+
+	pairs := make([]string, len(fields))
+	for i, field := range fields {
+		pairs[i] = field[0] + "=" + field[1]
+	}
+
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,58 @@
+package frames_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		frame frames.Frame
+		want  string
+	}{
+		{
+			frame: frames.Frame{'L', 'D', 0x04, '+', 't', 'e', 's', 't', '#', 0x12},
+			want:  `checksum=12 data=74657374 header="LD" length=4 valid=true`,
+		},
+		{
+			frame: frames.Frame{'L', 'D', 0x0, '+', '#', 0x00},
+			want:  `checksum=00 data= header="LD" length=0 valid=true`,
+		},
+		{
+			frame: frames.Frame{'L', 'd', 0x1, '+', 'A', '#', 0x60},
+			want:  `checksum=60 data=41 header="Ld" length=1 raw=4c64012b412360 valid=false`,
+		},
+		{
+			frame: frames.Frame{'x', 'd'},
+			want:  `raw=7864 valid=false`,
+		},
+	}
+
+	for i, test := range tests {
+		if got := frames.Canonical(test.frame); got != test.want {
+			t.Errorf("test %d: got %s, want %s", i, got, test.want)
+		}
+	}
+}
+
+func TestWriteCanonical(t *testing.T) {
+	records := []frames.Record{
+		{Direction: frames.Received, Frame: frames.Create([2]byte{'M', 'T'}, []byte("dondu"))},
+		{Direction: frames.Sent, Frame: frames.Frame{'x', 'd'}},
+	}
+
+	want := "" +
+		`checksum=60 data=646f6e6475 direction=rx header="MT" length=5 valid=true` + "\n" +
+		`direction=tx raw=7864 valid=false` + "\n"
+
+	var buf bytes.Buffer
+	if err := frames.WriteCanonical(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Errorf("got dump\n%s\nwant dump\n%s", got, want)
+	}
+}
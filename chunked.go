@@ -0,0 +1,63 @@
+package frames
+
+import (
+	"io"
+	"math"
+)
+
+// StreamReader returns a function returning consecutive frames with header,
+// carrying successive chunks of at most chunk bytes read from r, e.g. a file
+// being transferred. Every chunk is non-empty, and after r ends, a frame with
+// empty data marks the end of the stream. Then the function returns io.EOF.
+//
+// If chunk does not fit in the length byte, the function returns
+// ErrDataLength. Errors of r other than io.EOF are returned as is.
+func StreamReader(header [2]byte, r io.Reader, chunk int) func() (Frame, error) {
+	if chunk <= 0 || chunk > math.MaxUint8 {
+		return func() (Frame, error) {
+			return nil, ErrDataLength
+		}
+	}
+
+	buf := make([]byte, chunk)
+	done := false
+
+	return func() (Frame, error) {
+		if done {
+			return nil, io.EOF
+		}
+
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if n == 0 {
+			done = true
+		}
+
+		return Create(header, buf[:n]), nil
+	}
+}
+
+// WriteStream writes the frames returned by StreamReader to w, including the
+// end marker.
+func WriteStream(w io.Writer, header [2]byte, r io.Reader, chunk int) error {
+	next := StreamReader(header, r, chunk)
+	for {
+		frame, err := next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := w.Write(frame); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package frames_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestStreamReader(t *testing.T) {
+	content := bytes.Repeat([]byte("firmware"), 5)
+	header := [2]byte{'F', 'W'}
+
+	next := frames.StreamReader(header, bytes.NewReader(content), 16)
+
+	var got []byte
+	var chunks int
+	for {
+		frame, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !frames.Verify(frame) || string(frame.Header()) != "FW" {
+			t.Fatalf("got frame % x, want a valid FW frame", []byte(frame))
+		}
+
+		if frame.LenData() == 0 {
+			break
+		}
+		got = append(got, frame.Data()...)
+		chunks++
+	}
+
+	if !bytes.Equal(got, content) || chunks != 3 {
+		t.Errorf("got %d chunks of %q, want 3 chunks of %q", chunks, got, content)
+	}
+
+	if _, err := next(); err != io.EOF {
+		t.Errorf("got error %v, want %v", err, io.EOF)
+	}
+}
+
+func TestWriteStream(t *testing.T) {
+	var buf bytes.Buffer
+	if err := frames.WriteStream(&buf, [2]byte{'F', 'W'}, bytes.NewReader([]byte("abc")), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	var want []byte
+	want = append(want, frames.Create([2]byte{'F', 'W'}, []byte("ab"))...)
+	want = append(want, frames.Create([2]byte{'F', 'W'}, []byte("c"))...)
+	want = append(want, frames.Create([2]byte{'F', 'W'}, nil)...)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("got stream % x, want % x", buf.Bytes(), want)
+	}
+
+	if err := frames.WriteStream(&buf, [2]byte{'F', 'W'}, bytes.NewReader(nil), 256); err != frames.ErrDataLength {
+		t.Errorf("got error %v, want %v", err, frames.ErrDataLength)
+	}
+}
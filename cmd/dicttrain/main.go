@@ -0,0 +1,143 @@
+// Dicttrain trains a frames.Dictionary from captures, so that data of tiny
+// frames can be compressed with it.
+//
+// Usage:
+//
+//	dicttrain [-n entries] [-header XX] [-size bytes] [-o file] capture...
+//
+// Captures are in the format written by frames.FlightRecorder.Dump. Data of
+// every valid frame, optionally only of frames with the given header, is a
+// training sample. Since training time grows with the number of entries times
+// the size of samples, only the first samples up to the given total size, 256
+// KiB by default, are used; training on them takes seconds. The dictionary is written to the output file, or to the
+// standard output, in the format read by frames.ReadDictionary. The
+// compression ratio achieved on the samples is reported on the standard error.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/knei-knurow/frames"
+)
+
+// defaultSize is the default limit of the total size of training samples.
+const defaultSize = 256 << 10
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("dicttrain: ")
+
+	n := flag.Int("n", frames.MaxDictionaryEntries, "maximum number of dictionary `entries`")
+	header := flag.String("header", "", "use only frames with this `header`")
+	size := flag.Int("size", defaultSize, "use at most this many `bytes` of samples")
+	output := flag.String("o", "", "write the dictionary to `file` instead of the standard output")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		log.Fatal("no captures")
+	}
+	if *header != "" && len(*header) != 2 {
+		log.Fatalf("header %q is not 2 bytes long", *header)
+	}
+
+	var samples [][]byte
+	for _, path := range flag.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		records, err := frames.ReadRecords(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+
+		samples = append(samples, collect(records, *header)...)
+	}
+
+	if limited := limit(samples, *size); len(limited) < len(samples) {
+		log.Printf("using the first %d of %d samples", len(limited), len(samples))
+		samples = limited
+	}
+
+	dict, ratio, err := train(samples, *n)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if _, err := dict.WriteTo(w); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("%d samples, %d entries, compressed to %.1f%%", len(samples), len(dict.Entries()), 100*ratio)
+}
+
+// collect returns data of valid frames among records, only those with header
+// if it is not empty.
+func collect(records []frames.Record, header string) [][]byte {
+	var samples [][]byte
+	for _, record := range records {
+		if !frames.Verify(record.Frame) {
+			continue
+		}
+		if header != "" && !bytes.Equal(record.Frame.Header(), []byte(header)) {
+			continue
+		}
+
+		samples = append(samples, record.Frame.Data())
+	}
+
+	return samples
+}
+
+// limit returns the longest prefix of samples whose total length does not
+// exceed size.
+func limit(samples [][]byte, size int) [][]byte {
+	total := 0
+	for i, sample := range samples {
+		total += len(sample)
+		if total > size {
+			return samples[:i]
+		}
+	}
+
+	return samples
+}
+
+// train trains a dictionary of at most n entries on samples and returns it
+// together with the ratio of compressed to original size of samples.
+func train(samples [][]byte, n int) (*frames.Dictionary, float64, error) {
+	dict, err := frames.TrainDictionary(samples, n)
+	if err != nil {
+		return nil, 0, fmt.Errorf("training: %w", err)
+	}
+
+	original, compressed := 0, 0
+	for _, sample := range samples {
+		original += len(sample)
+		compressed += len(dict.Compress(sample))
+	}
+
+	ratio := 1.0
+	if original > 0 {
+		ratio = float64(compressed) / float64(original)
+	}
+
+	return dict, ratio, nil
+}
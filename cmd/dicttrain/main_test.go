@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestTrain(t *testing.T) {
+	var records []frames.Record
+	for i := 0; i < 20; i++ {
+		records = append(records,
+			frames.Record{Frame: frames.Create([2]byte{'C', 'M'}, []byte("status=ok&mode=scan"))},
+			frames.Record{Frame: frames.Create([2]byte{'L', 'D'}, []byte{byte(i), 0x00, 0x10})},
+		)
+	}
+	corrupted := frames.Create([2]byte{'C', 'M'}, []byte("status=error"))
+	corrupted[len(corrupted)-1] ^= 0xff
+	records = append(records, frames.Record{Frame: corrupted})
+
+	samples := collect(records, "CM")
+	if len(samples) != 20 {
+		t.Fatalf("got %d samples, want 20", len(samples))
+	}
+
+	dict, ratio, err := train(samples, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dict.Entries()) == 0 || len(dict.Entries()) > 4 {
+		t.Errorf("got %d entries, want 1 to 4", len(dict.Entries()))
+	}
+	if ratio >= 0.5 {
+		t.Errorf("got ratio %.2f, want less than 0.5", ratio)
+	}
+
+	for _, sample := range samples {
+		got, err := dict.Decompress(dict.Compress(sample))
+		if err != nil || !bytes.Equal(got, sample) {
+			t.Errorf("got %q, %v, want %q, nil", got, err, sample)
+		}
+	}
+}
+
+func TestLimit(t *testing.T) {
+	samples := [][]byte{[]byte("abc"), []byte("de"), []byte("fghi")}
+	for _, tt := range []struct {
+		size int
+		want int
+	}{{0, 0}, {4, 1}, {5, 2}, {9, 3}, {100, 3}} {
+		if got := limit(samples, tt.size); len(got) != tt.want {
+			t.Errorf("size %d: got %d samples, want %d", tt.size, len(got), tt.want)
+		}
+	}
+}
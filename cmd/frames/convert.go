@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+// captureFormats describes the formats of captures read and written by
+// "frames convert".
+const captureFormats = `formats:
+	raw      frames' bytes, one after another; bytes between frames are lost
+	hex      a frame in hex per line
+	base64   a frame in base64 per line
+	records  a record per line, as written by FlightRecorder.Dump
+	jsonl    a JSON object per line, with keys time, direction and frame
+	csv      rows written by WriteCSV; it cannot be read
+`
+
+// jsonRecord is a line of a capture in the jsonl format.
+type jsonRecord struct {
+	Time      string `json:"time,omitempty"`
+	Direction string `json:"direction"`
+	Frame     string `json:"frame"`
+}
+
+// runConvert implements "frames convert", which converts captures between
+// formats, so that they can be moved between tools, pasted into tickets and
+// the like. Records read from formats without timestamps or directions have
+// zero times and are received.
+func runConvert(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("convert", "[input [output]]")
+	from := fs.String("from", "records", "`format` of the input")
+	to := fs.String("to", "jsonl", "`format` of the output")
+	usage := fs.Usage
+	fs.Usage = func() {
+		usage()
+		fmt.Fprint(fs.Output(), captureFormats)
+	}
+	if err := parse(fs, args, 0, 2); err != nil {
+		return err
+	}
+
+	r, w := stdin, stdout
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	records, err := readCapture(r, *from)
+	if err != nil {
+		return err
+	}
+
+	if fs.NArg() > 1 {
+		f, err := os.Create(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := writeCapture(bw, *to, records); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// readCapture reads records from r in format.
+func readCapture(r io.Reader, format string) ([]frames.Record, error) {
+	var decode func(line string) (frames.Record, error)
+	switch format {
+	case "raw":
+		var records []frames.Record
+		reader := frames.NewReader(r, frames.Codec{}, frames.Limits{})
+		for {
+			frame, err := reader.ReadFrame()
+			if err == io.EOF {
+				return records, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, frames.Record{Frame: frame})
+		}
+	case "records":
+		return frames.ReadRecords(r)
+	case "hex":
+		decode = func(line string) (frames.Record, error) {
+			frame, err := hex.DecodeString(line)
+			return frames.Record{Frame: frame}, err
+		}
+	case "base64":
+		decode = func(line string) (frames.Record, error) {
+			frame, err := base64.StdEncoding.DecodeString(line)
+			return frames.Record{Frame: frame}, err
+		}
+	case "jsonl":
+		decode = decodeJSONRecord
+	default:
+		return nil, fmt.Errorf("cannot read format %q", format)
+	}
+
+	var records []frames.Record
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		record, err := decode(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+// decodeJSONRecord decodes a line of a capture in the jsonl format.
+func decodeJSONRecord(line string) (record frames.Record, err error) {
+	var decoded jsonRecord
+	if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+		return record, err
+	}
+
+	if decoded.Time != "" {
+		if record.Time, err = time.Parse(time.RFC3339Nano, decoded.Time); err != nil {
+			return record, err
+		}
+	}
+
+	switch decoded.Direction {
+	case frames.Received.String(), "":
+		record.Direction = frames.Received
+	case frames.Sent.String():
+		record.Direction = frames.Sent
+	default:
+		return record, fmt.Errorf("invalid direction %q", decoded.Direction)
+	}
+
+	record.Frame, err = hex.DecodeString(decoded.Frame)
+	return record, err
+}
+
+// writeCapture writes records to w in format.
+func writeCapture(w io.Writer, format string, records []frames.Record) error {
+	var encode func(record frames.Record) (string, error)
+	switch format {
+	case "raw":
+		for _, record := range records {
+			if _, err := w.Write(record.Frame); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		return frames.WriteCSV(w, records)
+	case "hex":
+		encode = func(record frames.Record) (string, error) {
+			return hex.EncodeToString(record.Frame), nil
+		}
+	case "base64":
+		encode = func(record frames.Record) (string, error) {
+			return base64.StdEncoding.EncodeToString(record.Frame), nil
+		}
+	case "records":
+		encode = func(record frames.Record) (string, error) {
+			return fmt.Sprintf("%s %s %x", record.Time.Format(time.RFC3339Nano), record.Direction, []byte(record.Frame)), nil
+		}
+	case "jsonl":
+		encode = encodeJSONRecord
+	default:
+		return fmt.Errorf("cannot write format %q", format)
+	}
+
+	for _, record := range records {
+		line, err := encode(record)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeJSONRecord encodes record as a line of a capture in the jsonl format.
+func encodeJSONRecord(record frames.Record) (string, error) {
+	encoded := jsonRecord{Direction: record.Direction.String(), Frame: hex.EncodeToString(record.Frame)}
+	if !record.Time.IsZero() {
+		encoded.Time = record.Time.Format(time.RFC3339Nano)
+	}
+
+	line, err := json.Marshal(encoded)
+	if err != nil {
+		return "", fmt.Errorf("encoding record: %w", err)
+	}
+
+	return string(line), nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestConvert(t *testing.T) {
+	records := []frames.Record{
+		{Time: time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC), Direction: frames.Sent, Frame: frames.Create([2]byte{'L', 'D'}, []byte("dondu"))},
+		{Time: time.Date(2021, 3, 14, 15, 9, 27, 0, time.UTC), Direction: frames.Received, Frame: frames.Create([2]byte{'M', 'T'}, nil)},
+	}
+
+	var dump strings.Builder
+	if err := writeCapture(&dump, "records", records); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		format string
+		output string
+		timed  bool
+	}{
+		{"hex", "4c44052b646f6e64752371\n4d54002b2311\n", false},
+		{"base64", "TEQFK2RvbmR1I3E=\nTVQAKyMR\n", false},
+		{"raw", "LD\x05+dondu#qMT\x00+#\x11", false},
+		{"jsonl", `{"time":"2021-03-14T15:09:26Z","direction":"tx","frame":"4c44052b646f6e64752371"}` + "\n" +
+			`{"time":"2021-03-14T15:09:27Z","direction":"rx","frame":"4d54002b2311"}` + "\n", true},
+		{"records", dump.String(), true},
+	}
+
+	for _, tt := range tests {
+		var out strings.Builder
+		if err := runConvert([]string{"-to", tt.format}, strings.NewReader(dump.String()), &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tt.output {
+			t.Errorf("%s: got %q, want %q", tt.format, out.String(), tt.output)
+		}
+
+		// and back
+		read, err := readCapture(strings.NewReader(out.String()), tt.format)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if len(read) != len(records) {
+			t.Fatalf("%s: got %d records, want %d", tt.format, len(read), len(records))
+		}
+		for i, record := range read {
+			want := records[i]
+			if !tt.timed {
+				want.Time, want.Direction = time.Time{}, frames.Received
+			}
+			if !record.Time.Equal(want.Time) || record.Direction != want.Direction || string(record.Frame) != string(want.Frame) {
+				t.Errorf("%s: got record %+v, want %+v", tt.format, record, want)
+			}
+		}
+	}
+}
+
+func TestConvertFiles(t *testing.T) {
+	dir := t.TempDir()
+	input, output := filepath.Join(dir, "capture.hex"), filepath.Join(dir, "capture.b64")
+	if err := os.WriteFile(input, []byte("4d54002b2311\n\n4d54002b2311\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runConvert([]string{"-from", "hex", "-to", "base64", input, output}, nil, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := os.ReadFile(output); err != nil || string(got) != "TVQAKyMR\nTVQAKyMR\n" {
+		t.Errorf("got %q, %v, want %q, nil", got, err, "TVQAKyMR\nTVQAKyMR\n")
+	}
+
+	if err := runConvert([]string{filepath.Join(dir, "missing")}, nil, io.Discard); err == nil {
+		t.Error("got no error reading a missing file")
+	}
+}
+
+func TestConvertCSV(t *testing.T) {
+	var out strings.Builder
+	if err := runConvert([]string{"-from", "hex", "-to", "csv"}, strings.NewReader("4d54002b2311\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "timestamp,direction,header,length,data,checksum_ok\n0001-01-01T00:00:00Z,rx,MT,0,,true\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+
+	if err := runConvert([]string{"-from", "csv"}, strings.NewReader(out.String()), io.Discard); err == nil {
+		t.Error("got no error reading csv")
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	for _, tt := range []struct {
+		format string
+		input  string
+		err    string
+	}{
+		{"hex", "4d54\nxyz\n", "line 2: "},
+		{"base64", "!!!\n", "line 1: "},
+		{"jsonl", `{"direction":"up","frame":""}` + "\n", `line 1: invalid direction "up"`},
+		{"jsonl", `{"time":"yesterday","frame":""}` + "\n", "line 1: "},
+		{"records", "xyz\n", frames.ErrRecordFormat.Error()},
+	} {
+		_, err := readCapture(strings.NewReader(tt.input), tt.format)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+			t.Errorf("%s %q: got error %v, want one starting with %q", tt.format, tt.input, err, tt.err)
+		}
+	}
+
+	if err := writeCapture(io.Discard, "xml", nil); err == nil {
+		t.Error("got no error writing xml")
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/knei-knurow/frames"
+)
+
+// runDiff implements "frames diff", which compares an expected frame with a
+// captured one and prints the bytes that differ, region by region. Each frame
+// is given as the path of a file holding its bytes or, if no such file exists,
+// in hex. It fails if the frames differ, so that it can be used in scripts.
+func runDiff(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("diff", "expected captured")
+	if err := parse(fs, args, 2, 2); err != nil {
+		return err
+	}
+
+	expected, err := frameArg(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	captured, err := frameArg(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	diffs := frames.DiffFrames(expected, captured)
+	for _, diff := range diffs {
+		fmt.Fprintln(stdout, diff)
+	}
+	if diffs != nil {
+		return fmt.Errorf("%d bytes differ", len(diffs))
+	}
+
+	fmt.Fprintln(stdout, "frames are equal")
+	return nil
+}
+
+// frameArg returns the frame given by arg: the contents of the file at path
+// arg or, if there is no such file, the bytes arg describes in hex.
+func frameArg(arg string) (frames.Frame, error) {
+	frame, err := os.ReadFile(arg)
+	if errors.Is(err, os.ErrNotExist) {
+		if frame, err = decodeHex(arg); err != nil {
+			return nil, fmt.Errorf("%q is neither a file nor hex", arg)
+		}
+	}
+
+	return frame, err
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestDiff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "captured")
+	if err := os.WriteFile(path, frames.Create([2]byte{'L', 'D'}, []byte("dundu")), 0o666); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	// the expected frame is LD5+dondu#q
+	if err := runDiff([]string{"4c44 052b 646f 6e64 7523 71", path}, nil, &out); err == nil {
+		t.Error("got no error for differing frames")
+	}
+	if got, want := out.String(), "data offset 1: 0x6f != 0x75\nchecksum offset 0: 0x71 != 0x6b\n"; got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+
+	out.Reset()
+	if err := runDiff([]string{"4c44052b646f6e64752371", "4c44052b646f6e64752371"}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "frames are equal\n"; got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+
+	if err := runDiff([]string{"4c44", filepath.Join(t.TempDir(), "missing")}, nil, &out); err == nil {
+		t.Error("got no error for a missing file")
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+// syncFrames is the number of copies of a valid frame following every input
+// fed to the library's decoder.
+const syncFrames = 3
+
+// runFuzz implements "frames fuzz", which feeds random and mutated bytes to the
+// library's decoder or, if a target is given, to a device.
+//
+// A panic of ParseFrame or Reader is reported as a crash, and frames found in
+// the wrong places as a desync, see fuzzDecoder. A device is sent a probe
+// frame after every input instead, and it must respond with any valid frame
+// before a timeout.
+func runFuzz(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("fuzz", "[target]")
+	count := fs.Int("n", 10000, "feed `count` inputs")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "seed of the inputs, printed to repeat a run")
+	probe := fs.String("probe", "PI", "`header` of the probe frames sent to a target")
+	timeout := fs.Duration("timeout", time.Second, "wait `duration` for a target's response to a probe")
+	if err := parse(fs, args, 0, 1); err != nil {
+		return err
+	}
+	if len(*probe) != 2 {
+		return fmt.Errorf("header %q is not 2 bytes long", *probe)
+	}
+
+	fmt.Fprintf(stdout, "seed %d\n", *seed)
+	r := rand.New(rand.NewSource(*seed))
+
+	if fs.NArg() == 0 {
+		failures := 0
+		for i := 0; i < *count; i++ {
+			input := fuzzInput(r)
+			if err := fuzzDecoder(input); err != nil {
+				fmt.Fprintf(stdout, "input %d: %v: %x\n", i, err, input)
+				failures++
+			}
+		}
+
+		fmt.Fprintf(stdout, "%d inputs, %d failures\n", *count, failures)
+		if failures > 0 {
+			return fmt.Errorf("%d failures", failures)
+		}
+		return nil
+	}
+
+	target, err := open(fs.Arg(0), os.O_RDWR)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	received := make(chan struct{}, 1)
+	go func() {
+		reader := frames.NewReader(target, frames.Codec{}, frames.Limits{})
+		for {
+			if _, err := reader.ReadFrame(); err != nil {
+				return
+			}
+			select {
+			case received <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	probeFrame := frames.Create([2]byte{(*probe)[0], (*probe)[1]}, nil)
+	for i := 0; i < *count; i++ {
+		input := fuzzInput(r)
+
+		// drop responses to previous inputs
+		select {
+		case <-received:
+		default:
+		}
+
+		if _, err := target.Write(append(input, probeFrame...)); err != nil {
+			return fmt.Errorf("input %d: %v: %x", i, err, input)
+		}
+
+		select {
+		case <-received:
+		case <-time.After(*timeout):
+			fmt.Fprintf(stdout, "input %d: no response: %x\n", i, input)
+			return fmt.Errorf("target stopped responding after %d inputs", i+1)
+		}
+	}
+
+	fmt.Fprintf(stdout, "%d inputs, no failures\n", *count)
+	return nil
+}
+
+// fuzzInput returns a random input: random bytes, valid frames and frames
+// mutated in various ways, concatenated.
+func fuzzInput(r *rand.Rand) []byte {
+	var input []byte
+	for parts := 1 + r.Intn(3); parts > 0; parts-- {
+		switch r.Intn(3) {
+		case 0:
+			garbage := make([]byte, r.Intn(64))
+			r.Read(garbage)
+			input = append(input, garbage...)
+		case 1:
+			input = append(input, randomFrame(r)...)
+		default:
+			input = append(input, mutate(r, randomFrame(r))...)
+		}
+	}
+
+	return input
+}
+
+// randomFrame returns a valid frame with a random header and data.
+func randomFrame(r *rand.Rand) frames.Frame {
+	data := make([]byte, r.Intn(32))
+	r.Read(data)
+
+	return frames.Create([2]byte{byte(r.Intn(256)), byte(r.Intn(256))}, data)
+}
+
+// mutate returns frame changed in one of the ways links corrupt frames, or
+// the ways a broken encoder would.
+func mutate(r *rand.Rand, frame frames.Frame) []byte {
+	b := []byte(frame)
+	switch r.Intn(6) {
+	case 0: // flipped bits
+		for flips := 1 + r.Intn(3); flips > 0; flips-- {
+			bit := r.Intn(8 * len(b))
+			b[bit/8] ^= 1 << (bit % 8)
+		}
+	case 1: // wrong length
+		b[2] = byte(r.Intn(256))
+	case 2: // truncated
+		b = b[:r.Intn(len(b))]
+	case 3: // inserted bytes
+		i := r.Intn(len(b) + 1)
+		inserted := make([]byte, 1+r.Intn(8))
+		r.Read(inserted)
+		b = append(b[:i:i], append(inserted, b[i:]...)...)
+	case 4: // repeated delimiters
+		i := r.Intn(len(b) + 1)
+		b = append(b[:i:i], append(bytes.Repeat([]byte("+#"), 1+r.Intn(4)), b[i:]...)...)
+	default: // a part of another frame
+		other := randomFrame(r)
+		b = append(b, other[r.Intn(len(other)):]...)
+	}
+
+	return b
+}
+
+// fuzzDecoder feeds input followed by copies of a valid frame to ParseFrame
+// and to a Reader. It returns an error describing a crash or a desync: the
+// Reader finding other frames than ParseFrame, or either of them missing any
+// of the copies. The copies are followed by enough zeros for every frame
+// started in input to be complete, and not checked if a valid frame crossing
+// the end of input is found, e.g. a truncated frame completed by them.
+func fuzzDecoder(input []byte) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("crash: %v", p)
+		}
+	}()
+
+	syncFrame := frames.Create([2]byte{'S', 'Y'}, []byte("sync"))
+	stream := append([]byte{}, input...)
+	for i := 0; i < syncFrames; i++ {
+		stream = append(stream, syncFrame...)
+	}
+	stream = append(stream, make([]byte, 262)...)
+
+	var parsed []frames.Frame
+	crossed := false
+	for pos := 0; pos < len(stream); {
+		frame, n, err := frames.ParseFrame(stream[pos:])
+		if errors.Is(err, frames.ErrIncomplete) {
+			break
+		}
+		if err != nil || !frames.Verify(frame) || n < len(frame) || pos+n > len(stream) {
+			return fmt.Errorf("desync: ParseFrame returned % x, %d, %v", []byte(frame), n, err)
+		}
+
+		pos += n
+		if pos-len(frame) < len(input) && pos > len(input) {
+			crossed = true
+		}
+		parsed = append(parsed, frame)
+	}
+
+	// the stream is read in small chunks, so that frames cross their ends
+	reader := frames.NewReader(&chunkReader{data: stream}, frames.Codec{}, frames.Limits{})
+	for i := 0; ; i++ {
+		frame, err := reader.ReadFrame()
+		if err == io.EOF {
+			if i < len(parsed) {
+				return fmt.Errorf("desync: Reader read %d frames, ParseFrame %d", i, len(parsed))
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("desync: %v", err)
+		}
+		if i >= len(parsed) || !bytes.Equal(frame, parsed[i]) {
+			return fmt.Errorf("desync: Reader read % x as frame %d, ParseFrame did not", []byte(frame), i)
+		}
+	}
+
+	if crossed {
+		return nil
+	}
+	if len(parsed) < syncFrames {
+		return fmt.Errorf("desync: %d of %d sync frames found", len(parsed), syncFrames)
+	}
+	for _, frame := range parsed[len(parsed)-syncFrames:] {
+		if !bytes.Equal(frame, syncFrame) {
+			return fmt.Errorf("desync: found % x instead of a sync frame", []byte(frame))
+		}
+	}
+
+	return nil
+}
+
+// chunkReader reads data in chunks of 1 to 16 bytes.
+type chunkReader struct {
+	data []byte
+	n    int // length of the next chunk minus 1
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+
+	n := 1 + r.n%16
+	if n > len(r.data) {
+		n = len(r.data)
+	}
+	n = copy(p, r.data[:n])
+	r.data = r.data[n:]
+	r.n += 7
+
+	return n, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestFuzz(t *testing.T) {
+	var out strings.Builder
+	if err := runFuzz([]string{"-n", "1000", "-seed", "1"}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "seed 1\n1000 inputs, 0 failures\n"; got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+}
+
+func TestFuzzDecoder(t *testing.T) {
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("dondu"))
+	for _, input := range [][]byte{
+		nil,
+		frame,
+		[]byte("garbage"),
+		frame[:len(frame)-1],
+		{'L', 'D', 0xff, '+'},
+	} {
+		if err := fuzzDecoder(input); err != nil {
+			t.Errorf("input % x: %v", input, err)
+		}
+	}
+}
+
+func TestFuzzInput(t *testing.T) {
+	a, b := rand.New(rand.NewSource(1)), rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		if x, y := fuzzInput(a), fuzzInput(b); !bytes.Equal(x, y) {
+			t.Fatalf("input %d: got % x and % x from the same seed", i, x, y)
+		}
+	}
+}
+
+func TestChunkReader(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10)
+	got, err := io.ReadAll(&chunkReader{data: data})
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("got %q, %v, want %q, nil", got, err, data)
+	}
+}
+
+func TestFuzzTarget(t *testing.T) {
+	// the peer responds to probes until it receives a frame with header "XX"
+	target := listenRaw(t, func(conn net.Conn) {
+		reader := frames.NewReader(conn, frames.Codec{}, frames.Limits{})
+		for {
+			frame, err := reader.ReadFrame()
+			if err != nil || bytes.Equal(frame.Header(), []byte("XX")) {
+				return
+			}
+			if bytes.Equal(frame.Header(), []byte("PI")) {
+				conn.Write(frames.Create([2]byte{'P', 'O'}, nil))
+			}
+		}
+	})
+
+	var out strings.Builder
+	if err := runFuzz([]string{"-n", "100", "-seed", "1", target}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out.String(), "100 inputs, no failures\n") {
+		t.Errorf("got output %q", out.String())
+	}
+
+	out.Reset()
+	err := runFuzz([]string{"-n", "100", "-seed", "1", "-probe", "XX", "-timeout", "50ms", target}, nil, &out)
+	if err == nil || !strings.Contains(out.String(), "input 0: no response") {
+		t.Errorf("got output %q, error %v, want no response to input 0", out.String(), err)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+// generateConfig configures the traffic produced by "frames generate".
+type generateConfig struct {
+	headers  [][2]byte
+	min, max int     // data length range
+	count    int     // number of frames, 0 means unlimited
+	rate     float64 // frames per second, 0 means unlimited
+	corrupt  float64 // percentage of frames with a flipped bit
+	seed     int64
+}
+
+// runGenerate implements "frames generate", which writes synthetic frames to a
+// target, e.g. to load-test a receiver. Frames can be corrupted on purpose, and
+// delayed and reordered with a DelayWriter or passed through a simulated
+// channel created by NewNoisyChannel on their way.
+func runGenerate(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("generate", "target")
+	headers := fs.String("headers", "LD", "comma-separated `headers` of frames, used in turn")
+	minLength := fs.Int("min", 0, "minimum data `length`")
+	maxLength := fs.Int("max", 16, "maximum data `length`")
+	count := fs.Int("n", 0, "write `count` frames, 0 means until interrupted")
+	rate := fs.Float64("rate", 0, "write `frames` per second, 0 means as fast as possible")
+	corrupt := fs.Float64("corrupt", 0, "flip a random bit in this `percentage` of frames")
+	seed := fs.Int64("seed", 1, "seed of the generated data and errors")
+	delay := fs.Duration("delay", 0, "delay frames by `duration`")
+	jitter := fs.Duration("jitter", 0, "delay frames additionally by up to `duration`, drawn uniformly")
+	reorder := fs.Int("reorder", 0, "let frames overtake up to `count` preceding ones when delayed")
+	ber := fs.Float64("ber", 0, "flip every written bit with this `probability`")
+	bandwidth := fs.Int("bandwidth", 0, "limit throughput to `bytes` per second, 0 means unlimited")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	config := generateConfig{min: *minLength, max: *maxLength, count: *count, rate: *rate, corrupt: *corrupt, seed: *seed}
+	for _, header := range strings.Split(*headers, ",") {
+		if len(header) != 2 {
+			return fmt.Errorf("header %q is not 2 bytes long", header)
+		}
+		config.headers = append(config.headers, [2]byte{header[0], header[1]})
+	}
+	if config.min < 0 || config.max > 255 || config.min > config.max {
+		return fmt.Errorf("invalid data length range %d to %d", config.min, config.max)
+	}
+	if *delay < 0 || *jitter < 0 || *reorder < 0 {
+		return fmt.Errorf("negative delay %v, jitter %v or reorder %d", *delay, *jitter, *reorder)
+	}
+
+	target, err := open(fs.Arg(0), os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	var w io.Writer = target
+	var noisy io.Closer
+	copied := make(chan error, 1)
+	if *ber > 0 || *bandwidth > 0 {
+		a, b := frames.NewNoisyChannel(frames.NoisyChannelConfig{BitErrorRate: *ber, Bandwidth: *bandwidth, Seed: *seed})
+		go func() {
+			_, err := io.Copy(target, b)
+			copied <- err
+		}()
+		w, noisy = a, a
+	}
+
+	var delayed *frames.DelayWriter
+	if *delay > 0 || *jitter > 0 {
+		delayed, err = frames.NewDelayWriter(w, frames.DelayConfig{
+			Distribution: frames.DelayUniform,
+			Delay:        *delay,
+			Jitter:       *jitter,
+			MaxReorder:   *reorder,
+			Seed:         *seed,
+		})
+		if err != nil {
+			return err
+		}
+		w = delayed
+	}
+
+	n, err := generate(w, config)
+	if delayed != nil {
+		if closeErr := delayed.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if noisy != nil {
+		// the copy ends once the written bytes are delivered
+		noisy.Close()
+		if copyErr := <-copied; err == nil {
+			err = copyErr
+		}
+	}
+
+	fmt.Fprintf(stdout, "%d frames written\n", n)
+	return err
+}
+
+// generate writes frames described by config to w, every frame in a single
+// call to Write. It returns the number of written frames.
+func generate(w io.Writer, config generateConfig) (n int, err error) {
+	r := rand.New(rand.NewSource(config.seed))
+
+	var tick <-chan time.Time
+	if config.rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / config.rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	data := make([]byte, config.max)
+	for ; config.count == 0 || n < config.count; n++ {
+		if tick != nil {
+			<-tick
+		}
+
+		data := data[:config.min+r.Intn(config.max-config.min+1)]
+		r.Read(data)
+		frame := frames.Create(config.headers[n%len(config.headers)], data)
+		if r.Float64()*100 < config.corrupt {
+			bit := r.Intn(8 * len(frame))
+			frame[bit/8] ^= 1 << (bit % 8)
+		}
+
+		if _, err := w.Write(frame); err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+// readFrames returns valid frames found in the file at path.
+func readFrames(t *testing.T, path string) []frames.Frame {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var read []frames.Frame
+	reader := frames.NewReader(f, frames.Codec{}, frames.Limits{})
+	for {
+		frame, err := reader.ReadFrame()
+		if err == io.EOF {
+			return read
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		read = append(read, frame)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		args  []string
+		valid int
+	}{
+		{[]string{"-n", "20", "-headers", "LD,MT", "-min", "2", "-max", "8"}, 20},
+		{[]string{"-n", "20", "-headers", "LD,MT", "-corrupt", "100"}, 0},
+		{[]string{"-n", "20", "-headers", "LD,MT", "-rate", "1000", "-delay", "1ms", "-jitter", "1ms", "-bandwidth", "100000"}, 20},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "traffic")
+
+		var out strings.Builder
+		if err := runGenerate(append(tt.args, path), nil, &out); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := out.String(), "20 frames written\n"; got != want {
+			t.Errorf("%q: got output %q, want %q", tt.args, got, want)
+		}
+
+		read := readFrames(t, path)
+		if len(read) != tt.valid {
+			t.Errorf("%q: got %d valid frames, want %d", tt.args, len(read), tt.valid)
+		}
+		for i, frame := range read {
+			if want := [][]byte{[]byte("LD"), []byte("MT")}[i%2]; !bytes.Equal(frame.Header(), want) {
+				t.Errorf("%q: frame %d: got header %q, want %q", tt.args, i, frame.Header(), want)
+			}
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic")
+	for _, args := range [][]string{
+		{"-headers", "LDX", path},
+		{"-min", "10", "-max", "5", path},
+		{"-max", "256", path},
+		{"-jitter", "-1s", path},
+		{"-delay", "-1ms", path},
+		{"-delay", "1ms", "-reorder", "-1", path},
+	} {
+		if err := runGenerate(args, nil, io.Discard); err == nil {
+			t.Errorf("%q: got no error", args)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/knei-knurow/frames"
+)
+
+// inspectHelp lists the commands of "frames inspect".
+const inspectHelp = `commands:
+	list          list the found frames
+	dump          show a hex dump of the input, with the found frames marked
+	next, n       show the next frame; an empty line does the same
+	prev, p       show the previous frame
+	frame, f I    show frame I
+	checksum, c   recalculate the checksum of the shown frame
+	diff, d I J   show bytes differing between frames I and J
+	help, h       show this help
+	quit, q       quit
+`
+
+// runInspect implements "frames inspect", which lets the user step through
+// frames found in bytes captured from a link and examine them. The bytes are
+// read from a file, as the commands are read from the standard input.
+func runInspect(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("inspect", "file")
+	isHex := fs.Bool("hex", false, "the file holds bytes in hex; whitespace is ignored")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	buf, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *isHex {
+		if buf, err = decodeHex(string(buf)); err != nil {
+			return err
+		}
+	}
+
+	return newInspector(buf, stdout).run(stdin)
+}
+
+// span is a frame found in the inspected bytes.
+type span struct {
+	offset int
+	frame  frames.Frame
+}
+
+// inspector is the state of an inspect session.
+type inspector struct {
+	buf     []byte
+	spans   []span
+	current int // index of the shown frame, -1 before the first one
+	w       io.Writer
+}
+
+func newInspector(buf []byte, w io.Writer) *inspector {
+	return &inspector{buf: buf, spans: findSpans(buf), current: -1, w: w}
+}
+
+// run executes commands read from r until it ends or the quit command.
+func (in *inspector) run(r io.Reader) error {
+	fmt.Fprintf(in.w, "%d bytes, %d frames found, type help for commands\n", len(in.buf), len(in.spans))
+
+	scanner := bufio.NewScanner(r)
+	for {
+		fmt.Fprint(in.w, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(in.w)
+			return scanner.Err()
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			fields = []string{"next"}
+		}
+		if fields[0] == "quit" || fields[0] == "q" {
+			return nil
+		}
+
+		if err := in.execute(fields[0], fields[1:]); err != nil {
+			fmt.Fprintln(in.w, err)
+		}
+	}
+}
+
+// execute executes a single command other than quit.
+func (in *inspector) execute(command string, args []string) error {
+	switch command {
+	case "list":
+		list := make([]frames.Frame, len(in.spans))
+		for i, s := range in.spans {
+			list[i] = s.frame
+		}
+		return frames.PrintTable(in.w, list)
+	case "dump":
+		_, err := fmt.Fprint(in.w, frames.DescribeBytes(in.buf))
+		return err
+	case "next", "n":
+		return in.show(in.current + 1)
+	case "prev", "p":
+		return in.show(in.current - 1)
+	case "frame", "f":
+		indices, err := in.indices(args, 1)
+		if err != nil {
+			return err
+		}
+		return in.show(indices[0])
+	case "checksum", "c":
+		if in.current < 0 {
+			return errors.New("no frame shown")
+		}
+		frame := in.spans[in.current].frame
+		stored, calculated := frame.Checksum(), frames.CalculateChecksum(frame)
+		if stored == calculated {
+			fmt.Fprintf(in.w, "checksum %#02x is correct\n", stored)
+		} else {
+			fmt.Fprintf(in.w, "checksum %#02x is wrong, it should be %#02x\n", stored, calculated)
+		}
+		return nil
+	case "diff", "d":
+		indices, err := in.indices(args, 2)
+		if err != nil {
+			return err
+		}
+		diffs := frames.DiffFrames(in.spans[indices[0]].frame, in.spans[indices[1]].frame)
+		if diffs == nil {
+			fmt.Fprintln(in.w, "frames are equal")
+		}
+		for _, diff := range diffs {
+			fmt.Fprintln(in.w, diff)
+		}
+		return nil
+	case "help", "h":
+		_, err := fmt.Fprint(in.w, inspectHelp)
+		return err
+	default:
+		return fmt.Errorf("unknown command %q, type help for commands", command)
+	}
+}
+
+// indices parses n indices of found frames from args.
+func (in *inspector) indices(args []string, n int) ([]int, error) {
+	if len(args) != n {
+		return nil, fmt.Errorf("want %d frame indices", n)
+	}
+
+	indices := make([]int, n)
+	for i, arg := range args {
+		index, err := strconv.Atoi(arg)
+		if err != nil || index < 0 || index >= len(in.spans) {
+			return nil, fmt.Errorf("no frame %s", arg)
+		}
+		indices[i] = index
+	}
+
+	return indices, nil
+}
+
+// show makes the frame with index i the current one and shows its parts.
+func (in *inspector) show(i int) error {
+	if i < 0 || i >= len(in.spans) {
+		return fmt.Errorf("no frame %d", i)
+	}
+	in.current = i
+
+	s := in.spans[i]
+	var verifyErr *frames.VerifyError
+	if !errors.As(frames.Validate(s.frame), &verifyErr) {
+		fmt.Fprintf(in.w, "frame %d of %d at offset %#x, %d bytes, valid\n", i, len(in.spans), s.offset, len(s.frame))
+	} else {
+		got := "end of frame"
+		if verifyErr.Got >= 0 {
+			got = fmt.Sprintf("%#02x", verifyErr.Got)
+		}
+		fmt.Fprintf(in.w, "frame %d of %d at offset %#x, %d bytes, invalid\n", i, len(in.spans), s.offset, len(s.frame))
+		fmt.Fprintf(in.w, "error     offset %d: want %s, got %s\n", verifyErr.Offset, verifyErr.Want, got)
+	}
+	fmt.Fprintf(in.w, "header    % x  %q\n", s.frame.Header(), s.frame.Header())
+	fmt.Fprintf(in.w, "length    %d\n", s.frame.LenData())
+	fmt.Fprintf(in.w, "data      % x  %q\n", s.frame.Data(), s.frame.Data())
+	_, err := fmt.Fprintf(in.w, "checksum  %#02x\n", s.frame.Checksum())
+	return err
+}
+
+// findSpans returns valid frames found in buf as ParseFrame finds them and,
+// between them, candidates with invalid checksums: byte sequences with '+' and
+// '#' where their length bytes place them. Candidates do not overlap.
+func findSpans(buf []byte) (spans []span) {
+	for pos := 0; pos < len(buf); {
+		frame, n, err := frames.ParseFrame(buf[pos:])
+		end := pos + n - len(frame)
+		if err != nil {
+			end = len(buf)
+		}
+
+		for i := pos; i < end; {
+			if candidate := candidateAt(buf[i:end]); candidate != nil {
+				spans = append(spans, span{offset: i, frame: candidate})
+				i += len(candidate)
+				continue
+			}
+			i++
+		}
+
+		if err != nil {
+			return spans
+		}
+		spans = append(spans, span{offset: end, frame: frame})
+		pos += n
+	}
+
+	return spans
+}
+
+// emptyFrameLen is the length of the shortest frame, which has no data.
+var emptyFrameLen = len(frames.Create([2]byte{}, nil))
+
+// candidateAt returns the frame buf starts with, regardless of its checksum,
+// or nil.
+func candidateAt(buf []byte) frames.Frame {
+	if len(buf) < emptyFrameLen || buf[3] != '+' {
+		return nil
+	}
+
+	end := emptyFrameLen + int(buf[2])
+	if end > len(buf) || buf[end-2] != '#' {
+		return nil
+	}
+
+	return frames.Frame(append([]byte{}, buf[:end]...))
+}
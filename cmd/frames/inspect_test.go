@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestFindSpans(t *testing.T) {
+	valid := frames.Create([2]byte{'L', 'D'}, []byte("dondu"))
+	invalid := frames.Create([2]byte{'L', 'D'}, []byte("A"))
+	invalid[len(invalid)-1] ^= 0xff
+	last := frames.Create([2]byte{'M', 'T'}, nil)
+
+	buf := bytes.Join([][]byte{[]byte("xx"), valid, invalid, []byte("+#"), last, []byte("LD\x09+")}, nil)
+	want := []span{{2, valid}, {13, invalid}, {22, last}}
+
+	spans := findSpans(buf)
+	if len(spans) != len(want) {
+		t.Fatalf("got %d spans, want %d", len(spans), len(want))
+	}
+	for i, s := range spans {
+		if s.offset != want[i].offset || !bytes.Equal(s.frame, want[i].frame) {
+			t.Errorf("span %d: got % x at %d, want % x at %d", i, []byte(s.frame), s.offset, []byte(want[i].frame), want[i].offset)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.hex")
+	if err := os.WriteFile(path, []byte("78 78 4c 44 05 2b 64 6f 6e 64 75 23 71\n4c 44 01 2b 41 23 00 4d 54 00 2b 23 11\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	commands := "c\nlist\n\n\nchecksum\nd 0 2\nd 0 0\nf 2\nn\nfoo\nq\nhelp\n"
+	if err := runInspect([]string{"-hex", path}, strings.NewReader(commands), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"26 bytes, 3 frames found",
+		"> no frame shown\n",
+		"1      LD      1       41          A      00        false\n",
+		"frame 0 of 3 at offset 0x2, 11 bytes, valid\nheader    4c 44  \"LD\"\nlength    5\ndata      64 6f 6e 64 75  \"dondu\"\nchecksum  0x71\n",
+		"frame 1 of 3 at offset 0xd, 7 bytes, invalid\nerror     offset 6: want checksum 0x40, got 0x00\nheader",
+		"> checksum 0x00 is wrong, it should be 0x40\n",
+		"> header offset 0: 0x4c != 0x4d\n",
+		"> frames are equal\n",
+		"> frame 2 of 3 at offset 0x14",
+		"> no frame 3\n",
+		`> unknown command "foo"`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("got output %q, want it to include %q", out.String(), want)
+		}
+	}
+	if strings.Contains(out.String(), "commands:") {
+		t.Errorf("got output %q, want it to end at quit", out.String())
+	}
+}
@@ -0,0 +1,149 @@
+// Frames is a tool for debugging links carrying frames and working with their
+// captures.
+//
+// Usage:
+//
+//	frames <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	stats     show live statistics of a link
+//	generate  write synthetic frames to a target
+//	fuzz      feed random and mutated bytes to the decoder or a target
+//	convert   convert captures between formats
+//	inspect   step through frames found in captured bytes
+//	diff      compare an expected frame with a captured one
+//
+// Run "frames <command> -h" for the flags of a command.
+//
+// Commands attaching to a link take a target: "tcp:host:port" connects to a
+// TCP endpoint, anything else is the path of a file or a device, e.g. a serial
+// port, which must be configured beforehand, e.g. with stty.
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/knei-knurow/frames"
+)
+
+// errUsage is returned by commands called with invalid flags or arguments,
+// after their usage has been printed.
+var errUsage = errors.New("invalid usage")
+
+// command is a subcommand of frames.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdin io.Reader, stdout io.Writer) error
+}
+
+var commands = []command{
+	{"stats", "show live statistics of a link", runStats},
+	{"generate", "write synthetic frames to a target", runGenerate},
+	{"fuzz", "feed random and mutated bytes to the decoder or a target", runFuzz},
+	{"convert", "convert captures between formats", runConvert},
+	{"inspect", "step through frames found in captured bytes", runInspect},
+	{"diff", "compare an expected frame with a captured one", runDiff},
+}
+
+// capabilities are advertised by commands performing the handshake. The
+// maximum frame size is that of the longest frame with a 16-bit checksum.
+var capabilities = frames.Capabilities{
+	Version:      1,
+	MaxFrameSize: 262,
+	Checksums:    []frames.ChecksumAlgorithm{frames.ChecksumXOR, frames.ChecksumXMODEM},
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("frames: ")
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+
+		err := cmd.run(os.Args[2:], os.Stdin, os.Stdout)
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		if err != nil {
+			log.Fatalf("%s: %v", cmd.name, err)
+		}
+		return
+	}
+
+	log.Printf("unknown command %q", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: frames <command> [flags] [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "The commands are:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-9s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+// newFlagSet returns a flag set of the command name, whose usage message shows
+// the arguments described by args.
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: frames %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parse parses args with fs and checks that between min and max arguments
+// remain. It returns errUsage if they do not.
+func parse(fs *flag.FlagSet, args []string, min, max int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() < min || fs.NArg() > max {
+		fs.Usage()
+		return errUsage
+	}
+
+	return nil
+}
+
+// open opens target for reading and writing. Flag is passed to os.OpenFile
+// when target is a path.
+func open(target string, flag int) (io.ReadWriteCloser, error) {
+	if address := strings.TrimPrefix(target, "tcp:"); address != target {
+		return net.Dial("tcp", address)
+	}
+
+	return os.OpenFile(target, flag, 0o666)
+}
+
+// decodeHex decodes bytes written in hex, ignoring whitespace.
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		err  error
+	}{
+		{[]string{"a"}, nil},
+		{[]string{"-v", "a", "b"}, nil},
+		{[]string{}, errUsage},
+		{[]string{"a", "b", "c"}, errUsage},
+		{[]string{"-unknown", "a"}, errUsage},
+	} {
+		fs := newFlagSet("test", "arg...")
+		fs.SetOutput(io.Discard)
+		fs.Bool("v", false, "")
+		if err := parse(fs, tt.args, 1, 2); !errors.Is(err, tt.err) {
+			t.Errorf("%q: got error %v, want %v", tt.args, err, tt.err)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if _, err := open("tcp:127.0.0.1:0", 0); err == nil {
+		t.Error("got no error dialing port 0")
+	}
+
+	rw, err := open(filepath.Join(t.TempDir(), "missing"), 0)
+	if err == nil {
+		rw.Close()
+		t.Error("got no error opening a missing file")
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+// runStats implements "frames stats", which attaches to a link with a Session
+// and shows how many frames and bytes of every header are received per second,
+// together with the session's counters and link quality. The view is redrawn
+// every interval.
+func runStats(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("stats", "target")
+	interval := fs.Duration("interval", time.Second, "redraw the view every `duration`")
+	count := fs.Int("n", 0, "exit after `count` redraws, 0 means never")
+	clearScreen := fs.Bool("clear", true, "clear the terminal before every redraw")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	target := fs.Arg(0)
+	session, err := frames.NewSession(frames.SessionConfig{
+		Dial: func() (io.ReadWriteCloser, error) {
+			return open(target, os.O_RDWR)
+		},
+		Capabilities: capabilities,
+		Source:       target,
+	})
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	view := newStatsView()
+	go func() {
+		for {
+			frame, err := session.ReadFrame()
+			if err != nil {
+				return
+			}
+			view.observe(session.Negotiation().Codec, frame)
+		}
+	}()
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for i := 0; *count == 0 || i < *count; i++ {
+		<-ticker.C
+
+		// the link quality is averaged since the previous snapshot
+		quality := session.LinkQuality()
+		stats := session.SnapshotAndReset()
+		if *clearScreen {
+			fmt.Fprint(stdout, "\x1b[H\x1b[2J")
+		}
+		if err := view.render(stdout, stats, quality, *interval); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// headerCounts counts frames with a single header.
+type headerCounts struct {
+	frames    int
+	dataBytes int
+}
+
+// statsView counts received frames by their headers between redraws.
+type statsView struct {
+	mu      sync.Mutex
+	headers map[string]*headerCounts
+}
+
+func newStatsView() *statsView {
+	return &statsView{headers: make(map[string]*headerCounts)}
+}
+
+// observe counts frame, encoded with codec.
+func (v *statsView) observe(codec frames.Codec, frame frames.Frame) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	header := string(codec.Header(frame))
+	counts := v.headers[header]
+	if counts == nil {
+		counts = &headerCounts{}
+		v.headers[header] = counts
+	}
+	counts.frames++
+	counts.dataBytes += len(codec.Data(frame))
+}
+
+// render writes the view to w and resets the counts of headers. Stats are the
+// session's counters since the previous redraw, interval ago.
+func (v *statsView) render(w io.Writer, stats frames.Stats, quality frames.LinkQuality, interval time.Duration) error {
+	v.mu.Lock()
+	headers := v.headers
+	v.headers = make(map[string]*headerCounts)
+	v.mu.Unlock()
+
+	seconds := interval.Seconds()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "uptime %v, %d reconnects, %d frames corrected\n",
+		quality.Uptime.Round(time.Second), stats.Reconnects, stats.CorrectedFrames)
+	fmt.Fprintf(tw, "%.1f frames/s, %.0f B/s received, %.0f B/s of data, error ratio %.2f%%, bit error rate %.2g\n",
+		quality.FramesPerSecond, float64(stats.BytesRead+stats.SkippedBytes)/seconds, quality.Goodput,
+		100*quality.ErrorRatio, stats.BitErrorRate)
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "HEADER\tFRAMES/S\tDATA B/S")
+
+	names := make([]string, 0, len(headers))
+	for header := range headers {
+		names = append(names, header)
+	}
+	sort.Strings(names)
+
+	for _, header := range names {
+		counts := headers[header]
+		fmt.Fprintf(tw, "%q\t%.1f\t%.0f\n", header, float64(counts.frames)/seconds, float64(counts.dataBytes)/seconds)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+// listen starts a TCP peer which performs the handshake on every accepted
+// connection and passes it to serve. It returns the target to connect to.
+func listen(t *testing.T, serve func(conn net.Conn)) string {
+	return listenRaw(t, func(conn net.Conn) {
+		if _, err := frames.Handshake(conn, capabilities); err != nil {
+			return
+		}
+		serve(conn)
+	})
+}
+
+// listenRaw works like listen, but without the handshake.
+func listenRaw(t *testing.T, serve func(conn net.Conn)) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn)
+			}()
+		}
+	}()
+
+	return "tcp:" + ln.Addr().String()
+}
+
+func TestStats(t *testing.T) {
+	target := listen(t, func(conn net.Conn) {
+		for {
+			for _, frame := range []frames.Frame{
+				frames.Codec{ChecksumAlgorithm: frames.ChecksumXMODEM}.Create([2]byte{'L', 'D'}, []byte("dondu")),
+				frames.Codec{ChecksumAlgorithm: frames.ChecksumXMODEM}.Create([2]byte{'M', 'T'}, []byte{0x01}),
+			} {
+				if _, err := conn.Write(frame); err != nil {
+					return
+				}
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	var out strings.Builder
+	if err := runStats([]string{"-interval", "50ms", "-n", "2", "-clear=false", target}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"HEADER", `"LD"`, `"MT"`, "frames/s"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("got output %q, want it to include %q", out.String(), want)
+		}
+	}
+}
+
+func TestStatsView(t *testing.T) {
+	view := newStatsView()
+	for i := 0; i < 4; i++ {
+		view.observe(frames.Codec{}, frames.Create([2]byte{'L', 'D'}, []byte("abcd")))
+	}
+	view.observe(frames.Codec{}, frames.Create([2]byte{'M', 'T'}, nil))
+
+	var out strings.Builder
+	stats := frames.Stats{BytesRead: 50, SkippedBytes: 50}
+	quality := frames.LinkQuality{FramesPerSecond: 2.5, Goodput: 8, ErrorRatio: 0.5}
+	if err := view.render(&out, stats, quality, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"2.5 frames/s, 50 B/s received, 8 B/s of data, error ratio 50.00%", `"LD"    2.0       8`, `"MT"    0.5       0`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("got output %q, want it to include %q", out.String(), want)
+		}
+	}
+
+	// counts are reset after rendering
+	out.Reset()
+	view.render(&out, stats, quality, time.Second)
+	if strings.Contains(out.String(), `"LD"`) {
+		t.Errorf("got output %q, want no headers", out.String())
+	}
+}
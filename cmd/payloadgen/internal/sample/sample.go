@@ -0,0 +1,28 @@
+// Package sample declares payloads used to test the code generated by
+// payloadgen.
+package sample
+
+//go:generate go run github.com/knei-knurow/frames/cmd/payloadgen
+
+// Measurement is a single LIDAR measurement.
+//
+//frames:payload LD
+type Measurement struct {
+	Angle    uint16
+	Distance uint32
+	Quality  uint8
+	Valid    bool
+}
+
+// Status is a status report of a device.
+//
+//frames:payload ST
+type Status struct {
+	Temperature float32
+	Voltage     float64
+	Offset      int16
+	Errors      int64
+	Serial      [4]byte
+	Cached      int `frames:"-"`
+	Message     []byte
+}
@@ -0,0 +1,88 @@
+// Code generated by payloadgen. DO NOT EDIT.
+
+package sample
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/knei-knurow/frames"
+)
+
+// Header returns the header of frames carrying Measurement.
+func (p *Measurement) Header() [2]byte {
+	return [2]byte{'L', 'D'}
+}
+
+// AppendTo appends p, encoded as frame data, to b.
+func (p *Measurement) AppendTo(b []byte) []byte {
+	b = append(b, byte(p.Angle>>8), byte(p.Angle))
+	b = append(b, byte(p.Distance>>24), byte(p.Distance>>16), byte(p.Distance>>8), byte(p.Distance))
+	b = append(b, byte(p.Quality))
+	if p.Valid {
+		b = append(b, 1)
+	} else {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// ParseFrom decodes p from frame data. It returns frames.ErrPayloadLength if
+// data has a wrong length.
+func (p *Measurement) ParseFrom(data []byte) error {
+	if len(data) != 8 {
+		return frames.ErrPayloadLength
+	}
+
+	p.Angle = binary.BigEndian.Uint16(data[0:])
+	p.Distance = binary.BigEndian.Uint32(data[2:])
+	p.Quality = data[6]
+	p.Valid = data[7] != 0
+
+	return nil
+}
+
+// Header returns the header of frames carrying Status.
+func (p *Status) Header() [2]byte {
+	return [2]byte{'S', 'T'}
+}
+
+// AppendTo appends p, encoded as frame data, to b.
+func (p *Status) AppendTo(b []byte) []byte {
+	{
+		v := math.Float32bits(p.Temperature)
+		b = append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+	{
+		v := math.Float64bits(p.Voltage)
+		b = append(b, byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+	{
+		v := uint16(p.Offset)
+		b = append(b, byte(v>>8), byte(v))
+	}
+	{
+		v := uint64(p.Errors)
+		b = append(b, byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+	b = append(b, p.Serial[:]...)
+	b = append(b, p.Message...)
+	return b
+}
+
+// ParseFrom decodes p from frame data. It returns frames.ErrPayloadLength if
+// data has a wrong length.
+func (p *Status) ParseFrom(data []byte) error {
+	if len(data) < 26 {
+		return frames.ErrPayloadLength
+	}
+
+	p.Temperature = math.Float32frombits(binary.BigEndian.Uint32(data[0:]))
+	p.Voltage = math.Float64frombits(binary.BigEndian.Uint64(data[4:]))
+	p.Offset = int16(binary.BigEndian.Uint16(data[12:]))
+	p.Errors = int64(binary.BigEndian.Uint64(data[14:]))
+	copy(p.Serial[:], data[22:26])
+	p.Message = data[26:]
+
+	return nil
+}
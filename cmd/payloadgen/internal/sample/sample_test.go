@@ -0,0 +1,76 @@
+package sample_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+	"github.com/knei-knurow/frames/cmd/payloadgen/internal/sample"
+)
+
+func TestMeasurement(t *testing.T) {
+	want := sample.Measurement{Angle: 359, Distance: 0x01020304, Quality: 200, Valid: true}
+
+	data := want.AppendTo(nil)
+	if wantData := []byte{0x01, 0x67, 0x01, 0x02, 0x03, 0x04, 200, 1}; !bytes.Equal(data, wantData) {
+		t.Errorf("got data % x, want % x", data, wantData)
+	}
+
+	var got sample.Measurement
+	if err := got.ParseFrom(data); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if err := got.ParseFrom(data[1:]); err != frames.ErrPayloadLength {
+		t.Errorf("got error %v, want %v", err, frames.ErrPayloadLength)
+	}
+
+	if header := got.Header(); header != [2]byte{'L', 'D'} {
+		t.Errorf("got header %q, want %q", header, "LD")
+	}
+}
+
+func TestStatus(t *testing.T) {
+	want := sample.Status{
+		Temperature: -12.5,
+		Voltage:     3.3,
+		Offset:      -2,
+		Errors:      -1 << 40,
+		Serial:      [4]byte{'S', 'N', '0', '1'},
+		Cached:      42,
+		Message:     []byte("ok"),
+	}
+
+	data := want.AppendTo(nil)
+	if len(data) != 28 {
+		t.Fatalf("got %d bytes of data, want 28", len(data))
+	}
+
+	var got sample.Status
+	if err := got.ParseFrom(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Temperature != want.Temperature || got.Voltage != want.Voltage || got.Offset != want.Offset ||
+		got.Errors != want.Errors || got.Serial != want.Serial || !bytes.Equal(got.Message, want.Message) || got.Cached != 0 {
+		t.Errorf("got %+v, want %+v without Cached", got, want)
+	}
+}
+
+func TestAllocs(t *testing.T) {
+	m := sample.Measurement{Angle: 1, Distance: 2}
+	buf := make([]byte, 0, 16)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		data := m.AppendTo(buf[:0])
+		if err := m.ParseFrom(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+}
@@ -0,0 +1,383 @@
+// Payloadgen generates code encoding Go structs as frame data and decoding
+// them from it, without reflection and without allocations.
+//
+// It is meant to be run by go generate. Add a comment like
+//
+//	//go:generate go run github.com/knei-knurow/frames/cmd/payloadgen
+//
+// to a file declaring structs annotated with a line in their doc comments
+// naming the header of frames carrying them:
+//
+//	// Measurement is a single LIDAR measurement.
+//	//
+//	//frames:payload LD
+//	type Measurement struct {
+//		Angle    uint16
+//		Distance uint16
+//	}
+//
+// For every annotated struct, payloadgen generates Header, AppendTo and
+// ParseFrom methods on a pointer to the struct, which thus implements
+// frames.Payload and can be used with frames.TypedFrame. Fields are encoded in order,
+// in big-endian byte order. Supported field types are bool, byte, uint8 to
+// uint64, int8 to int64, float32, float64, byte arrays and, as the last field
+// only, a byte slice taking the rest of the data. ParseFrom makes the slice
+// refer to the decoded data instead of copying it. Fields tagged with
+// `frames:"-"` are skipped.
+//
+// The code is written to a file named after the input file, with the suffix
+// _payload.go. If the input file has no annotated structs, nothing is written.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("payloadgen: ")
+
+	file := flag.String("file", os.Getenv("GOFILE"), "Go `file` declaring the payload structs")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("no input file, use -file or run with go generate")
+	}
+
+	src, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	code, err := generate(*file, src)
+	if err == errNoPayloads {
+		log.Printf("%s: %v, nothing written", *file, err)
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	output := strings.TrimSuffix(*file, ".go") + "_payload.go"
+	if err := os.WriteFile(output, code, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// payloadAnnotation starts the doc comment line marking a payload struct.
+const payloadAnnotation = "//frames:payload "
+
+// errNoPayloads is returned by generate when the file has no payload structs,
+// as the generated file would not compile.
+var errNoPayloads = errors.New("no annotated payload structs")
+
+// payload describes an annotated struct.
+type payload struct {
+	name   string
+	header string
+	fields []field
+}
+
+// field describes a single encoded field of a payload.
+type field struct {
+	name string
+	kind string // type name, "array" or "slice"
+	size int    // encoded size in bytes, 0 for a slice
+}
+
+// generate returns the code for the payload structs declared in src.
+func generate(filename string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var payloads []payload
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			spec := spec.(*ast.TypeSpec)
+
+			doc := spec.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			header, ok := annotation(doc)
+			if !ok {
+				continue
+			}
+
+			p, err := parsePayload(spec, header)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", fset.Position(spec.Pos()), err)
+			}
+			payloads = append(payloads, p)
+		}
+	}
+
+	if len(payloads) == 0 {
+		return nil, errNoPayloads
+	}
+
+	var buf bytes.Buffer
+	writeFile(&buf, file.Name.Name, payloads)
+
+	return format.Source(buf.Bytes())
+}
+
+// annotation returns the header named in the payload annotation in doc.
+func annotation(doc *ast.CommentGroup) (header string, ok bool) {
+	if doc == nil {
+		return "", false
+	}
+
+	for _, comment := range doc.List {
+		if strings.HasPrefix(comment.Text, payloadAnnotation) {
+			return strings.TrimSpace(strings.TrimPrefix(comment.Text, payloadAnnotation)), true
+		}
+	}
+
+	return "", false
+}
+
+// fieldSizes are the encoded sizes of supported basic types.
+var fieldSizes = map[string]int{
+	"bool": 1, "byte": 1, "uint8": 1, "int8": 1,
+	"uint16": 2, "int16": 2,
+	"uint32": 4, "int32": 4, "float32": 4,
+	"uint64": 8, "int64": 8, "float64": 8,
+}
+
+// parsePayload describes the struct declared by spec.
+func parsePayload(spec *ast.TypeSpec, header string) (payload, error) {
+	if len(header) != 2 {
+		return payload{}, fmt.Errorf("header %q is not 2 bytes long", header)
+	}
+
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return payload{}, fmt.Errorf("%s is not a struct", spec.Name.Name)
+	}
+
+	p := payload{name: spec.Name.Name, header: header}
+	for _, f := range st.Fields.List {
+		if f.Tag != nil {
+			tag, err := strconv.Unquote(f.Tag.Value)
+			if err == nil && reflect.StructTag(tag).Get("frames") == "-" {
+				continue
+			}
+		}
+
+		if len(f.Names) == 0 {
+			return payload{}, fmt.Errorf("embedded fields are not supported")
+		}
+
+		kind, size, err := fieldType(f.Type)
+		if err != nil {
+			return payload{}, err
+		}
+
+		for _, name := range f.Names {
+			if len(p.fields) > 0 && p.fields[len(p.fields)-1].kind == "slice" {
+				return payload{}, fmt.Errorf("field %s follows a byte slice, which must be the last field", name.Name)
+			}
+			p.fields = append(p.fields, field{name: name.Name, kind: kind, size: size})
+		}
+	}
+
+	return p, nil
+}
+
+// fieldType returns the kind and the encoded size of a field of type expr.
+func fieldType(expr ast.Expr) (kind string, size int, err error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if size, ok := fieldSizes[t.Name]; ok {
+			return t.Name, size, nil
+		}
+	case *ast.ArrayType:
+		if elem, ok := t.Elt.(*ast.Ident); !ok || (elem.Name != "byte" && elem.Name != "uint8") {
+			break
+		}
+
+		if t.Len == nil {
+			return "slice", 0, nil
+		}
+
+		if lit, ok := t.Len.(*ast.BasicLit); ok && lit.Kind == token.INT {
+			n, err := strconv.Atoi(lit.Value)
+			if err == nil {
+				return "array", n, nil
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	format.Node(&buf, token.NewFileSet(), expr)
+	return "", 0, fmt.Errorf("unsupported field type %s", buf.String())
+}
+
+// writeFile writes the generated code for payloads in package pkg to buf.
+func writeFile(buf *bytes.Buffer, pkg string, payloads []payload) {
+	usesBinary, usesMath := false, false
+	for _, p := range payloads {
+		for _, f := range p.fields {
+			usesBinary = usesBinary || f.size > 1 && f.kind != "array"
+			usesMath = usesMath || strings.HasPrefix(f.kind, "float")
+		}
+	}
+
+	fmt.Fprintf(buf, "// Code generated by payloadgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	if usesBinary {
+		fmt.Fprintf(buf, "\t\"encoding/binary\"\n")
+	}
+	if usesMath {
+		fmt.Fprintf(buf, "\t\"math\"\n")
+	}
+	if usesBinary || usesMath {
+		fmt.Fprintf(buf, "\n")
+	}
+	fmt.Fprintf(buf, "\t\"github.com/knei-knurow/frames\"\n)\n")
+
+	for _, p := range payloads {
+		writePayload(buf, p)
+	}
+}
+
+// writePayload writes the methods of a single payload to buf.
+func writePayload(buf *bytes.Buffer, p payload) {
+	fmt.Fprintf(buf, "\n// Header returns the header of frames carrying %s.\n", p.name)
+	fmt.Fprintf(buf, "func (p *%s) Header() [2]byte {\n\treturn [2]byte{%q, %q}\n}\n", p.name, p.header[0], p.header[1])
+
+	fmt.Fprintf(buf, "\n// AppendTo appends p, encoded as frame data, to b.\n")
+	fmt.Fprintf(buf, "func (p *%s) AppendTo(b []byte) []byte {\n", p.name)
+	for _, f := range p.fields {
+		writeAppend(buf, f)
+	}
+	fmt.Fprintf(buf, "\treturn b\n}\n")
+
+	size := 0
+	variable := false
+	for _, f := range p.fields {
+		size += f.size
+		variable = variable || f.kind == "slice"
+	}
+
+	fmt.Fprintf(buf, "\n// ParseFrom decodes p from frame data. It returns frames.ErrPayloadLength if\n// data has a wrong length.\n")
+	fmt.Fprintf(buf, "func (p *%s) ParseFrom(data []byte) error {\n", p.name)
+	if variable {
+		fmt.Fprintf(buf, "\tif len(data) < %d {\n", size)
+	} else {
+		fmt.Fprintf(buf, "\tif len(data) != %d {\n", size)
+	}
+	fmt.Fprintf(buf, "\t\treturn frames.ErrPayloadLength\n\t}\n\n")
+
+	offset := 0
+	for _, f := range p.fields {
+		writeParse(buf, f, offset)
+		offset += f.size
+	}
+	fmt.Fprintf(buf, "\n\treturn nil\n}\n")
+}
+
+// unsignedTypes are the unsigned integer types of the same size as the
+// supported types, used to encode them.
+var unsignedTypes = map[int]string{1: "uint8", 2: "uint16", 4: "uint32", 8: "uint64"}
+
+// bits returns an expression converting field name of kind to an unsigned
+// integer.
+func bits(kind, name string) string {
+	switch kind {
+	case "float32":
+		return "math.Float32bits(" + name + ")"
+	case "float64":
+		return "math.Float64bits(" + name + ")"
+	case "int8", "int16", "int32", "int64":
+		return "u" + kind + "(" + name + ")"
+	default:
+		return name
+	}
+}
+
+// writeAppend writes the code appending field f to b.
+func writeAppend(buf *bytes.Buffer, f field) {
+	name := "p." + f.name
+	switch f.kind {
+	case "bool":
+		fmt.Fprintf(buf, "\tif %s {\n\t\tb = append(b, 1)\n\t} else {\n\t\tb = append(b, 0)\n\t}\n", name)
+	case "array":
+		fmt.Fprintf(buf, "\tb = append(b, %s[:]...)\n", name)
+	case "slice":
+		fmt.Fprintf(buf, "\tb = append(b, %s...)\n", name)
+	default:
+		v := bits(f.kind, name)
+		converted := v != name
+		if converted {
+			fmt.Fprintf(buf, "\t{\n\t\tv := %s\n", v)
+			v = "v"
+		}
+
+		bytes := make([]string, f.size)
+		for i := range bytes {
+			shift := 8 * (f.size - 1 - i)
+			if shift == 0 {
+				bytes[i] = "byte(" + v + ")"
+			} else {
+				bytes[i] = fmt.Sprintf("byte(%s>>%d)", v, shift)
+			}
+		}
+		fmt.Fprintf(buf, "\tb = append(b, %s)\n", strings.Join(bytes, ", "))
+		if converted {
+			fmt.Fprintf(buf, "\t}\n")
+		}
+	}
+}
+
+// writeParse writes the code decoding field f from data at offset.
+func writeParse(buf *bytes.Buffer, f field, offset int) {
+	name := "p." + f.name
+	switch f.kind {
+	case "bool":
+		fmt.Fprintf(buf, "\t%s = data[%d] != 0\n", name, offset)
+	case "array":
+		fmt.Fprintf(buf, "\tcopy(%s[:], data[%d:%d])\n", name, offset, offset+f.size)
+	case "slice":
+		fmt.Fprintf(buf, "\t%s = data[%d:]\n", name, offset)
+	default:
+		unsigned := unsignedTypes[f.size]
+		v := fmt.Sprintf("data[%d]", offset)
+		if f.size > 1 {
+			v = fmt.Sprintf("binary.BigEndian.Uint%d(data[%d:])", 8*f.size, offset)
+		}
+
+		switch f.kind {
+		case "float32":
+			v = "math.Float32frombits(" + v + ")"
+		case "float64":
+			v = "math.Float64frombits(" + v + ")"
+		case "byte", "uint8":
+		default:
+			if f.kind != unsigned {
+				v = f.kind + "(" + v + ")"
+			}
+		}
+		fmt.Fprintf(buf, "\t%s = %s\n", name, v)
+	}
+}
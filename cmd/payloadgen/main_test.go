@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSample(t *testing.T) {
+	path := filepath.Join("internal", "sample", "sample.go")
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := generate(path, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile(filepath.Join("internal", "sample", "sample_payload.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated code differs from sample_payload.go, run go generate:\n%s", got)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{
+			src: "package p\n\n//frames:payload LDX\ntype T struct{ A uint8 }\n",
+			err: `header "LDX" is not 2 bytes long`,
+		},
+		{
+			src: "package p\n\n//frames:payload LD\ntype T struct{ A string }\n",
+			err: "unsupported field type string",
+		},
+		{
+			src: "package p\n\n//frames:payload LD\ntype T struct {\n\tA []byte\n\tB uint8\n}\n",
+			err: "field B follows a byte slice",
+		},
+		{
+			src: "package p\n\n//frames:payload LD\ntype T int\n",
+			err: "T is not a struct",
+		},
+		{
+			src: "package p\n\n// T is not annotated.\ntype T struct{ A uint8 }\n",
+			err: errNoPayloads.Error(),
+		},
+	}
+
+	for i, test := range tests {
+		_, err := generate("p.go", []byte(test.src))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("test %d: got error %v, want error containing %q", i, err, test.err)
+		}
+	}
+}
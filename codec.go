@@ -0,0 +1,618 @@
+package frames
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// ChecksumAlgorithm selects how the checksum at the end of a frame is
+// calculated.
+type ChecksumAlgorithm int
+
+const (
+	// ChecksumXOR is the simple 8-bit XOR checksum used by CalculateChecksum.
+	ChecksumXOR ChecksumAlgorithm = iota
+
+	// ChecksumXMODEM is the 16-bit CRC used by XMODEM (polynomial 0x1021,
+	// initial value 0), transmitted in big-endian byte order.
+	ChecksumXMODEM
+)
+
+// Size returns the number of bytes taken by a checksum calculated with a.
+func (a ChecksumAlgorithm) Size() int {
+	if a == ChecksumXMODEM {
+		return 2
+	}
+
+	return 1
+}
+
+// Codec describes a variant of the frame format, for communicating with peers
+// that deviate from it. The zero value describes the format used by Create and
+// Verify.
+//
+// Frames encoded with a Codec other than the zero value must be accessed with
+// the Codec's methods instead of Frame's methods, because the positions of
+// their parts may differ.
+type Codec struct {
+	// DataChecksum makes the checksum cover only the frame's data. By default
+	// it covers all bytes of the frame except the checksum itself.
+	DataChecksum bool
+
+	// HeaderChecksum adds a checksum of the header and the length byte (and
+	// its complement) directly after them. It lets a decoder reject a corrupted
+	// length before waiting for the data it announces. Combined with
+	// DataChecksum, the header and the data are protected separately.
+	HeaderChecksum bool
+
+	// LengthComplement adds the one's complement of the length byte directly
+	// after the length byte. A decoder rejects frames in which the two do not
+	// match, which catches most corrupted length bytes.
+	LengthComplement bool
+
+	// Preamble is a fixed sequence of bytes preceding the header, e.g.
+	// 0xAA 0x55. It lets a decoder quickly find where frames start in a noisy
+	// stream. The preamble is not covered by the checksum.
+	Preamble []byte
+
+	// CRLF appends a carriage return and a line feed ("\r\n") after the
+	// checksum, so that frames can be typed and read in a plain serial
+	// terminal. The line ending is not covered by the checksum. Note that the
+	// zero Codec's ParseFrame tolerates line endings too: it skips them as
+	// bytes between frames.
+	CRLF bool
+
+	// ChecksumAlgorithm selects how the checksum is calculated. It does not
+	// affect the header checksum, which is always an 8-bit XOR.
+	ChecksumAlgorithm ChecksumAlgorithm
+
+	// HammingHeader encodes every nibble of the header and the length byte as
+	// an extended Hamming(8,4) code word, doubling their size. A decoder
+	// corrects a single flipped bit and detects two flipped bits in every code
+	// word, so the fields it depends on for framing survive most corruption.
+	// Checksums cover the decoded header and length, and frames returned by
+	// ParseFrame are re-encoded, so corrected bits do not show.
+	HammingHeader bool
+
+	// ChecksumBeforeHash places the checksum directly after the data,
+	// followed by the hash sign, as in "HH4+DDDDC#". By default the checksum
+	// follows the hash sign. In both cases the checksum covers all bytes
+	// preceding it, except the preamble.
+	ChecksumBeforeHash bool
+
+	// Extensions adds an extensions area between the data and the hash sign
+	// (or the checksum, if ChecksumBeforeHash is set): a byte holding the
+	// area's length, followed by type-length-value entries, see
+	// Codec.Extension and Codec.SetExtension. The length byte still describes
+	// only the data, and receivers skip extensions they do not know. With
+	// DataChecksum, the checksum covers the extensions too.
+	Extensions bool
+}
+
+// isZero reports whether c is the zero Codec, which describes the default
+// format. A Codec with an empty, non-nil Preamble is zero too.
+func (c Codec) isZero() bool {
+	return !c.DataChecksum && !c.HeaderChecksum && !c.LengthComplement &&
+		len(c.Preamble) == 0 && !c.CRLF && c.ChecksumAlgorithm == ChecksumXOR &&
+		!c.HammingHeader && !c.ChecksumBeforeHash && !c.Extensions
+}
+
+// Create creates a new frame encoded with c. The frame starts with header and
+// contains data. Data length must not overflow byte.
+func (c Codec) Create(header [2]byte, data []byte) (frame Frame) {
+	begin := c.dataOffset()
+	frame = make(Frame, begin+len(data)+c.extensionsOverhead()+c.suffixLen())
+	copy(frame, c.Preamble)
+	c.putFields(frame, header, byte(len(data)))
+	if c.HeaderChecksum {
+		frame[c.headerChecksumOffset()] = c.CalculateHeaderChecksum(frame)
+	}
+	frame[begin-1] = '+'
+	copy(frame[begin:], data)
+	frame[c.hashOffset(frame)] = '#'
+	if c.CRLF {
+		copy(frame[len(frame)-2:], "\r\n")
+	}
+	c.putChecksum(frame, c.CalculateChecksum(frame))
+
+	return
+}
+
+// Convert re-encodes frame, which must be valid according to from, so that it
+// is encoded with to. The header and data are preserved, while everything else
+// (checksums, delimiters, preamble) is recalculated for to. Extensions are
+// preserved if both codecs have them enabled.
+func Convert(frame Frame, from, to Codec) (Frame, error) {
+	if err := from.Validate(frame); err != nil {
+		return nil, err
+	}
+
+	var header [2]byte
+	copy(header[:], from.Header(frame))
+
+	converted := to.Create(header, from.Data(frame))
+	if from.Extensions && to.Extensions {
+		converted = to.withExtensions(converted, from.extensions(frame))
+	}
+
+	return converted, nil
+}
+
+// Header returns frame's header, i.e the 2 bytes following the preamble. If
+// c.HammingHeader is set, the header is decoded.
+func (c Codec) Header(frame Frame) []byte {
+	if c.HammingHeader {
+		h0, _, _ := c.field(frame, 0)
+		h1, _, _ := c.field(frame, 1)
+		return []byte{h0, h1}
+	}
+
+	length := c.lengthOffset()
+	return frame[length-2 : length]
+}
+
+// LenData returns the length of frame's data in bytes, i.e the value of the
+// length byte.
+func (c Codec) LenData(frame Frame) int {
+	length, _, _ := c.field(frame, 2)
+	return int(length)
+}
+
+// putFields stores header and length in frame, together with the length
+// complement if c.LengthComplement is set.
+func (c Codec) putFields(frame Frame, header [2]byte, length byte) {
+	for k, b := range []byte{header[0], header[1], length} {
+		i := len(c.Preamble) + k*c.fieldSize()
+		if c.HammingHeader {
+			frame[i], frame[i+1] = hammingEncode(b>>4), hammingEncode(b&0xf)
+		} else {
+			frame[i] = b
+		}
+	}
+
+	if c.LengthComplement {
+		frame[c.lengthOffset()+c.fieldSize()] = ^length
+	}
+}
+
+// field returns the k-th field of frame, where 0 and 1 are the header bytes and
+// 2 is the length byte, decoded if c.HammingHeader is set. present is false if
+// frame is too short to contain the field, and ok is false if the field is
+// corrupted beyond repair.
+func (c Codec) field(frame Frame, k int) (b byte, present, ok bool) {
+	i := len(c.Preamble) + k*c.fieldSize()
+	if i+c.fieldSize() > len(frame) {
+		return 0, false, false
+	}
+
+	if !c.HammingHeader {
+		return frame[i], true, true
+	}
+
+	hi, okHi := hammingDecode(frame[i])
+	lo, okLo := hammingDecode(frame[i+1])
+	return hi<<4 | lo, true, okHi && okLo
+}
+
+// fields returns frame's header, length byte and the length complement (if
+// c.LengthComplement is set), decoded if c.HammingHeader is set.
+func (c Codec) fields(frame Frame) []byte {
+	length := c.lengthOffset()
+	if !c.HammingHeader {
+		return frame[length-2 : c.headerChecksumOffset()]
+	}
+
+	fields := append(c.Header(frame), byte(c.LenData(frame)))
+	return append(fields, frame[length+c.fieldSize():c.headerChecksumOffset()]...)
+}
+
+// Data returns frame's data part from the first byte after a plus sign ("+") up
+// to the hash sign ("#"), or up to the checksum if c.ChecksumBeforeHash is set,
+// or up to the extensions if c.Extensions is set.
+func (c Codec) Data(frame Frame) []byte {
+	return frame[c.dataOffset():c.dataEnd(frame)]
+}
+
+// Checksum returns frame's checksum, i.e the byte (or 2 bytes, depending on
+// c.ChecksumAlgorithm) following the hash sign, or preceding it if
+// c.ChecksumBeforeHash is set.
+func (c Codec) Checksum(frame Frame) uint16 {
+	offset := c.checksumOffset(frame)
+	if c.ChecksumAlgorithm.Size() == 2 {
+		return uint16(frame[offset])<<8 | uint16(frame[offset+1])
+	}
+
+	return uint16(frame[offset])
+}
+
+// putChecksum stores checksum in frame at the position of the checksum.
+func (c Codec) putChecksum(frame Frame, checksum uint16) {
+	offset := c.checksumOffset(frame)
+	if c.ChecksumAlgorithm.Size() == 2 {
+		frame[offset] = byte(checksum >> 8)
+		frame[offset+1] = byte(checksum)
+		return
+	}
+
+	frame[offset] = byte(checksum)
+}
+
+// Verify checks whether the frame is valid according to c.
+func (c Codec) Verify(frame Frame) bool {
+	return c.Validate(frame) == nil
+}
+
+// Validate checks whether the frame is valid according to c. If the frame is
+// invalid, it returns a *VerifyError describing the offending byte.
+func (c Codec) Validate(frame Frame) error {
+	begin := c.dataOffset()
+	if size := begin + c.extensionsOverhead() + c.suffixLen(); len(frame) < size {
+		return &VerifyError{Offset: len(frame), Want: fmt.Sprintf("at least %d bytes", size), Got: -1}
+	}
+
+	if err := c.validatePrefix(frame); err != nil {
+		return err
+	}
+
+	if c.Extensions {
+		if err := c.validateExtensions(frame); err != nil {
+			return err
+		}
+	}
+
+	if c.LenData(frame) != len(c.Data(frame)) {
+		return &VerifyError{Offset: c.lengthOffset(), Want: fmt.Sprintf("length %#02x", len(c.Data(frame))), Got: c.LenData(frame)}
+	}
+
+	if hash := c.hashOffset(frame); frame[hash] != '#' {
+		return &VerifyError{Offset: hash, Want: "'#'", Got: int(frame[hash])}
+	}
+
+	checksum := c.CalculateChecksum(frame)
+	if checksum != c.Checksum(frame) {
+		want := make(Frame, len(frame))
+		c.putChecksum(want, checksum)
+		offset := c.checksumOffset(frame)
+		for frame[offset] == want[offset] {
+			offset++
+		}
+
+		return &VerifyError{Offset: offset, Want: fmt.Sprintf("checksum %#0*x", 2*c.ChecksumAlgorithm.Size(), checksum), Got: int(frame[offset])}
+	}
+
+	if c.CRLF {
+		for i, b := range []byte("\r\n") {
+			if offset := len(frame) - 2 + i; frame[offset] != b {
+				return &VerifyError{Offset: offset, Want: fmt.Sprintf("%+q", b), Got: int(frame[offset])}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validatePrefix checks the part of frame preceding its data. Only the bytes
+// present in frame are checked, so that a frame can be rejected before it is
+// received completely.
+func (c Codec) validatePrefix(frame Frame) error {
+	for i := 0; i < len(c.Preamble) && i < len(frame); i++ {
+		if frame[i] != c.Preamble[i] {
+			return &VerifyError{Offset: i, Want: fmt.Sprintf("preamble %#02x", c.Preamble[i]), Got: int(frame[i])}
+		}
+	}
+
+	for k := 0; k < 3; k++ {
+		b, present, ok := c.field(frame, k)
+		offset := len(c.Preamble) + k*c.fieldSize()
+		if present && !ok {
+			return &VerifyError{Offset: offset, Want: "Hamming code words", Got: int(frame[offset])}
+		}
+		if present && k < 2 && !validHeaderByte(b) {
+			return &VerifyError{Offset: offset, Want: "uppercase ASCII letter or digit", Got: int(b)}
+		}
+	}
+
+	complement := c.lengthOffset() + c.fieldSize()
+	if c.LengthComplement && len(frame) > complement && frame[complement] != ^byte(c.LenData(frame)) {
+		return &VerifyError{Offset: complement, Want: fmt.Sprintf("length complement %#02x", ^byte(c.LenData(frame))), Got: int(frame[complement])}
+	}
+
+	begin := c.dataOffset()
+	if len(frame) < begin {
+		return nil
+	}
+
+	if c.HeaderChecksum {
+		offset := c.headerChecksumOffset()
+		checksum := c.CalculateHeaderChecksum(frame)
+		if checksum != frame[offset] {
+			return &VerifyError{Offset: offset, Want: fmt.Sprintf("header checksum %#02x", checksum), Got: int(frame[offset])}
+		}
+	}
+
+	if frame[begin-1] != '+' {
+		return &VerifyError{Offset: begin - 1, Want: "'+'", Got: int(frame[begin-1])}
+	}
+
+	return nil
+}
+
+// CalculateChecksum calculates the checksum of frame according to c. It does
+// not check whether the frame is correct.
+func (c Codec) CalculateChecksum(frame Frame) uint16 {
+	covered := frame[len(c.Preamble):c.checksumOffset(frame)]
+	if c.HammingHeader {
+		covered = append(c.fields(frame), frame[c.headerChecksumOffset():c.checksumOffset(frame)]...)
+	}
+	if c.DataChecksum {
+		covered = frame[c.dataOffset():c.suffixOffset(frame)]
+	}
+
+	if c.ChecksumAlgorithm == ChecksumXMODEM {
+		return xmodemCRC(covered)
+	}
+
+	var crc byte
+	for _, b := range covered {
+		crc ^= b
+	}
+
+	return uint16(crc)
+}
+
+// xmodemCRC calculates the CRC-16/XMODEM of b.
+func xmodemCRC(b []byte) (crc uint16) {
+	for _, v := range b {
+		crc ^= uint16(v) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return
+}
+
+// CalculateHeaderChecksum calculates the checksum of all frame's bytes
+// preceding the header checksum, except the preamble. The header checksum is
+// placed after the length byte (and its complement) if c.HeaderChecksum is set.
+// It does not check whether the frame is correct.
+func (c Codec) CalculateHeaderChecksum(frame Frame) (crc byte) {
+	for _, b := range c.fields(frame) {
+		crc ^= b
+	}
+
+	return
+}
+
+// ParseFrame parses the first frame in buf that is valid according to c. It
+// works like the ParseFrame function.
+//
+// If c.Preamble is set, only positions where the preamble is found are
+// considered. If c.HeaderChecksum or c.LengthComplement is set, a candidate
+// frame with an invalid header checksum or length complement is skipped
+// immediately instead of waiting for the data announced by its possibly
+// corrupted length byte.
+func (c Codec) ParseFrame(buf []byte) (frame Frame, n int, err error) {
+	frame, n, _, err = c.parseFrame(buf, false)
+	if err == nil {
+		frame = Recreate(frame)
+	}
+
+	return
+}
+
+// parseFrame works like ParseFrame, but the returned frame may refer to buf. If
+// correct is set, candidate frames that are complete but invalid are repaired
+// with Correct if possible, which is reported by corrected.
+func (c Codec) parseFrame(buf []byte, correct bool) (frame Frame, n int, corrected bool, err error) {
+	begin := c.dataOffset()
+	for i := 0; i < len(buf); i++ {
+		if c.validatePrefix(buf[i:]) != nil {
+			continue
+		}
+
+		if len(buf)-i < begin {
+			return nil, i, false, ErrIncomplete
+		}
+
+		end := i + begin + c.LenData(buf[i:]) + c.suffixLen()
+		if c.Extensions {
+			area := end - c.suffixLen()
+			if area >= len(buf) {
+				return nil, i, false, ErrIncomplete
+			}
+			end += 1 + int(buf[area])
+		}
+		if end > len(buf) {
+			return nil, i, false, ErrIncomplete
+		}
+
+		if c.Verify(buf[i:end]) {
+			if c.HammingHeader {
+				return c.recreate(buf[i:end]), end, false, nil
+			}
+			return Frame(buf[i:end:end]), end, false, nil
+		}
+
+		if correct {
+			if frame, ok := c.Correct(buf[i:end]); ok {
+				return c.recreate(frame), end, true, nil
+			}
+		}
+	}
+
+	return nil, len(buf), false, ErrIncomplete
+}
+
+// Correct tries to repair frame, invalid according to c, by flipping a single
+// bit. If exactly one such flip makes the frame valid, Correct returns the
+// repaired copy of frame and true. A valid frame is returned as a copy too.
+//
+// With ChecksumXOR, a flipped bit in the header, data or checksum usually
+// cannot be told apart from the same bit flipped in another byte, so mostly
+// corrupted delimiters and length bytes are repaired. With ChecksumXMODEM,
+// every single-bit error can be repaired.
+func (c Codec) Correct(frame Frame) (Frame, bool) {
+	if c.Verify(frame) {
+		return Recreate(frame), true
+	}
+
+	candidate := Recreate(frame)
+	var repaired Frame
+	for i := range candidate {
+		for bit := 0; bit < 8; bit++ {
+			candidate[i] ^= 1 << bit
+			if c.Verify(candidate) {
+				if repaired != nil {
+					return nil, false
+				}
+				repaired = Recreate(candidate)
+			}
+			candidate[i] ^= 1 << bit
+		}
+	}
+
+	return repaired, repaired != nil
+}
+
+// recreate returns a copy of valid frame. If c.HammingHeader is set, its
+// header and length are re-encoded, dropping corrected errors.
+func (c Codec) recreate(frame Frame) Frame {
+	copied := Recreate(frame)
+	if c.HammingHeader {
+		var header [2]byte
+		copy(header[:], c.Header(frame))
+		c.putFields(copied, header, byte(c.LenData(frame)))
+	}
+
+	return copied
+}
+
+// dataOffset returns the index of the first data byte in frames encoded with
+// c.
+func (c Codec) dataOffset() int {
+	offset := c.lengthOffset() + c.fieldSize() + 1 // length byte and plus sign
+	if c.LengthComplement {
+		offset++
+	}
+	if c.HeaderChecksum {
+		offset++
+	}
+
+	return offset
+}
+
+// lengthOffset returns the index of the length byte in frames encoded with c.
+func (c Codec) lengthOffset() int {
+	return len(c.Preamble) + 2*c.fieldSize()
+}
+
+// fieldSize returns the number of bytes taken by each header byte and by the
+// length byte in frames encoded with c.
+func (c Codec) fieldSize() int {
+	if c.HammingHeader {
+		return 2
+	}
+
+	return 1
+}
+
+// suffixLen returns the number of bytes following the data in frames encoded
+// with c.
+func (c Codec) suffixLen() int {
+	n := 1 + c.ChecksumAlgorithm.Size() // hash sign and checksum
+	if c.CRLF {
+		n += 2
+	}
+
+	return n
+}
+
+// suffixOffset returns the index of the first byte following the data and the
+// extensions in frame encoded with c.
+func (c Codec) suffixOffset(frame Frame) int {
+	return len(frame) - c.suffixLen()
+}
+
+// dataEnd returns the index of the first byte following the data in frame
+// encoded with c.
+func (c Codec) dataEnd(frame Frame) int {
+	end := c.suffixOffset(frame)
+	if c.Extensions && c.dataOffset()+c.LenData(frame) < end {
+		end = c.dataOffset() + c.LenData(frame)
+	}
+
+	return end
+}
+
+// extensionsOverhead returns the number of bytes taken by an empty extensions
+// area in frames encoded with c.
+func (c Codec) extensionsOverhead() int {
+	if c.Extensions {
+		return 1
+	}
+
+	return 0
+}
+
+// hashOffset returns the index of the hash sign in frame encoded with c.
+func (c Codec) hashOffset(frame Frame) int {
+	if c.ChecksumBeforeHash {
+		return c.suffixOffset(frame) + c.ChecksumAlgorithm.Size()
+	}
+
+	return c.suffixOffset(frame)
+}
+
+// checksumOffset returns the index of the checksum in frame encoded with c.
+func (c Codec) checksumOffset(frame Frame) int {
+	if c.ChecksumBeforeHash {
+		return c.suffixOffset(frame)
+	}
+
+	return c.hashOffset(frame) + 1
+}
+
+// headerChecksumOffset returns the index of the header checksum in frames
+// encoded with c, if c.HeaderChecksum is set.
+func (c Codec) headerChecksumOffset() int {
+	if c.LengthComplement {
+		return c.lengthOffset() + c.fieldSize() + 1
+	}
+
+	return c.lengthOffset() + c.fieldSize()
+}
+
+// hammingCodeWords are the extended Hamming(8,4) code words of all nibbles.
+// The bits are, from the lowest: 3 parity bits, 4 data bits and the overall
+// parity bit.
+var hammingCodeWords = func() (words [16]byte) {
+	for n := range words {
+		d := func(i int) byte { return byte(n>>i) & 1 }
+		word := (d(0) ^ d(1) ^ d(3)) | (d(0)^d(2)^d(3))<<1 | (d(1)^d(2)^d(3))<<2 | byte(n)<<3
+		words[n] = word | byte(bits.OnesCount8(word)&1)<<7
+	}
+
+	return
+}()
+
+// hammingEncode returns the code word of the lower nibble of n.
+func hammingEncode(n byte) byte {
+	return hammingCodeWords[n&0xf]
+}
+
+// hammingDecode returns the nibble encoded by word, correcting a single flipped
+// bit. ok is false if more bits are flipped.
+func hammingDecode(word byte) (n byte, ok bool) {
+	for i, w := range hammingCodeWords {
+		if bits.OnesCount8(w^word) <= 1 {
+			return byte(i), true
+		}
+	}
+
+	return 0, false
+}
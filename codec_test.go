@@ -0,0 +1,382 @@
+package frames_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestCodecDataChecksum(t *testing.T) {
+	codec := frames.Codec{DataChecksum: true}
+
+	codecTestCases := []struct {
+		inputHeader [2]byte
+		inputData   []byte
+		frame       []byte
+	}{
+		{
+			inputHeader: [2]byte{'L', 'D'},
+			inputData:   []byte{},
+			frame:       []byte{'L', 'D', 0x0, '+', '#', 0x00},
+		},
+		{
+			inputHeader: [2]byte{'L', 'D'},
+			inputData:   []byte{'A'},
+			frame:       []byte{'L', 'D', 0x1, '+', 'A', '#', 0x41},
+		},
+		{
+			inputHeader: [2]byte{'M', 'T'},
+			inputData:   []byte{'d', 'o', 'n', 'd', 'u'},
+			frame:       []byte{'M', 'T', 0x5, '+', 'd', 'o', 'n', 'd', 'u', '#', 0x74},
+		},
+	}
+
+	for i, tc := range codecTestCases {
+		testName := fmt.Sprintf("test %d", i)
+		t.Run(testName, func(t *testing.T) {
+			gotFrame := codec.Create(tc.inputHeader, tc.inputData)
+			if !bytes.Equal(gotFrame, tc.frame) {
+				t.Errorf("got frame % x, want frame % x", []byte(gotFrame), tc.frame)
+			}
+
+			if !codec.Verify(gotFrame) {
+				t.Errorf("frame verification failed for % x", []byte(gotFrame))
+			}
+		})
+	}
+
+	// a frame with the checksum covering the whole frame is invalid
+	if codec.Verify(frames.Create([2]byte{'L', 'D'}, []byte{'A'})) {
+		t.Errorf("frame with whole-frame checksum verified with data checksum")
+	}
+}
+
+func TestCodecHeaderChecksum(t *testing.T) {
+	codec := frames.Codec{HeaderChecksum: true, DataChecksum: true}
+
+	frame := codec.Create([2]byte{'L', 'D'}, []byte{'A', 'B'})
+	want := []byte{'L', 'D', 0x2, 0x0a, '+', 'A', 'B', '#', 0x03}
+	if !bytes.Equal(frame, want) {
+		t.Fatalf("got frame % x, want frame % x", []byte(frame), want)
+	}
+
+	if !bytes.Equal(codec.Data(frame), []byte{'A', 'B'}) {
+		t.Errorf("got data % x, want data % x", codec.Data(frame), []byte{'A', 'B'})
+	}
+
+	if !codec.Verify(frame) {
+		t.Errorf("frame verification failed for % x", []byte(frame))
+	}
+
+	// corrupted length byte is rejected without waiting for the data
+	corrupted := []byte{'L', 'D', 0xff, 0x0a, '+', 'x'}
+	_, n, err := codec.ParseFrame(corrupted)
+	if err != frames.ErrIncomplete || n != len(corrupted) {
+		t.Errorf("got %d bytes consumed and error %v, want %d and %v", n, err, len(corrupted), frames.ErrIncomplete)
+	}
+
+	buf := append(corrupted, frame...)
+	parsed, n, err := codec.ParseFrame(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != len(buf) || !bytes.Equal(parsed, frame) {
+		t.Errorf("got frame % x and %d bytes consumed, want frame % x and %d", []byte(parsed), n, []byte(frame), len(buf))
+	}
+}
+
+func TestCodecLengthComplement(t *testing.T) {
+	codec := frames.Codec{LengthComplement: true}
+
+	frame := codec.Create([2]byte{'L', 'D'}, []byte{'A'})
+	want := []byte{'L', 'D', 0x1, 0xfe, '+', 'A', '#', 0xbe}
+	if !bytes.Equal(frame, want) {
+		t.Fatalf("got frame % x, want frame % x", []byte(frame), want)
+	}
+
+	if !codec.Verify(frame) {
+		t.Errorf("frame verification failed for % x", []byte(frame))
+	}
+
+	// corrupted length byte is rejected as soon as its complement arrives
+	corrupted := []byte{'x', 'L', 'D', 0x7f, 0xfe}
+	_, n, err := codec.ParseFrame(corrupted)
+	if err != frames.ErrIncomplete || n != len(corrupted) {
+		t.Errorf("got %d bytes consumed and error %v, want %d and %v", n, err, len(corrupted), frames.ErrIncomplete)
+	}
+
+	// both options combined
+	codec.HeaderChecksum = true
+	frame = codec.Create([2]byte{'L', 'D'}, []byte{'A'})
+	want = []byte{'L', 'D', 0x1, 0xfe, 0xf7, '+', 'A', '#', 0x49}
+	if !bytes.Equal(frame, want) {
+		t.Fatalf("got frame % x, want frame % x", []byte(frame), want)
+	}
+
+	if !codec.Verify(frame) {
+		t.Errorf("frame verification failed for % x", []byte(frame))
+	}
+}
+
+func TestCodecPreamble(t *testing.T) {
+	codec := frames.Codec{Preamble: []byte{0xaa, 0x55}}
+
+	frame := codec.Create([2]byte{'L', 'D'}, []byte{'A'})
+	want := []byte{0xaa, 0x55, 'L', 'D', 0x1, '+', 'A', '#', 0x40}
+	if !bytes.Equal(frame, want) {
+		t.Fatalf("got frame % x, want frame % x", []byte(frame), want)
+	}
+
+	if !bytes.Equal(codec.Header(frame), []byte{'L', 'D'}) || codec.LenData(frame) != 1 {
+		t.Errorf("got header % x and length %d, want header % x and length 1", codec.Header(frame), codec.LenData(frame), []byte{'L', 'D'})
+	}
+
+	if !codec.Verify(frame) {
+		t.Errorf("frame verification failed for % x", []byte(frame))
+	}
+
+	// a valid frame without the preamble is not recognized
+	buf := []byte{'L', 'D', 0x0, '+', '#', 0x00, 0xaa, 0xaa}
+	_, n, err := codec.ParseFrame(buf)
+	if err != frames.ErrIncomplete || n != 7 {
+		t.Errorf("got %d bytes consumed and error %v, want 7 and %v", n, err, frames.ErrIncomplete)
+	}
+
+	buf = append(buf, want[1:]...)
+	parsed, n, err := codec.ParseFrame(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != len(buf) || !bytes.Equal(parsed, want) {
+		t.Errorf("got frame % x and %d bytes consumed, want frame % x and %d", []byte(parsed), n, want, len(buf))
+	}
+}
+
+func TestCodecCRLF(t *testing.T) {
+	codec := frames.Codec{CRLF: true}
+
+	frame := codec.Create([2]byte{'L', 'D'}, []byte{'A'})
+	want := []byte{'L', 'D', 0x1, '+', 'A', '#', 0x40, '\r', '\n'}
+	if !bytes.Equal(frame, want) {
+		t.Fatalf("got frame % x, want frame % x", []byte(frame), want)
+	}
+
+	if !bytes.Equal(codec.Data(frame), []byte{'A'}) || codec.Checksum(frame) != 0x40 {
+		t.Errorf("got data % x and checksum %#02x, want data 41 and checksum 0x40", codec.Data(frame), codec.Checksum(frame))
+	}
+
+	if !codec.Verify(frame) {
+		t.Errorf("frame verification failed for % x", []byte(frame))
+	}
+
+	if codec.Verify(want[:len(want)-1]) {
+		t.Errorf("frame without line feed verified")
+	}
+
+	// the zero codec skips line endings between frames
+	buf := append(append([]byte{}, want...), want...)
+	_, n, err := frames.ParseFrame(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, m, err := frames.ParseFrame(buf[n:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n+m != len(buf)-2 || !bytes.Equal(parsed, want[:len(want)-2]) {
+		t.Errorf("got frame % x and %d bytes consumed, want frame % x and %d", []byte(parsed), n+m, want[:len(want)-2], len(buf)-2)
+	}
+}
+
+func TestCodecChecksumBeforeHash(t *testing.T) {
+	codec := frames.Codec{ChecksumBeforeHash: true}
+
+	frame := codec.Create([2]byte{'L', 'D'}, []byte{'A'})
+	want := []byte{'L', 'D', 0x1, '+', 'A', 0x63, '#'}
+	if !bytes.Equal(frame, want) {
+		t.Fatalf("got frame % x, want frame % x", []byte(frame), want)
+	}
+
+	if !bytes.Equal(codec.Data(frame), []byte{'A'}) || codec.Checksum(frame) != 0x63 {
+		t.Errorf("got data % x and checksum %#02x, want data 41 and checksum 0x63", codec.Data(frame), codec.Checksum(frame))
+	}
+
+	if !codec.Verify(frame) {
+		t.Errorf("frame verification failed for % x", []byte(frame))
+	}
+
+	if err := codec.Validate([]byte{'L', 'D', 0x1, '+', 'A', '#', 0x63}); err == nil || err.Error() != "frames: offset 6: want '#', got 0x63" {
+		t.Errorf("got error %v for trailing checksum, want misplaced hash sign", err)
+	}
+
+	xmodem := frames.Codec{ChecksumBeforeHash: true, ChecksumAlgorithm: frames.ChecksumXMODEM, CRLF: true}
+	frame = xmodem.Create([2]byte{'L', 'D'}, []byte("test"))
+	buf := append([]byte("xd"), frame...)
+
+	parsed, n, err := xmodem.ParseFrame(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != len(buf) || !bytes.Equal(parsed, frame) || !bytes.Equal(xmodem.Data(parsed), []byte("test")) {
+		t.Errorf("got frame % x and %d bytes consumed, want frame % x and %d", []byte(parsed), n, []byte(frame), len(buf))
+	}
+
+	if end := frame[len(frame)-3:]; !bytes.Equal(end, []byte("#\r\n")) {
+		t.Errorf("got frame ending with % x, want hash sign and line ending", end)
+	}
+}
+
+func TestCodecXMODEM(t *testing.T) {
+	codec := frames.Codec{DataChecksum: true, ChecksumAlgorithm: frames.ChecksumXMODEM}
+
+	frame := codec.Create([2]byte{'L', 'D'}, []byte("123456789"))
+	want := append([]byte{'L', 'D', 0x9, '+'}, "123456789#\x31\xc3"...)
+	if !bytes.Equal(frame, want) {
+		t.Fatalf("got frame % x, want frame % x", []byte(frame), want)
+	}
+
+	if codec.Checksum(frame) != 0x31c3 {
+		t.Errorf("got checksum %#04x, want checksum 0x31c3", codec.Checksum(frame))
+	}
+
+	if !codec.Verify(frame) {
+		t.Errorf("frame verification failed for % x", []byte(frame))
+	}
+
+	frame[len(frame)-1] = 0xc4
+	wantErr := "frames: offset 15: want checksum 0x31c3, got 0xc4"
+	if err := codec.Validate(frame); err == nil || err.Error() != wantErr {
+		t.Errorf("got error %v, want error %q", err, wantErr)
+	}
+}
+
+func TestCodecHammingHeader(t *testing.T) {
+	codec := frames.Codec{HammingHeader: true}
+
+	frame := codec.Create([2]byte{'L', 'D'}, []byte{'A'})
+	want := []byte{0xa6, 0xe1, 0xa6, 0xa6, 0x00, 0x8b, '+', 'A', '#', 0x40}
+	if !bytes.Equal(frame, want) {
+		t.Fatalf("got frame % x, want frame % x", []byte(frame), want)
+	}
+
+	if string(codec.Header(frame)) != "LD" || codec.LenData(frame) != 1 || string(codec.Data(frame)) != "A" {
+		t.Errorf("got header %q, length %d and data %q, want LD, 1 and A", codec.Header(frame), codec.LenData(frame), codec.Data(frame))
+	}
+
+	// a single flipped bit in every code word is corrected
+	for offset := 0; offset < 6; offset++ {
+		for bit := 0; bit < 8; bit++ {
+			corrupted := frames.Recreate(frame)
+			corrupted[offset] ^= 1 << bit
+
+			got, n, err := codec.ParseFrame(append([]byte{'x'}, corrupted...))
+			if err != nil {
+				t.Fatalf("offset %d, bit %d: %v", offset, bit, err)
+			}
+
+			if !bytes.Equal(got, frame) || n != len(frame)+1 {
+				t.Errorf("offset %d, bit %d: got frame % x and %d bytes, want frame % x and %d bytes", offset, bit, []byte(got), n, []byte(frame), len(frame)+1)
+			}
+		}
+	}
+
+	// two flipped bits in a code word are detected
+	corrupted := frames.Recreate(frame)
+	corrupted[4] ^= 0x03
+	if err := codec.Validate(corrupted); err == nil || err.(*frames.VerifyError).Offset != 4 {
+		t.Errorf("got error %v, want error at offset 4", err)
+	}
+
+	// combined with other options
+	codec = frames.Codec{HammingHeader: true, HeaderChecksum: true, LengthComplement: true, Preamble: []byte{0xaa, 0x55}, ChecksumAlgorithm: frames.ChecksumXMODEM}
+	frame = codec.Create([2]byte{'M', 'T'}, []byte("dondu"))
+	frame[3] ^= 0x10
+	frame[7] ^= 0x01
+	if err := codec.Validate(frame); err != nil {
+		t.Errorf("got error %v for frame % x", err, []byte(frame))
+	}
+	if got, err := frames.Convert(frame, codec, frames.Codec{}); err != nil || !bytes.Equal(got, frames.Create([2]byte{'M', 'T'}, []byte("dondu"))) {
+		t.Errorf("got frame % x and error %v after conversion", []byte(got), err)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	codecs := []frames.Codec{
+		{},
+		{DataChecksum: true},
+		{HeaderChecksum: true, LengthComplement: true},
+		{Preamble: []byte{0xaa, 0x55}, CRLF: true},
+		{ChecksumAlgorithm: frames.ChecksumXMODEM},
+	}
+
+	for i, from := range codecs {
+		for j, to := range codecs {
+			testName := fmt.Sprintf("test %d to %d", i, j)
+			t.Run(testName, func(t *testing.T) {
+				frame := from.Create([2]byte{'M', 'T'}, []byte("dondu"))
+
+				got, err := frames.Convert(frame, from, to)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := to.Create([2]byte{'M', 'T'}, []byte("dondu"))
+				if !bytes.Equal(got, want) {
+					t.Errorf("got frame % x, want frame % x", []byte(got), []byte(want))
+				}
+			})
+		}
+	}
+
+	if _, err := frames.Convert(frames.Frame{'x', 'd'}, frames.Codec{}, frames.Codec{CRLF: true}); err == nil {
+		t.Errorf("invalid frame converted")
+	}
+}
+
+func TestCorrect(t *testing.T) {
+	xmodem := frames.Codec{ChecksumAlgorithm: frames.ChecksumXMODEM}
+
+	tests := []struct {
+		codec  frames.Codec
+		offset int // of the corrupted byte
+		mask   byte
+		ok     bool
+	}{
+		{frames.Codec{}, 3, 0x01, true},  // plus sign
+		{frames.Codec{}, 8, 0x40, true},  // hash sign
+		{frames.Codec{}, 2, 0x10, true},  // length byte
+		{frames.Codec{}, 5, 0x04, false}, // data byte, ambiguous
+		{xmodem, 5, 0x04, true},
+		{xmodem, 0, 0x02, true},
+		{xmodem, 9, 0x80, true},
+		{frames.Codec{}, 5, 0x05, false}, // two bits
+	}
+
+	for i, tc := range tests {
+		frame := tc.codec.Create([2]byte{'L', 'D'}, []byte("test"))
+		corrupted := frames.Recreate(frame)
+		corrupted[tc.offset] ^= tc.mask
+
+		got, ok := tc.codec.Correct(corrupted)
+		if ok != tc.ok {
+			t.Errorf("test %d: got %t, want %t", i, ok, tc.ok)
+			continue
+		}
+
+		if ok && !bytes.Equal(got, frame) {
+			t.Errorf("test %d: got frame % x, want frame % x", i, []byte(got), []byte(frame))
+		}
+	}
+
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("test"))
+	if got, ok := frames.Correct(frame); !ok || !bytes.Equal(got, frame) {
+		t.Errorf("got frame % x, %t for a valid frame, want the same frame", []byte(got), ok)
+	}
+}
@@ -0,0 +1,67 @@
+package frames
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvColumns are the names of the columns written by CSVWriter.
+var csvColumns = []string{"timestamp", "direction", "header", "length", "data", "checksum_ok"}
+
+// CSVWriter writes records as CSV, one row per frame, for analysis in
+// spreadsheets and the like. The first row names the columns: timestamp (in
+// RFC 3339 format), direction, header, length, data (in hex) and checksum_ok
+// (whether the frame is valid).
+//
+// Frames shorter than an empty frame are written with these columns left empty
+// and all their bytes as data.
+type CSVWriter struct {
+	w       *csv.Writer
+	started bool
+}
+
+// NewCSVWriter creates a CSVWriter writing to w.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// Write writes record as a single row. The row is flushed to the underlying
+// writer immediately, so that CSVWriter can be used for live streams.
+func (c *CSVWriter) Write(record Record) error {
+	if !c.started {
+		c.started = true
+		if err := c.w.Write(csvColumns); err != nil {
+			return err
+		}
+	}
+
+	row := []string{record.Time.Format(time.RFC3339Nano), record.Direction.String(), "", "", hex.EncodeToString(record.Frame), "false"}
+	if len(record.Frame) >= minFrameLen {
+		row[2] = string(record.Frame.Header())
+		row[3] = strconv.Itoa(record.Frame.LenData())
+		row[4] = hex.EncodeToString(record.Frame.Data())
+		row[5] = strconv.FormatBool(Verify(record.Frame))
+	}
+
+	if err := c.w.Write(row); err != nil {
+		return err
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// WriteCSV writes records to w with a CSVWriter.
+func WriteCSV(w io.Writer, records []Record) error {
+	c := NewCSVWriter(w)
+	for _, record := range records {
+		if err := c.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package frames_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestWriteCSV(t *testing.T) {
+	start := time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC)
+	records := []frames.Record{
+		{Time: start, Direction: frames.Sent, Frame: frames.Frame{'L', 'D', 0x1, '+', 'A', '#', 0x40}},
+		{Time: start.Add(1500 * time.Microsecond), Direction: frames.Received, Frame: frames.Frame{'M', 'T', 0x2, '+', 0x01, 'x', '#', 0x00}},
+		{Time: start.Add(3 * time.Millisecond), Direction: frames.Received, Frame: frames.Frame{'x', 'd'}},
+	}
+
+	want := "" +
+		"timestamp,direction,header,length,data,checksum_ok\n" +
+		"2021-03-14T15:09:26Z,tx,LD,1,41,true\n" +
+		"2021-03-14T15:09:26.0015Z,rx,MT,2,0178,false\n" +
+		"2021-03-14T15:09:26.003Z,rx,,,7864,false\n"
+
+	var buf bytes.Buffer
+	if err := frames.WriteCSV(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Errorf("got CSV\n%s\nwant CSV\n%s", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+package frames
+
+// Decoder decodes frames fed to it byte by byte. Its entire state lives in a
+// single buffer allocated by NewDecoder, so decoding never allocates, which
+// suits soft real-time loops. Bytes that are not part of any valid frame are
+// skipped, like in ParseFrame.
+//
+// Decoder supports only the format used by Create and Verify.
+type Decoder struct {
+	buf []byte
+}
+
+// NewDecoder creates a Decoder for frames of up to maxFrameSize bytes. Longer
+// frames are skipped.
+func NewDecoder(maxFrameSize int) *Decoder {
+	return &Decoder{buf: make([]byte, 0, maxFrameSize)}
+}
+
+// Feed feeds b to the decoder. If b completes a valid frame, the frame is
+// returned. The frame refers to the decoder's buffer, so it is valid only until
+// the next call.
+func (d *Decoder) Feed(b byte) (frame Frame, ok bool) {
+	if cap(d.buf) == 0 {
+		return nil, false
+	}
+
+	d.buf = append(d.buf, b)
+	for len(d.buf) > 0 {
+		switch d.check() {
+		case decoderIncomplete:
+			return nil, false
+		case decoderComplete:
+			frame = Frame(d.buf)
+			d.buf = d.buf[:0]
+			return frame, true
+		}
+
+		// no frame starts at the first byte, look for one further
+		d.buf = d.buf[:copy(d.buf, d.buf[1:])]
+	}
+
+	return nil, false
+}
+
+// Decode feeds bytes from p to the decoder until a valid frame is complete. It
+// returns the frame, or nil if p ended first, and the number of bytes consumed.
+// The frame is valid only until the next call.
+func (d *Decoder) Decode(p []byte) (frame Frame, n int) {
+	for i, b := range p {
+		if frame, ok := d.Feed(b); ok {
+			return frame, i + 1
+		}
+	}
+
+	return nil, len(p)
+}
+
+// Results of Decoder.check.
+const (
+	decoderIncomplete = iota
+	decoderComplete
+	decoderInvalid
+)
+
+// check tells whether the buffered bytes are the beginning of a valid frame, a
+// complete valid frame, or neither.
+func (d *Decoder) check() int {
+	buf := d.buf
+	for i := 0; i < 2 && i < len(buf); i++ {
+		if !validHeaderByte(buf[i]) {
+			return decoderInvalid
+		}
+	}
+
+	if len(buf) < 3 {
+		return decoderIncomplete
+	}
+
+	total := int(buf[2]) + minFrameLen
+	if total > cap(buf) || len(buf) > 3 && buf[3] != '+' {
+		return decoderInvalid
+	}
+
+	if len(buf) < total {
+		return decoderIncomplete
+	}
+
+	if buf[total-2] != '#' || CalculateChecksum(buf) != buf[total-1] {
+		return decoderInvalid
+	}
+
+	return decoderComplete
+}
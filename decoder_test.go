@@ -0,0 +1,60 @@
+package frames_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestDecoder(t *testing.T) {
+	long := frames.Create([2]byte{'L', 'D'}, bytes.Repeat([]byte{'x'}, 40))
+
+	var stream []byte
+	var want []frames.Frame
+	for _, tc := range testCases {
+		if len(tc.frame) > 32 {
+			continue
+		}
+
+		// garbage looking like the beginning of a frame
+		stream = append(stream, 'L', 'D', 0x02, '+', 'x')
+		stream = append(stream, tc.frame...)
+		want = append(want, tc.frame)
+	}
+	stream = append(stream, long...)
+
+	decoder := frames.NewDecoder(32)
+	var got []frames.Frame
+	for p := stream; len(p) > 0; {
+		frame, n := decoder.Decode(p)
+		if frame != nil {
+			got = append(got, frames.Recreate(frame))
+		}
+		p = p[n:]
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d frames, want %d", len(got), len(want))
+	}
+
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("frame %d: got % x, want % x", i, []byte(got[i]), []byte(want[i]))
+		}
+	}
+}
+
+func TestDecoderAllocs(t *testing.T) {
+	stream := append([]byte("garbage"), frames.Create([2]byte{'L', 'D'}, []byte("test"))...)
+	decoder := frames.NewDecoder(64)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, b := range stream {
+			decoder.Feed(b)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+}
@@ -0,0 +1,61 @@
+package frames
+
+import "sync"
+
+// Dedup detects duplicate frames, e.g. retransmitted ones, among the most
+// recently received frames. Frames are identified by a key, which by default
+// is a hash of the frame's header and data, but can be e.g. a sequence number
+// carried in the data.
+//
+// Dedup is safe for concurrent use.
+type Dedup struct {
+	key func(Frame) uint64
+
+	mu     sync.Mutex
+	seen   map[uint64]struct{}
+	window []uint64
+	next   int
+}
+
+// NewDedup creates a Dedup remembering the keys of the last window frames. If
+// key is nil, frames are identified by a hash of their header and data.
+func NewDedup(window int, key func(Frame) uint64) *Dedup {
+	if key == nil {
+		key = Frame.Hash
+	}
+
+	return &Dedup{
+		key:    key,
+		seen:   make(map[uint64]struct{}, window),
+		window: make([]uint64, 0, window),
+	}
+}
+
+// Duplicate reports whether a frame with the same key as frame is among the
+// remembered ones. If it is not, frame's key is remembered, and the oldest key
+// is forgotten if the window is full.
+func (d *Dedup) Duplicate(frame Frame) bool {
+	key := d.key(frame)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.seen[key]; ok {
+		return true
+	}
+
+	if cap(d.window) == 0 {
+		return false
+	}
+
+	if len(d.window) < cap(d.window) {
+		d.window = append(d.window, key)
+	} else {
+		delete(d.seen, d.window[d.next])
+		d.window[d.next] = key
+		d.next = (d.next + 1) % len(d.window)
+	}
+	d.seen[key] = struct{}{}
+
+	return false
+}
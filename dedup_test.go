@@ -0,0 +1,43 @@
+package frames_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestDedup(t *testing.T) {
+	a := frames.Create([2]byte{'L', 'D'}, []byte("a"))
+	b := frames.Create([2]byte{'L', 'D'}, []byte("b"))
+	c := frames.Create([2]byte{'L', 'D'}, []byte("c"))
+
+	dedup := frames.NewDedup(2, nil)
+
+	input := []frames.Frame{a, b, a, c, a, b, a}
+	want := []bool{false, false, true, false, false, false, true}
+
+	for i, frame := range input {
+		testName := fmt.Sprintf("test %d", i)
+		t.Run(testName, func(t *testing.T) {
+			if got := dedup.Duplicate(frame); got != want[i] {
+				t.Errorf("got duplicate %t, want %t", got, want[i])
+			}
+		})
+	}
+}
+
+func TestDedupSequence(t *testing.T) {
+	// the first data byte is a sequence number
+	dedup := frames.NewDedup(16, func(f frames.Frame) uint64 {
+		return uint64(f.Data()[0])
+	})
+
+	if dedup.Duplicate(frames.Create([2]byte{'L', 'D'}, []byte{1, 'a'})) {
+		t.Errorf("first frame reported as duplicate")
+	}
+
+	if !dedup.Duplicate(frames.Create([2]byte{'L', 'D'}, []byte{1, 'b'})) {
+		t.Errorf("frame with repeated sequence number not reported as duplicate")
+	}
+}
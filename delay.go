@@ -0,0 +1,234 @@
+package frames
+
+import (
+	"container/heap"
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrDelayConfig is returned by NewDelayWriter when its configuration has a
+// negative delay, jitter or MaxReorder.
+var ErrDelayConfig = errors.New("frames: invalid delay configuration")
+
+// DelayDistribution selects how delays of a DelayWriter are drawn.
+type DelayDistribution int
+
+const (
+	// DelayFixed delays every frame by DelayConfig.Delay.
+	DelayFixed DelayDistribution = iota
+
+	// DelayUniform draws delays uniformly from the range from
+	// DelayConfig.Delay to DelayConfig.Delay+DelayConfig.Jitter.
+	DelayUniform
+
+	// DelayNormal draws delays from the normal distribution with the mean of
+	// DelayConfig.Delay and the standard deviation of DelayConfig.Jitter.
+	// Negative delays are treated as zero.
+	DelayNormal
+)
+
+// DelayConfig configures a DelayWriter.
+type DelayConfig struct {
+	Distribution DelayDistribution
+	Delay        time.Duration
+	Jitter       time.Duration
+
+	// MaxReorder is the maximum number of frames written after a frame that
+	// may be delivered before it. Zero keeps frames in order, so that a frame
+	// delayed more than the next ones delays them too.
+	MaxReorder int
+
+	// Seed seeds the drawn delays, so that a simulation can be repeated.
+	Seed int64
+}
+
+// DelayWriter is a transport decorator delaying frames written to an
+// underlying writer, to simulate latency, jitter and reordering of realistic
+// radio links. Every call to Write is treated as a single frame.
+//
+// DelayWriter is safe for concurrent use.
+type DelayWriter struct {
+	w      io.Writer
+	config DelayConfig
+
+	mu      sync.Mutex
+	rand    *rand.Rand
+	queue   delayQueue
+	seq     int
+	recent  []time.Time // delivery times of the last MaxReorder+1 frames
+	err     error
+	closing bool
+	wake    chan struct{}
+	done    chan struct{}
+}
+
+// NewDelayWriter creates a DelayWriter writing delayed frames to w. Close must
+// be called to release its resources. Delay, Jitter and MaxReorder must not be
+// negative, otherwise it returns ErrDelayConfig.
+func NewDelayWriter(w io.Writer, config DelayConfig) (*DelayWriter, error) {
+	if config.Delay < 0 || config.Jitter < 0 || config.MaxReorder < 0 {
+		return nil, ErrDelayConfig
+	}
+
+	d := &DelayWriter{
+		w:      w,
+		config: config,
+		rand:   rand.New(rand.NewSource(config.Seed)),
+		recent: make([]time.Time, config.MaxReorder+1),
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go d.deliver()
+
+	return d, nil
+}
+
+// Write schedules frame to be written to the underlying writer after a drawn
+// delay and returns immediately. It returns the first error returned by the
+// underlying writer so far, if any.
+func (d *DelayWriter) Write(frame []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return 0, d.err
+	}
+	if d.closing {
+		return 0, io.ErrClosedPipe
+	}
+
+	at := time.Now().Add(d.delay())
+
+	// the frame written MaxReorder+1 frames earlier must be delivered first
+	slot := d.seq % len(d.recent)
+	if at.Before(d.recent[slot]) {
+		at = d.recent[slot]
+	}
+	d.recent[slot] = at
+
+	heap.Push(&d.queue, delayedFrame{data: append([]byte{}, frame...), at: at, seq: d.seq})
+	d.seq++
+	d.notify()
+
+	return len(frame), nil
+}
+
+// Close waits until all scheduled frames are written. It does not close the
+// underlying writer. It returns the first error returned by the underlying
+// writer, if any.
+func (d *DelayWriter) Close() error {
+	d.mu.Lock()
+	if !d.closing {
+		d.closing = true
+		d.notify()
+	}
+	d.mu.Unlock()
+
+	<-d.done
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+// delay draws the delay of the next frame.
+func (d *DelayWriter) delay() time.Duration {
+	switch d.config.Distribution {
+	case DelayUniform:
+		return d.config.Delay + time.Duration(d.rand.Int63n(int64(d.config.Jitter)+1))
+	case DelayNormal:
+		delay := d.config.Delay + time.Duration(d.rand.NormFloat64()*float64(d.config.Jitter))
+		if delay < 0 {
+			return 0
+		}
+		return delay
+	default:
+		return d.config.Delay
+	}
+}
+
+// notify wakes up deliver. d.mu must be held.
+func (d *DelayWriter) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver writes scheduled frames to the underlying writer when their time
+// comes.
+func (d *DelayWriter) deliver() {
+	defer close(d.done)
+
+	for {
+		d.mu.Lock()
+		if len(d.queue) == 0 {
+			closing := d.closing
+			d.mu.Unlock()
+			if closing {
+				return
+			}
+
+			<-d.wake
+			continue
+		}
+
+		next := d.queue[0]
+		if wait := time.Until(next.at); wait > 0 {
+			d.mu.Unlock()
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-d.wake:
+				timer.Stop()
+			}
+			continue
+		}
+
+		heap.Pop(&d.queue)
+		d.mu.Unlock()
+
+		_, err := d.w.Write(next.data)
+
+		d.mu.Lock()
+		if err != nil && d.err == nil {
+			d.err = err
+		}
+		d.mu.Unlock()
+	}
+}
+
+// delayedFrame is a frame scheduled by a DelayWriter.
+type delayedFrame struct {
+	data []byte
+	at   time.Time
+	seq  int
+}
+
+// delayQueue is a heap of delayed frames ordered by their delivery time, then
+// by the order in which they were written.
+type delayQueue []delayedFrame
+
+func (q delayQueue) Len() int { return len(q) }
+
+func (q delayQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+
+func (q delayQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *delayQueue) Push(x interface{}) { *q = append(*q, x.(delayedFrame)) }
+
+func (q *delayQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,121 @@
+package frames_test
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+// deliveryRecorder records the first byte of every written frame and the time
+// it was written at.
+type deliveryRecorder struct {
+	mu    sync.Mutex
+	seqs  []byte
+	times []time.Time
+}
+
+func (r *deliveryRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seqs = append(r.seqs, p[0])
+	r.times = append(r.times, time.Now())
+	return len(p), nil
+}
+
+func TestDelayWriterFixed(t *testing.T) {
+	var r deliveryRecorder
+	d, err := frames.NewDelayWriter(&r, frames.DelayConfig{Delay: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := d.Write([]byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, seq := range r.seqs {
+		if seq != byte(i) {
+			t.Errorf("got frame %d at position %d, want frame %d", seq, i, i)
+		}
+		if elapsed := r.times[i].Sub(start); elapsed < 20*time.Millisecond {
+			t.Errorf("got frame %d after %v, want it after at least 20ms", seq, elapsed)
+		}
+	}
+}
+
+func TestDelayWriterReorder(t *testing.T) {
+	for _, tc := range []struct {
+		distribution frames.DelayDistribution
+		maxReorder   int
+	}{
+		{frames.DelayUniform, 0},
+		{frames.DelayUniform, 2},
+		{frames.DelayNormal, 3},
+	} {
+		var r deliveryRecorder
+		d, err := frames.NewDelayWriter(&r, frames.DelayConfig{
+			Distribution: tc.distribution,
+			Delay:        5 * time.Millisecond,
+			Jitter:       5 * time.Millisecond,
+			MaxReorder:   tc.maxReorder,
+			Seed:         1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		const n = 50
+		for i := 0; i < n; i++ {
+			if _, err := d.Write([]byte{byte(i)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := d.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(r.seqs) != n {
+			t.Fatalf("got %d frames, want %d", len(r.seqs), n)
+		}
+
+		position := make([]int, n)
+		reordered := false
+		for i, seq := range r.seqs {
+			position[seq] = i
+			reordered = reordered || int(seq) != i
+		}
+
+		for seq := 0; seq+tc.maxReorder+1 < n; seq++ {
+			if later := seq + tc.maxReorder + 1; position[later] < position[seq] {
+				t.Errorf("distribution %d, max reorder %d: got frame %d before frame %d", tc.distribution, tc.maxReorder, later, seq)
+			}
+		}
+
+		if tc.maxReorder > 0 && !reordered {
+			t.Errorf("distribution %d, max reorder %d: got no reordering", tc.distribution, tc.maxReorder)
+		}
+	}
+}
+
+func TestNewDelayWriterInvalid(t *testing.T) {
+	for _, config := range []frames.DelayConfig{
+		{Delay: -time.Millisecond},
+		{Distribution: frames.DelayUniform, Jitter: -time.Second},
+		{MaxReorder: -1},
+	} {
+		if _, err := frames.NewDelayWriter(io.Discard, config); !errors.Is(err, frames.ErrDelayConfig) {
+			t.Errorf("config %+v: got error %v, want error %v", config, err, frames.ErrDelayConfig)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package frames
+
+import (
+	"errors"
+	"math"
+)
+
+// DeltaOverhead is the number of bytes delta encoding adds to frames' data.
+const DeltaOverhead = 2
+
+// Kinds of delta-encoded frames, the first data byte.
+const (
+	deltaKeyframe = 0x00
+	deltaUpdate   = 0x01
+)
+
+// deltaMinGap is the length of the shortest run of unchanged bytes splitting
+// changed bytes in a delta. Shorter runs are sent as changed bytes, which is
+// cheaper.
+const deltaMinGap = 3
+
+// ErrDeltaBase is returned by DeltaDecoder when a delta does not apply to the
+// previous frame, e.g. because a frame was lost. Frames with the header cannot
+// be decoded until the next keyframe.
+var ErrDeltaBase = errors.New("frames: delta base frame missing")
+
+// ErrDeltaFormat is returned by DeltaDecoder for malformed delta-encoded data.
+var ErrDeltaFormat = errors.New("frames: invalid delta")
+
+// deltaState is the state of delta encoding or decoding for a header.
+type deltaState struct {
+	data  []byte // data of the previous frame
+	seq   byte   // sequence number of the previous frame
+	count int    // frames since the last keyframe
+}
+
+// DeltaEncoder encodes frames as differences against the previous frame of the
+// same header, cutting bandwidth for slowly changing data like IMU readings.
+// Encoded frames have the same header, and their data starts with a kind byte
+// and a sequence number, followed by either the whole data (a keyframe) or
+// the bytes that changed, XOR-ed with the previous ones (a delta). A
+// DeltaEncoder must not be used concurrently.
+type DeltaEncoder struct {
+	interval int
+	states   map[[2]byte]*deltaState
+}
+
+// NewDeltaEncoder returns a DeltaEncoder sending every interval-th frame of a
+// header as a keyframe, so that a receiver recovers from lost frames. Zero
+// means keyframes are sent only when required: first for each header, when
+// data length changes, or when a delta would not be shorter.
+func NewDeltaEncoder(interval int) *DeltaEncoder {
+	return &DeltaEncoder{interval: interval, states: make(map[[2]byte]*deltaState)}
+}
+
+// Encode returns frame, which must be valid, delta-encoded. If its data is
+// longer than 255 - DeltaOverhead bytes, it returns ErrDataLength.
+func (e *DeltaEncoder) Encode(frame Frame) (Frame, error) {
+	data := frame.Data()
+	if len(data) > math.MaxUint8-DeltaOverhead {
+		return nil, ErrDataLength
+	}
+
+	var header [2]byte
+	copy(header[:], frame.Header())
+
+	state, ok := e.states[header]
+	if !ok {
+		state = &deltaState{}
+		e.states[header] = state
+	}
+
+	keyframe := !ok || len(data) != len(state.data) || e.interval > 0 && state.count+1 >= e.interval
+	var delta []byte
+	if !keyframe {
+		delta = encodeDelta(state.data, data)
+		keyframe = len(delta) >= len(data)
+	}
+
+	state.seq++
+	state.data = append(state.data[:0], data...)
+	if keyframe {
+		state.count = 0
+		return CreateFromSegments(header, []byte{deltaKeyframe, state.seq}, data), nil
+	}
+	state.count++
+
+	return CreateFromSegments(header, []byte{deltaUpdate, state.seq}, delta), nil
+}
+
+// encodeDelta returns the changes from prev to data, which have equal lengths,
+// as a sequence of runs: the number of unchanged bytes, the number of changed
+// bytes and the changed bytes XOR-ed with prev. Trailing unchanged bytes are
+// omitted.
+func encodeDelta(prev, data []byte) []byte {
+	var delta []byte
+	for i := 0; i < len(data); {
+		unchanged := 0
+		for i+unchanged < len(data) && data[i+unchanged] == prev[i+unchanged] {
+			unchanged++
+		}
+		if i+unchanged == len(data) {
+			break
+		}
+		i += unchanged
+
+		end := i
+		for end < len(data) {
+			if data[end] != prev[end] {
+				end++
+				continue
+			}
+
+			gap := 0
+			for end+gap < len(data) && data[end+gap] == prev[end+gap] {
+				gap++
+			}
+			if gap >= deltaMinGap || end+gap == len(data) {
+				break
+			}
+			end += gap
+		}
+
+		delta = append(delta, byte(unchanged), byte(end-i))
+		for ; i < end; i++ {
+			delta = append(delta, data[i]^prev[i])
+		}
+	}
+
+	return delta
+}
+
+// DeltaDecoder decodes frames encoded by DeltaEncoder. A DeltaDecoder must not
+// be used concurrently.
+type DeltaDecoder struct {
+	states map[[2]byte]*deltaState
+}
+
+// NewDeltaDecoder returns a DeltaDecoder.
+func NewDeltaDecoder() *DeltaDecoder {
+	return &DeltaDecoder{states: make(map[[2]byte]*deltaState)}
+}
+
+// Decode returns the original frame of delta-encoded frame. If the frame is
+// invalid, it returns a *VerifyError, see Validate. If it is a delta which
+// does not follow the previous frame of its header, it returns ErrDeltaBase.
+func (d *DeltaDecoder) Decode(frame Frame) (Frame, error) {
+	if err := Validate(frame); err != nil {
+		return nil, err
+	}
+
+	data := frame.Data()
+	if len(data) < DeltaOverhead {
+		return nil, ErrDeltaFormat
+	}
+
+	var header [2]byte
+	copy(header[:], frame.Header())
+
+	state, ok := d.states[header]
+	switch data[0] {
+	case deltaKeyframe:
+		if !ok {
+			state = &deltaState{}
+			d.states[header] = state
+		}
+		state.data = append(state.data[:0], data[DeltaOverhead:]...)
+	case deltaUpdate:
+		if !ok || state.data == nil || data[1] != state.seq+1 {
+			return nil, ErrDeltaBase
+		}
+		if err := applyDelta(state.data, data[DeltaOverhead:]); err != nil {
+			state.data = nil
+			return nil, err
+		}
+	default:
+		return nil, ErrDeltaFormat
+	}
+	state.seq = data[1]
+
+	return Create(header, state.data), nil
+}
+
+// applyDelta applies delta encoded by encodeDelta to data in place.
+func applyDelta(data, delta []byte) error {
+	i := 0
+	for len(delta) > 0 {
+		if len(delta) < 2 {
+			return ErrDeltaFormat
+		}
+
+		i += int(delta[0])
+		changed := int(delta[1])
+		if i+changed > len(data) || 2+changed > len(delta) {
+			return ErrDeltaFormat
+		}
+		for k := 0; k < changed; k++ {
+			data[i+k] ^= delta[2+k]
+		}
+
+		i += changed
+		delta = delta[2+changed:]
+	}
+
+	return nil
+}
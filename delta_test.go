@@ -0,0 +1,108 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestDelta(t *testing.T) {
+	encoder := frames.NewDeltaEncoder(4)
+	decoder := frames.NewDeltaDecoder()
+
+	inputs := []frames.Frame{
+		frames.Create([2]byte{'I', 'M'}, []byte{0x10, 0x20, 0x30, 0x40, 0x50, 0x60, 0x70, 0x80, 0x90, 0xa0, 0xb0, 0xc0}),
+		frames.Create([2]byte{'I', 'M'}, []byte{0x10, 0x21, 0x30, 0x40, 0x50, 0x60, 0x70, 0x80, 0x90, 0xa0, 0xb0, 0xc0}),
+		frames.Create([2]byte{'T', 'P'}, []byte{0x01}),
+		frames.Create([2]byte{'I', 'M'}, []byte{0x10, 0x22, 0x30, 0x41, 0x50, 0x60, 0x70, 0x81, 0x90, 0xa0, 0xb0, 0xc0}),
+		frames.Create([2]byte{'I', 'M'}, []byte{0x10, 0x22, 0x30, 0x41, 0x50, 0x60, 0x70, 0x81, 0x90, 0xa0, 0xb0, 0xc0}),
+		frames.Create([2]byte{'I', 'M'}, []byte{0x11, 0x22, 0x30, 0x41, 0x50, 0x60, 0x70, 0x81, 0x90, 0xa0, 0xb0, 0xc0}),
+		frames.Create([2]byte{'I', 'M'}, []byte{0x11, 0x22}),
+		frames.Create([2]byte{'I', 'M'}, []byte{0xff, 0xff}),
+	}
+	wantData := [][]byte{
+		{0x00, 0x01, 0x10, 0x20, 0x30, 0x40, 0x50, 0x60, 0x70, 0x80, 0x90, 0xa0, 0xb0, 0xc0},
+		{0x01, 0x02, 0x01, 0x01, 0x01},
+		{0x00, 0x01, 0x01},
+		{0x01, 0x03, 0x01, 0x03, 0x03, 0x00, 0x01, 0x03, 0x01, 0x01},
+		{0x01, 0x04},
+		{0x00, 0x05, 0x11, 0x22, 0x30, 0x41, 0x50, 0x60, 0x70, 0x81, 0x90, 0xa0, 0xb0, 0xc0}, // 4th frame of the header
+		{0x00, 0x06, 0x11, 0x22}, // length changed
+		{0x00, 0x07, 0xff, 0xff}, // delta not shorter
+	}
+
+	for i, input := range inputs {
+		encoded, err := encoder.Encode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(encoded.Header(), input.Header()) || !bytes.Equal(encoded.Data(), wantData[i]) {
+			t.Errorf("frame %d: got %s+% x, want %s+% x", i, encoded.Header(), encoded.Data(), input.Header(), wantData[i])
+		}
+
+		decoded, err := decoder.Decode(encoded)
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Errorf("frame %d: got % x, want % x", i, []byte(decoded), []byte(input))
+		}
+	}
+}
+
+func TestDeltaLost(t *testing.T) {
+	encoder := frames.NewDeltaEncoder(3)
+	decoder := frames.NewDeltaDecoder()
+
+	var encoded []frames.Frame
+	for i := 0; i < 6; i++ {
+		frame, err := encoder.Encode(frames.Create([2]byte{'I', 'M'}, []byte{0x00, 0x00, 0x00, byte(i)}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded = append(encoded, frame)
+	}
+
+	if _, err := decoder.Decode(encoded[1]); err != frames.ErrDeltaBase {
+		t.Errorf("got error %v, want %v", err, frames.ErrDeltaBase)
+	}
+
+	// frame 1 is lost
+	for _, tt := range []struct {
+		i   int
+		err error
+	}{{0, nil}, {2, frames.ErrDeltaBase}, {3, nil}, {4, nil}, {5, nil}} {
+		decoded, err := decoder.Decode(encoded[tt.i])
+		if err != tt.err {
+			t.Errorf("frame %d: got error %v, want %v", tt.i, err, tt.err)
+		}
+		if err == nil && decoded.Data()[3] != byte(tt.i) {
+			t.Errorf("frame %d: got data % x", tt.i, decoded.Data())
+		}
+	}
+}
+
+func TestDeltaErrors(t *testing.T) {
+	if _, err := frames.NewDeltaEncoder(0).Encode(frames.Create([2]byte{'I', 'M'}, make([]byte, 254))); err != frames.ErrDataLength {
+		t.Errorf("got error %v, want %v", err, frames.ErrDataLength)
+	}
+
+	decoder := frames.NewDeltaDecoder()
+	for _, data := range [][]byte{{0x00}, {0x02, 0x02}, {0x01, 0x02, 0x01}, {0x01, 0x02, 0x01, 0x02, 0xff}} {
+		if _, err := decoder.Decode(frames.Create([2]byte{'I', 'M'}, []byte{0x00, 0x01, 0x00, 0x00})); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := decoder.Decode(frames.Create([2]byte{'I', 'M'}, data)); err != frames.ErrDeltaFormat {
+			t.Errorf("data % x: got error %v, want %v", data, err, frames.ErrDeltaFormat)
+		}
+	}
+
+	frame := frames.Create([2]byte{'I', 'M'}, []byte{0x00, 0x01})
+	frame[len(frame)-1] ^= 0xff
+	var verifyErr *frames.VerifyError
+	if _, err := decoder.Decode(frame); !errors.As(err, &verifyErr) {
+		t.Errorf("got error %v, want *VerifyError", err)
+	}
+}
@@ -0,0 +1,305 @@
+package frames
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MaxDictionaryEntries is the maximum number of entries of a Dictionary.
+const MaxDictionaryEntries = 255
+
+// dictionaryLiterals is the code starting a run of literal bytes in data
+// compressed with a Dictionary. Other codes are indices of entries.
+const dictionaryLiterals = 0xff
+
+// trainMaxEntryLength is the length of the longest entries chosen by
+// TrainDictionary.
+const trainMaxEntryLength = 16
+
+// Errors returned by Dictionary.
+var (
+	ErrDictionary     = errors.New("frames: invalid dictionary")
+	ErrCompressedData = errors.New("frames: invalid compressed data")
+)
+
+// Dictionary compresses tiny data, e.g. single frames' data, far too small for
+// general-purpose compression, by replacing byte sequences from a dictionary
+// agreed on by both sides with their indices. Each entry occurrence takes a
+// single byte, and each run of other bytes takes 2 bytes more than the run.
+// A dictionary can be trained from captures with TrainDictionary.
+type Dictionary struct {
+	entries [][]byte
+
+	// byFirst lists indices of entries starting with a byte, longest first
+	byFirst [256][]int
+}
+
+// NewDictionary returns a dictionary of entries, which must be non-empty and
+// at most 255 bytes long. There must be at most MaxDictionaryEntries entries.
+func NewDictionary(entries [][]byte) (*Dictionary, error) {
+	if len(entries) > MaxDictionaryEntries {
+		return nil, ErrDictionary
+	}
+
+	d := &Dictionary{entries: make([][]byte, len(entries))}
+	for i, entry := range entries {
+		if len(entry) == 0 || len(entry) > math.MaxUint8 {
+			return nil, ErrDictionary
+		}
+		d.entries[i] = append([]byte{}, entry...)
+		d.byFirst[entry[0]] = append(d.byFirst[entry[0]], i)
+	}
+
+	for _, indices := range d.byFirst {
+		sort.SliceStable(indices, func(i, j int) bool {
+			return len(d.entries[indices[i]]) > len(d.entries[indices[j]])
+		})
+	}
+
+	return d, nil
+}
+
+// Entries returns the entries of d. They must not be modified.
+func (d *Dictionary) Entries() [][]byte {
+	return d.entries
+}
+
+// Compress returns data compressed with d. At every position, the longest
+// matching entry is used.
+func (d *Dictionary) Compress(data []byte) []byte {
+	compressed := make([]byte, 0, len(data))
+	literals := 0 // number of pending literal bytes preceding i
+
+	flush := func(i int) {
+		for literals > 0 {
+			n := literals
+			if n > math.MaxUint8 {
+				n = math.MaxUint8
+			}
+			compressed = append(compressed, dictionaryLiterals, byte(n))
+			compressed = append(compressed, data[i-literals:i-literals+n]...)
+			literals -= n
+		}
+	}
+
+	for i := 0; i < len(data); {
+		index := d.match(data[i:])
+		if index < 0 {
+			literals++
+			i++
+			continue
+		}
+
+		flush(i)
+		compressed = append(compressed, byte(index))
+		i += len(d.entries[index])
+	}
+	flush(len(data))
+
+	return compressed
+}
+
+// match returns the index of the longest entry data starts with, or -1.
+func (d *Dictionary) match(data []byte) int {
+	for _, index := range d.byFirst[data[0]] {
+		if bytes.HasPrefix(data, d.entries[index]) {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// Decompress returns data compressed with d. If data refers to unknown entries
+// or ends within a run of literal bytes, it returns ErrCompressedData.
+func (d *Dictionary) Decompress(data []byte) ([]byte, error) {
+	var decompressed []byte
+	for i := 0; i < len(data); {
+		code := data[i]
+		i++
+
+		if code != dictionaryLiterals {
+			if int(code) >= len(d.entries) {
+				return nil, ErrCompressedData
+			}
+			decompressed = append(decompressed, d.entries[code]...)
+			continue
+		}
+
+		if i >= len(data) || data[i] == 0 || i+1+int(data[i]) > len(data) {
+			return nil, ErrCompressedData
+		}
+		decompressed = append(decompressed, data[i+1:i+1+int(data[i])]...)
+		i += 1 + int(data[i])
+	}
+
+	return decompressed, nil
+}
+
+// Create creates a new frame whose data is data compressed with d. If the
+// compressed data does not fit in the frame, it returns ErrDataLength.
+func (d *Dictionary) Create(header [2]byte, data []byte) (Frame, error) {
+	compressed := d.Compress(data)
+	if len(compressed) > math.MaxUint8 {
+		return nil, ErrDataLength
+	}
+
+	return Create(header, compressed), nil
+}
+
+// Decode returns the decompressed data of frame created with Create. If the
+// frame is invalid, it returns a *VerifyError, see Validate.
+func (d *Dictionary) Decode(frame Frame) ([]byte, error) {
+	if err := Validate(frame); err != nil {
+		return nil, err
+	}
+
+	return d.Decompress(frame.Data())
+}
+
+// WriteTo writes the entries of d to w, one per line in hex. It implements
+// io.WriterTo.
+func (d *Dictionary) WriteTo(w io.Writer) (n int64, err error) {
+	bw := bufio.NewWriter(w)
+	for _, entry := range d.entries {
+		m, err := fmt.Fprintf(bw, "%x\n", entry)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, bw.Flush()
+}
+
+// ReadDictionary reads a dictionary in the format written by
+// Dictionary.WriteTo. Empty lines are skipped.
+func ReadDictionary(r io.Reader) (*Dictionary, error) {
+	var entries [][]byte
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		entry, err := hex.DecodeString(line)
+		if err != nil {
+			return nil, ErrDictionary
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewDictionary(entries)
+}
+
+// TrainDictionary returns a dictionary of at most n entries, n not exceeding
+// MaxDictionaryEntries, which compresses samples well, e.g. data of captured
+// frames. Entries are chosen one by one: each is the sequence of 2 to 16 bytes
+// saving the most bytes in samples, not counting occurrences already replaced
+// with previous entries. Occurrences are counted without overlaps, as Compress
+// replaces them.
+//
+// Occurrences are counted once and recounted only in samples containing the
+// chosen entries, but every entry is still chosen among all distinct
+// sequences, up to 15 times the total length of samples. Training thus takes
+// time proportional to n times the total length of samples, over a minute for
+// 2 megabytes of varied data and 255 entries, and memory proportional to the
+// total length.
+func TrainDictionary(samples [][]byte, n int) (*Dictionary, error) {
+	if n < 0 || n > MaxDictionaryEntries {
+		return nil, ErrDictionary
+	}
+
+	counts := make(map[string]int)
+	for _, sample := range samples {
+		countSequences(counts, sample, 1)
+	}
+
+	var entries [][]byte
+	segments := samples
+	for len(entries) < n {
+		entry := mostSaving(counts)
+		if entry == nil {
+			break
+		}
+		entries = append(entries, entry)
+
+		var split [][]byte
+		for _, segment := range segments {
+			if !bytes.Contains(segment, entry) {
+				split = append(split, segment)
+				continue
+			}
+
+			countSequences(counts, segment, -1)
+			for _, part := range bytes.Split(segment, entry) {
+				if len(part) > 1 {
+					split = append(split, part)
+					countSequences(counts, part, 1)
+				}
+			}
+		}
+		segments = split
+	}
+
+	return NewDictionary(entries)
+}
+
+// countSequences adds delta to counts of sequences of 2 to trainMaxEntryLength
+// bytes for each of their non-overlapping occurrences in segment, found from
+// left to right. Sequences whose count drops to zero are removed.
+func countSequences(counts map[string]int, segment []byte, delta int) {
+	// end of the last counted occurrence of each sequence
+	end := make(map[string]int)
+	for i := range segment {
+		for length := 2; length <= trainMaxEntryLength && i+length <= len(segment); length++ {
+			seq := string(segment[i : i+length])
+			if i < end[seq] {
+				continue
+			}
+			end[seq] = i + length
+
+			counts[seq] += delta
+			if counts[seq] == 0 {
+				delete(counts, seq)
+			}
+		}
+	}
+}
+
+// mostSaving returns the sequence occurring at least twice according to counts
+// whose replacement with a single byte saves the most bytes, or nil if there is
+// none. Ties are broken by preferring longer sequences, then lexicographically
+// smaller ones.
+func mostSaving(counts map[string]int) []byte {
+	best, bestSavings := "", 0
+	for seq, count := range counts {
+		if count < 2 {
+			continue
+		}
+
+		savings := count * (len(seq) - 1)
+		if savings > bestSavings ||
+			savings == bestSavings && (len(seq) > len(best) || len(seq) == len(best) && seq < best) {
+			best, bestSavings = seq, savings
+		}
+	}
+
+	if bestSavings == 0 {
+		return nil
+	}
+
+	return []byte(best)
+}
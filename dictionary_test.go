@@ -0,0 +1,145 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestDictionary(t *testing.T) {
+	dict, err := frames.NewDictionary([][]byte{[]byte("mode="), []byte("scan"), []byte("sc"), []byte("&")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		data       string
+		compressed []byte
+	}{
+		{"", []byte{}},
+		{"mode=scan", []byte{0x00, 0x01}},
+		{"mode=scout", []byte{0x00, 0x02, 0xff, 0x03, 'o', 'u', 't'}},
+		{"x&mode=scan", []byte{0xff, 0x01, 'x', 0x03, 0x00, 0x01}},
+		{"\xff", []byte{0xff, 0x01, 0xff}},
+	}
+
+	for _, tt := range tests {
+		compressed := dict.Compress([]byte(tt.data))
+		if !bytes.Equal(compressed, tt.compressed) {
+			t.Errorf("%q: got % x, want % x", tt.data, compressed, tt.compressed)
+		}
+
+		data, err := dict.Decompress(compressed)
+		if err != nil || string(data) != tt.data {
+			t.Errorf("got %q, %v, want %q, nil", data, err, tt.data)
+		}
+	}
+
+	long := bytes.Repeat([]byte{'x'}, 300)
+	if got, err := dict.Decompress(dict.Compress(long)); err != nil || !bytes.Equal(got, long) {
+		t.Errorf("got %d bytes, %v, want %d bytes, nil", len(got), err, len(long))
+	}
+}
+
+func TestDictionaryErrors(t *testing.T) {
+	if _, err := frames.NewDictionary([][]byte{{}}); err != frames.ErrDictionary {
+		t.Errorf("got error %v, want %v", err, frames.ErrDictionary)
+	}
+	if _, err := frames.NewDictionary(make([][]byte, 256)); err != frames.ErrDictionary {
+		t.Errorf("got error %v, want %v", err, frames.ErrDictionary)
+	}
+
+	dict, err := frames.NewDictionary([][]byte{[]byte("ab")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range [][]byte{{0x01}, {0xff}, {0xff, 0x00}, {0xff, 0x02, 'x'}} {
+		if _, err := dict.Decompress(data); err != frames.ErrCompressedData {
+			t.Errorf("data % x: got error %v, want %v", data, err, frames.ErrCompressedData)
+		}
+	}
+
+	if _, err := dict.Create([2]byte{'C', 'M'}, make([]byte, 255)); err != frames.ErrDataLength {
+		t.Errorf("got error %v, want %v", err, frames.ErrDataLength)
+	}
+
+	frame, err := dict.Create([2]byte{'C', 'M'}, []byte("abab"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := dict.Decode(frame); err != nil || string(got) != "abab" {
+		t.Errorf("got %q, %v, want %q, nil", got, err, "abab")
+	}
+	frame[len(frame)-1] ^= 0xff
+	var verifyErr *frames.VerifyError
+	if _, err := dict.Decode(frame); !errors.As(err, &verifyErr) {
+		t.Errorf("got error %v, want *VerifyError", err)
+	}
+}
+
+func TestReadDictionary(t *testing.T) {
+	dict, err := frames.NewDictionary([][]byte{[]byte("mode="), {0x00, 0xff}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	if _, err := dict.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "6d6f64653d\n00ff\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	read, err := frames.ReadDictionary(strings.NewReader(buf.String() + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Entries()) != 2 || !bytes.Equal(read.Entries()[1], []byte{0x00, 0xff}) {
+		t.Errorf("got entries %q, want %q", read.Entries(), dict.Entries())
+	}
+
+	if _, err := frames.ReadDictionary(strings.NewReader("xyz\n")); err != frames.ErrDictionary {
+		t.Errorf("got error %v, want %v", err, frames.ErrDictionary)
+	}
+}
+
+func TestTrainDictionary(t *testing.T) {
+	var samples [][]byte
+	for _, mode := range []string{"scan", "idle", "scan", "calibrate", "scan"} {
+		samples = append(samples, []byte("status=ok&mode="+mode))
+	}
+
+	dict, err := frames.TrainDictionary(samples, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(dict.Entries()[0]), "status=ok&mode="; got != want {
+		t.Errorf("got first entry %q, want %q", got, want)
+	}
+
+	original, compressed := 0, 0
+	for _, sample := range samples {
+		original += len(sample)
+		compressed += len(dict.Compress(sample))
+	}
+	if compressed*10 > original*3 {
+		t.Errorf("got %d bytes compressed from %d, want at most 30%%", compressed, original)
+	}
+
+	// "aaa" occurs 3 times in each sample only when overlapping
+	dict, err = frames.TrainDictionary([][]byte{[]byte("aaaaa"), []byte("aaaaa")}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(dict.Entries()[0]), "aaaaa"; got != want {
+		t.Errorf("got first entry %q, want %q", got, want)
+	}
+
+	if _, err := frames.TrainDictionary(samples, 256); err != frames.ErrDictionary {
+		t.Errorf("got error %v, want %v", err, frames.ErrDictionary)
+	}
+}
@@ -0,0 +1,121 @@
+package frames
+
+import "fmt"
+
+// Region identifies a part of a frame.
+type Region int
+
+// Regions of a frame, in the order they appear in it.
+const (
+	RegionHeader Region = iota
+	RegionLength
+	RegionPlus
+	RegionData
+	RegionHash
+	RegionChecksum
+)
+
+func (r Region) String() string {
+	switch r {
+	case RegionHeader:
+		return "header"
+	case RegionLength:
+		return "length"
+	case RegionPlus:
+		return "plus"
+	case RegionData:
+		return "data"
+	case RegionHash:
+		return "hash"
+	case RegionChecksum:
+		return "checksum"
+	default:
+		return fmt.Sprintf("Region(%d)", int(r))
+	}
+}
+
+// Difference describes a single byte that differs between two frames.
+type Difference struct {
+	Region Region
+
+	// Offset is the offset of the byte within its region.
+	Offset int
+
+	// A and B are the values of the byte in the first and the second frame.
+	// If the byte is absent from a frame, its value is -1.
+	A, B int
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s offset %d: %s != %s", d.Region, d.Offset, diffByte(d.A), diffByte(d.B))
+}
+
+func diffByte(b int) string {
+	if b < 0 {
+		return "none"
+	}
+
+	return fmt.Sprintf("0x%02x", b)
+}
+
+// DiffFrames compares frames a and b region by region and returns all bytes
+// that differ. It returns nil if the frames are equal.
+//
+// Regions are determined by the frame's actual length, not by its length
+// byte, so that data of different length can be compared too. Frames shorter
+// than 6 bytes are split into regions from the start, and the remaining bytes
+// are treated as data.
+func DiffFrames(a, b Frame) (diffs []Difference) {
+	regionsA, regionsB := frameRegions(a), frameRegions(b)
+
+	for region := RegionHeader; region <= RegionChecksum; region++ {
+		ra, rb := regionsA[region], regionsB[region]
+
+		n := len(ra)
+		if len(rb) > n {
+			n = len(rb)
+		}
+
+		for i := 0; i < n; i++ {
+			va, vb := -1, -1
+			if i < len(ra) {
+				va = int(ra[i])
+			}
+			if i < len(rb) {
+				vb = int(rb[i])
+			}
+
+			if va != vb {
+				diffs = append(diffs, Difference{Region: region, Offset: i, A: va, B: vb})
+			}
+		}
+	}
+
+	return
+}
+
+// frameRegions splits frame into its regions, indexed by Region.
+func frameRegions(frame Frame) (regions [RegionChecksum + 1][]byte) {
+	if len(frame) >= minFrameLen {
+		regions[RegionHeader] = frame[:2]
+		regions[RegionLength] = frame[2:3]
+		regions[RegionPlus] = frame[3:4]
+		regions[RegionData] = frame[4 : len(frame)-2]
+		regions[RegionHash] = frame[len(frame)-2 : len(frame)-1]
+		regions[RegionChecksum] = frame[len(frame)-1:]
+		return
+	}
+
+	sizes := []int{2, 1, 1}
+	rest := frame
+	for region, size := range sizes {
+		if size > len(rest) {
+			size = len(rest)
+		}
+		regions[region] = rest[:size]
+		rest = rest[size:]
+	}
+	regions[RegionData] = rest
+
+	return
+}
@@ -0,0 +1,61 @@
+package frames_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestDiffFrames(t *testing.T) {
+	diffTestCases := []struct {
+		a, b  []byte
+		diffs []string
+	}{
+		// equal frames
+		{
+			a:     []byte{'L', 'D', 0x1, '+', 'A', '#', 0x40},
+			b:     []byte{'L', 'D', 0x1, '+', 'A', '#', 0x40},
+			diffs: nil,
+		},
+		// different header and checksum
+		{
+			a:     []byte{'L', 'D', 0x1, '+', 'A', '#', 0x40},
+			b:     []byte{'M', 'D', 0x1, '+', 'A', '#', 0x41},
+			diffs: []string{"header offset 0: 0x4c != 0x4d", "checksum offset 0: 0x40 != 0x41"},
+		},
+		// different data
+		{
+			a:     []byte{'L', 'D', 0x4, '+', 't', 'e', 's', 't', '#', 0x12},
+			b:     []byte{'L', 'D', 0x4, '+', 't', 'e', 'x', 't', '#', 0x12},
+			diffs: []string{"data offset 2: 0x73 != 0x78"},
+		},
+		// data of different length
+		{
+			a:     []byte{'L', 'D', 0x1, '+', 'A', '#', 0x40},
+			b:     []byte{'L', 'D', 0x2, '+', 'A', 'B', '#', 0x01},
+			diffs: []string{"length offset 0: 0x01 != 0x02", "data offset 1: none != 0x42", "checksum offset 0: 0x40 != 0x01"},
+		},
+		// too short frame
+		{
+			a:     []byte{'L', 'D', 0x0, '+', '#', 0x00},
+			b:     []byte{'L', 'D'},
+			diffs: []string{"length offset 0: 0x00 != none", "plus offset 0: 0x2b != none", "hash offset 0: 0x23 != none", "checksum offset 0: 0x00 != none"},
+		},
+	}
+
+	for i, tc := range diffTestCases {
+		testName := fmt.Sprintf("test %d", i)
+		t.Run(testName, func(t *testing.T) {
+			var got []string
+			for _, d := range frames.DiffFrames(tc.a, tc.b) {
+				got = append(got, d.String())
+			}
+
+			if !reflect.DeepEqual(got, tc.diffs) {
+				t.Errorf("got differences %q, want %q", got, tc.diffs)
+			}
+		})
+	}
+}
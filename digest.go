@@ -0,0 +1,83 @@
+package frames
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"sync"
+)
+
+// DigestHeader is the header of control frames carrying a stream digest.
+var DigestHeader = [2]byte{'D', 'G'}
+
+var (
+	// ErrDigestFormat is returned when a digest control frame is malformed.
+	ErrDigestFormat = errors.New("frames: malformed digest frame")
+
+	// ErrDigestMismatch is returned when the peer's digest differs from the
+	// local one, i.e. the streams have silently diverged.
+	ErrDigestMismatch = errors.New("frames: stream digest mismatch")
+)
+
+// StreamDigest is a running SHA-256 digest over the headers and data of all
+// frames of a stream, in order. Both ends of a link keep one and periodically
+// exchange it in control frames to detect silent divergence of long-lived
+// streams.
+//
+// StreamDigest is safe for concurrent use.
+type StreamDigest struct {
+	mu    sync.Mutex
+	h     hash.Hash
+	count uint64
+}
+
+// NewStreamDigest creates an empty StreamDigest.
+func NewStreamDigest() *StreamDigest {
+	return &StreamDigest{h: sha256.New()}
+}
+
+// Add adds frame to the digest. Digest control frames, i.e. frames with
+// DigestHeader, are ignored, so that all frames of a stream can be added.
+func (d *StreamDigest) Add(frame Frame) {
+	if bytes.Equal(frame.Header(), DigestHeader[:]) {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.h.Write(frame.Header())
+	d.h.Write([]byte{byte(len(frame.Data()))})
+	d.h.Write(frame.Data())
+	d.count++
+}
+
+// Frame returns a control frame with DigestHeader carrying the number of frames
+// added so far (8 bytes, big-endian) and their digest.
+func (d *StreamDigest) Frame() Frame {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	data := make([]byte, 8, 8+sha256.Size)
+	binary.BigEndian.PutUint64(data, d.count)
+	data = d.h.Sum(data)
+
+	return Create(DigestHeader, data)
+}
+
+// Check compares the digest carried by control, created by the peer's Frame,
+// with the local digest. It returns ErrDigestMismatch if the peer has seen a
+// different number of frames or different frames.
+func (d *StreamDigest) Check(control Frame) error {
+	if !Verify(control) || !bytes.Equal(control.Header(), DigestHeader[:]) || len(control.Data()) != 8+sha256.Size {
+		return ErrDigestFormat
+	}
+
+	if !bytes.Equal(control.Data(), d.Frame().Data()) {
+		return ErrDigestMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package frames_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestStreamDigest(t *testing.T) {
+	local, remote := frames.NewStreamDigest(), frames.NewStreamDigest()
+
+	for _, tc := range testCases {
+		local.Add(tc.frame)
+		remote.Add(tc.frame)
+	}
+
+	// the peer's control frame goes through the stream too
+	control := remote.Frame()
+	local.Add(control)
+
+	if err := local.Check(control); err != nil {
+		t.Fatal(err)
+	}
+
+	remote.Add(frames.Create([2]byte{'L', 'D'}, []byte("lost")))
+	if err := local.Check(remote.Frame()); !errors.Is(err, frames.ErrDigestMismatch) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrDigestMismatch)
+	}
+
+	local.Add(frames.Create([2]byte{'L', 'D'}, []byte("lust")))
+	if err := local.Check(remote.Frame()); !errors.Is(err, frames.ErrDigestMismatch) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrDigestMismatch)
+	}
+
+	if err := local.Check(frames.Create(frames.DigestHeader, []byte("short"))); !errors.Is(err, frames.ErrDigestFormat) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrDigestFormat)
+	}
+}
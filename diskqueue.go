@@ -0,0 +1,236 @@
+package frames
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// diskQueueHeaderSize is the size of the header at the start of a DiskQueue
+// file. The header holds the offset of the first pending record.
+const diskQueueHeaderSize = 8
+
+// ErrQueueEmpty is returned by DiskQueue.Peek when the queue is empty.
+var ErrQueueEmpty = errors.New("frames: queue empty")
+
+// DiskQueue is a persistent FIFO queue of frames stored in a single file, so
+// that frames waiting to be sent survive a restart of the process.
+//
+// The file starts with the offset of the first pending frame, followed by
+// frames, each prefixed with its length as 2 bytes, big-endian. Frames are
+// synced to disk before Push returns. When the queue becomes empty, the file is
+// truncated.
+//
+// DiskQueue is safe for concurrent use.
+type DiskQueue struct {
+	mu   sync.Mutex
+	file *os.File
+	head int64
+	end  int64
+	n    int
+}
+
+// OpenDiskQueue opens the queue stored in the file at path, creating the file
+// if it does not exist. A frame only partially written before a crash is
+// discarded.
+func OpenDiskQueue(path string) (*DiskQueue, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &DiskQueue{file: file, head: diskQueueHeaderSize}
+	if err := q.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// load reads the header and counts the complete frames following it.
+func (q *DiskQueue) load() error {
+	info, err := q.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.Size() < diskQueueHeaderSize {
+		return q.reset()
+	}
+
+	header := make([]byte, diskQueueHeaderSize)
+	if _, err := q.file.ReadAt(header, 0); err != nil {
+		return err
+	}
+	q.head = int64(binary.BigEndian.Uint64(header))
+	if q.head < diskQueueHeaderSize || q.head > info.Size() {
+		return q.reset()
+	}
+	q.end = q.head
+
+	length := make([]byte, 2)
+	for {
+		if _, err := q.file.ReadAt(length, q.end); err != nil {
+			break
+		}
+
+		next := q.end + 2 + int64(binary.BigEndian.Uint16(length))
+		if next > info.Size() {
+			break
+		}
+
+		q.end = next
+		q.n++
+	}
+
+	if q.end != info.Size() {
+		return q.file.Truncate(q.end)
+	}
+
+	return nil
+}
+
+// Push appends frame to the end of the queue.
+func (q *DiskQueue) Push(frame Frame) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	record := make([]byte, 2+len(frame))
+	binary.BigEndian.PutUint16(record, uint16(len(frame)))
+	copy(record[2:], frame)
+
+	if _, err := q.file.WriteAt(record, q.end); err != nil {
+		return err
+	}
+
+	if err := q.file.Sync(); err != nil {
+		return err
+	}
+
+	q.end += int64(len(record))
+	q.n++
+
+	return nil
+}
+
+// Peek returns the frame at the front of the queue without removing it. If the
+// queue is empty, it returns ErrQueueEmpty.
+func (q *DiskQueue) Peek() (Frame, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	frame, _, err := q.peek()
+	return frame, err
+}
+
+func (q *DiskQueue) peek() (Frame, int64, error) {
+	if q.n == 0 {
+		return nil, 0, ErrQueueEmpty
+	}
+
+	length := make([]byte, 2)
+	if _, err := q.file.ReadAt(length, q.head); err != nil {
+		return nil, 0, err
+	}
+
+	frame := make(Frame, binary.BigEndian.Uint16(length))
+	if _, err := q.file.ReadAt(frame, q.head+2); err != nil && err != io.EOF {
+		return nil, 0, err
+	}
+
+	return frame, q.head + 2 + int64(len(frame)), nil
+}
+
+// Pop removes the frame at the front of the queue. If the queue is empty, it
+// returns ErrQueueEmpty.
+func (q *DiskQueue) Pop() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	_, next, err := q.peek()
+	if err != nil {
+		return err
+	}
+
+	if q.n == 1 {
+		return q.reset()
+	}
+
+	if err := q.writeHead(next); err != nil {
+		return err
+	}
+	q.n--
+
+	return nil
+}
+
+// Flush passes queued frames to write, e.g. Session.WriteFrame, in order, and
+// removes every frame for which write succeeds. It stops at the first error
+// returned by write, leaving the frame that failed at the front of the queue.
+func (q *DiskQueue) Flush(write func(Frame) error) error {
+	for {
+		frame, err := q.Peek()
+		if err == ErrQueueEmpty {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := write(frame); err != nil {
+			return err
+		}
+
+		if err := q.Pop(); err != nil {
+			return err
+		}
+	}
+}
+
+// Len returns the number of frames in the queue.
+func (q *DiskQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.n
+}
+
+// Close closes the file backing the queue.
+func (q *DiskQueue) Close() error {
+	return q.file.Close()
+}
+
+// reset empties the queue and truncates the file.
+func (q *DiskQueue) reset() error {
+	if err := q.file.Truncate(diskQueueHeaderSize); err != nil {
+		return err
+	}
+
+	if err := q.writeHead(diskQueueHeaderSize); err != nil {
+		return err
+	}
+
+	q.end = diskQueueHeaderSize
+	q.n = 0
+
+	return nil
+}
+
+// writeHead stores the offset of the first pending frame in the header.
+func (q *DiskQueue) writeHead(head int64) error {
+	header := make([]byte, diskQueueHeaderSize)
+	binary.BigEndian.PutUint64(header, uint64(head))
+	if _, err := q.file.WriteAt(header, 0); err != nil {
+		return err
+	}
+
+	if err := q.file.Sync(); err != nil {
+		return err
+	}
+
+	q.head = head
+
+	return nil
+}
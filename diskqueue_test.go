@@ -0,0 +1,131 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestDiskQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue")
+
+	q, err := frames.OpenDiskQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range testCases {
+		if err := q.Push(tc.frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := q.Pop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the queue survives reopening
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	q, err = frames.OpenDiskQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	if q.Len() != len(testCases)-1 {
+		t.Fatalf("got %d frames in queue, want %d", q.Len(), len(testCases)-1)
+	}
+
+	var got []frames.Frame
+	err = q.Flush(func(frame frames.Frame) error {
+		got = append(got, frame)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range testCases[1:] {
+		if !bytes.Equal(got[i], tc.frame) {
+			t.Errorf("got frame % x, want frame % x", []byte(got[i]), tc.frame)
+		}
+	}
+
+	if _, err := q.Peek(); !errors.Is(err, frames.ErrQueueEmpty) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrQueueEmpty)
+	}
+}
+
+func TestDiskQueueFlushError(t *testing.T) {
+	q, err := frames.OpenDiskQueue(filepath.Join(t.TempDir(), "queue"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	for _, tc := range testCases[:2] {
+		if err := q.Push(tc.frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	errLinkDown := errors.New("link down")
+	err = q.Flush(func(frame frames.Frame) error {
+		return errLinkDown
+	})
+	if !errors.Is(err, errLinkDown) {
+		t.Errorf("got error %v, want error %v", err, errLinkDown)
+	}
+
+	if q.Len() != 2 {
+		t.Errorf("got %d frames in queue, want 2", q.Len())
+	}
+}
+
+func TestDiskQueuePartialWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue")
+
+	q, err := frames.OpenDiskQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Push(testCases[1].frame); err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+
+	// simulate a crash in the middle of writing the second frame
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0x00, 0x07, 'L', 'D'})
+	f.Close()
+
+	q, err = frames.OpenDiskQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	if q.Len() != 1 {
+		t.Fatalf("got %d frames in queue, want 1", q.Len())
+	}
+
+	frame, err := q.Peek()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(frame, testCases[1].frame) {
+		t.Errorf("got frame % x, want frame % x", []byte(frame), testCases[1].frame)
+	}
+}
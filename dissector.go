@@ -0,0 +1,290 @@
+package frames
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Errors returned by WriteDissector.
+var (
+	ErrDissectorLinkType = errors.New("frames: dissector link type is not a user-defined link type")
+	ErrDissectorCodec    = errors.New("frames: dissector does not support Hamming-coded headers or extensions")
+)
+
+// DissectorField describes a field of frames' data.
+type DissectorField struct {
+	// Name is shown in Wireshark's packet details. Lowercased, with characters
+	// other than letters and digits replaced by underscores, it is also a part
+	// of the field's name in display filters.
+	Name string
+
+	// Size is the field's size in bytes. Zero means the rest of the data.
+	Size int
+}
+
+// DissectorConfig configures WriteDissector.
+type DissectorConfig struct {
+	// Name is the protocol's name, also used as the prefix of fields in
+	// display filters, e.g. "frames.header". It defaults to "frames".
+	Name string
+
+	// Codec is the codec the frames are encoded with.
+	Codec Codec
+
+	// LinkType is the link type of the packets to dissect, from LinkTypeUser0
+	// to LinkTypeUser0+15, as passed to NewPCAPNGWriter.
+	LinkType uint16
+
+	// Fields maps headers, e.g. "LD", to the fields of the data of frames with
+	// that header. The data of other frames is shown as a whole.
+	Fields map[string][]DissectorField
+}
+
+// WriteDissector writes a Wireshark dissector, in Lua, decoding frames captured
+// with PCAPNGWriter. It shows every part of a frame, splits data into the
+// configured fields and marks frames with invalid checksums. To use it, copy
+// it to Wireshark's plugin directory.
+func WriteDissector(w io.Writer, config DissectorConfig) error {
+	if config.LinkType < LinkTypeUser0 || config.LinkType > LinkTypeUser0+15 {
+		return ErrDissectorLinkType
+	}
+	if config.Codec.HammingHeader || config.Codec.Extensions {
+		return ErrDissectorCodec
+	}
+
+	name := config.Name
+	if name == "" {
+		name = "frames"
+	}
+
+	c := config.Codec
+	data := dissectorData{
+		Name:           name,
+		Encap:          "USER" + strconv.Itoa(int(config.LinkType-LinkTypeUser0)),
+		PreambleLen:    len(c.Preamble),
+		LengthOffset:   c.lengthOffset(),
+		DataOffset:     c.dataOffset(),
+		SuffixLen:      c.suffixLen(),
+		ChecksumSize:   c.ChecksumAlgorithm.Size(),
+		XMODEM:         c.ChecksumAlgorithm == ChecksumXMODEM,
+		DataChecksum:   c.DataChecksum,
+		BeforeHash:     c.ChecksumBeforeHash,
+		CRLF:           c.CRLF,
+		HeaderChecksum: -1,
+		Complement:     -1,
+	}
+	if c.HeaderChecksum {
+		data.HeaderChecksum = c.headerChecksumOffset()
+	}
+	if c.LengthComplement {
+		data.Complement = c.lengthOffset() + 1
+	}
+
+	headers := make([]string, 0, len(config.Fields))
+	for header := range config.Fields {
+		headers = append(headers, header)
+	}
+	sort.Strings(headers)
+
+	for _, header := range headers {
+		layout := dissectorLayout{Header: header}
+		for _, field := range config.Fields[header] {
+			layout.Fields = append(layout.Fields, dissectorLayoutField{
+				Key:  "data_" + luaIdentifier(header) + "_" + luaIdentifier(field.Name),
+				Abbr: name + "." + luaIdentifier(header) + "." + luaIdentifier(field.Name),
+				Name: field.Name,
+				Size: field.Size,
+			})
+		}
+		data.Layouts = append(data.Layouts, layout)
+	}
+
+	return dissectorTemplate.Execute(w, data)
+}
+
+// luaIdentifier returns s lowercased, with characters other than letters and
+// digits replaced by underscores.
+func luaIdentifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// dissectorData is passed to dissectorTemplate. Offsets of optional parts are
+// -1 if the parts are absent.
+type dissectorData struct {
+	Name           string
+	Encap          string
+	PreambleLen    int
+	LengthOffset   int
+	Complement     int
+	HeaderChecksum int
+	DataOffset     int
+	SuffixLen      int
+	ChecksumSize   int
+	XMODEM         bool
+	DataChecksum   bool
+	BeforeHash     bool
+	CRLF           bool
+	Layouts        []dissectorLayout
+}
+
+// dissectorLayout describes the data of frames with a single header.
+type dissectorLayout struct {
+	Header string
+	Fields []dissectorLayoutField
+}
+
+// dissectorLayoutField describes a single field of data.
+type dissectorLayoutField struct {
+	Key  string
+	Abbr string
+	Name string
+	Size int
+}
+
+var dissectorTemplate = template.Must(template.New("dissector").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`-- Wireshark dissector for the {{.Name}} protocol, generated by
+-- github.com/knei-knurow/frames. Do not edit.
+
+local proto = Proto({{quote .Name}}, {{quote (print "Frames (" .Name ")")}})
+
+local f = proto.fields
+{{- if .PreambleLen}}
+f.preamble = ProtoField.bytes({{quote (print .Name ".preamble")}}, "Preamble")
+{{- end}}
+f.header = ProtoField.string({{quote (print .Name ".header")}}, "Header")
+f.length = ProtoField.uint8({{quote (print .Name ".length")}}, "Length", base.DEC)
+{{- if ge .Complement 0}}
+f.length_complement = ProtoField.uint8({{quote (print .Name ".length_complement")}}, "Length complement", base.HEX)
+{{- end}}
+{{- if ge .HeaderChecksum 0}}
+f.header_checksum = ProtoField.uint8({{quote (print .Name ".header_checksum")}}, "Header checksum", base.HEX)
+{{- end}}
+f.data = ProtoField.bytes({{quote (print .Name ".data")}}, "Data")
+f.checksum = ProtoField.uint{{if .XMODEM}}16{{else}}8{{end}}({{quote (print .Name ".checksum")}}, "Checksum", base.HEX)
+{{- range .Layouts}}
+{{- range .Fields}}
+f[{{quote .Key}}] = ProtoField.bytes({{quote .Abbr}}, {{quote .Name}})
+{{- end}}
+{{- end}}
+
+local checksum_bad = ProtoExpert.new({{quote (print .Name ".checksum.bad")}}, "Bad checksum", expert.group.CHECKSUM, expert.severity.ERROR)
+proto.experts = { checksum_bad }
+
+-- layouts map headers to the fields of data, as pairs of a field and its size
+-- (0 meaning the rest of the data)
+local layouts = {
+{{- range .Layouts}}
+	[{{quote .Header}}] = {
+{{- range .Fields}}
+		{ f[{{quote .Key}}], {{.Size}} },
+{{- end}}
+	},
+{{- end}}
+}
+
+-- xor calculates the 8-bit XOR of length bytes starting at offset.
+local function xor(tvb, offset, length)
+	local sum = 0
+	for i = offset, offset + length - 1 do
+		sum = bit.bxor(sum, tvb(i, 1):uint())
+	end
+	return sum
+end
+{{- if .XMODEM}}
+
+-- crc_xmodem calculates the CRC-16/XMODEM of length bytes starting at offset.
+local function crc_xmodem(tvb, offset, length)
+	local crc = 0
+	for i = offset, offset + length - 1 do
+		crc = bit.bxor(crc, bit.lshift(tvb(i, 1):uint(), 8))
+		for _ = 1, 8 do
+			if bit.band(crc, 0x8000) ~= 0 then
+				crc = bit.bxor(bit.lshift(crc, 1), 0x1021)
+			else
+				crc = bit.lshift(crc, 1)
+			end
+			crc = bit.band(crc, 0xffff)
+		end
+	end
+	return crc
+end
+{{- end}}
+
+function proto.dissector(tvb, pinfo, tree)
+	local data_offset = {{.DataOffset}}
+	local data_length = tvb:len() - data_offset - {{.SuffixLen}}
+	if data_length < 0 then
+		return 0
+	end
+
+	pinfo.cols.protocol = proto.name
+	local header = tvb({{.PreambleLen}}, 2):string()
+	pinfo.cols.info = string.format("%s, %d bytes of data", header, data_length)
+
+	local subtree = tree:add(proto, tvb())
+{{- if .PreambleLen}}
+	subtree:add(f.preamble, tvb(0, {{.PreambleLen}}))
+{{- end}}
+	subtree:add(f.header, tvb({{.PreambleLen}}, 2))
+	subtree:add(f.length, tvb({{.LengthOffset}}, 1))
+{{- if ge .Complement 0}}
+	subtree:add(f.length_complement, tvb({{.Complement}}, 1))
+{{- end}}
+{{- if ge .HeaderChecksum 0}}
+	local header_checksum = subtree:add(f.header_checksum, tvb({{.HeaderChecksum}}, 1))
+	if tvb({{.HeaderChecksum}}, 1):uint() ~= xor(tvb, {{.PreambleLen}}, {{.HeaderChecksum}} - {{.PreambleLen}}) then
+		header_checksum:add_proto_expert_info(checksum_bad)
+	end
+{{- end}}
+
+	if data_length > 0 then
+		local data = subtree:add(f.data, tvb(data_offset, data_length))
+		local layout = layouts[header]
+		if layout then
+			local offset = data_offset
+			local data_end = data_offset + data_length
+			for _, field in ipairs(layout) do
+				local size = field[2]
+				if size == 0 or offset + size > data_end then
+					size = data_end - offset
+				end
+				if size <= 0 then
+					break
+				end
+				data:add(field[1], tvb(offset, size))
+				offset = offset + size
+			end
+		end
+	end
+
+	local checksum_offset = data_offset + data_length{{if not .BeforeHash}} + 1{{end}}
+	local checksum = subtree:add(f.checksum, tvb(checksum_offset, {{.ChecksumSize}}))
+{{- if .DataChecksum}}
+	local covered_offset, covered_length = data_offset, data_length
+{{- else}}
+	local covered_offset, covered_length = {{.PreambleLen}}, checksum_offset - {{.PreambleLen}}
+{{- end}}
+	if tvb(checksum_offset, {{.ChecksumSize}}):uint() ~= {{if .XMODEM}}crc_xmodem{{else}}xor{{end}}(tvb, covered_offset, covered_length) then
+		checksum:add_proto_expert_info(checksum_bad)
+		pinfo.cols.info:append(" [bad checksum]")
+	end
+
+	return tvb:len()
+end
+
+DissectorTable.get("wtap_encap"):add(wtap_encaps.{{.Encap}}, proto)
+`))
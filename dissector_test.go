@@ -0,0 +1,69 @@
+package frames_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestWriteDissector(t *testing.T) {
+	var buf bytes.Buffer
+	err := frames.WriteDissector(&buf, frames.DissectorConfig{
+		Name:     "lidar",
+		Codec:    frames.Codec{Preamble: []byte{0xaa, 0x55}, HeaderChecksum: true, ChecksumAlgorithm: frames.ChecksumXMODEM},
+		LinkType: frames.LinkTypeUser0 + 2,
+		Fields: map[string][]frames.DissectorField{
+			"LD": {{Name: "Angle", Size: 2}, {Name: "Distances"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dissector := buf.String()
+	for _, want := range []string{
+		`local proto = Proto("lidar", "Frames (lidar)")`,
+		`f.preamble = ProtoField.bytes("lidar.preamble", "Preamble")`,
+		`f.header_checksum = ProtoField.uint8("lidar.header_checksum", "Header checksum", base.HEX)`,
+		`f.checksum = ProtoField.uint16("lidar.checksum", "Checksum", base.HEX)`,
+		`f["data_ld_angle"] = ProtoField.bytes("lidar.ld.angle", "Angle")`,
+		`{ f["data_ld_distances"], 0 },`,
+		`local data_offset = 7`,
+		`crc_xmodem(tvb, covered_offset, covered_length)`,
+		`DissectorTable.get("wtap_encap"):add(wtap_encaps.USER2, proto)`,
+	} {
+		if !strings.Contains(dissector, want) {
+			t.Errorf("dissector does not contain %s:\n%s", want, dissector)
+		}
+	}
+
+	if strings.Contains(dissector, "length_complement") {
+		t.Errorf("dissector contains length complement, which the codec lacks:\n%s", dissector)
+	}
+}
+
+func TestWriteDissectorChecksumBeforeHash(t *testing.T) {
+	var buf bytes.Buffer
+	config := frames.DissectorConfig{Codec: frames.Codec{ChecksumBeforeHash: true}, LinkType: frames.LinkTypeUser0}
+	if err := frames.WriteDissector(&buf, config); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "local checksum_offset = data_offset + data_length\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("dissector does not contain %s:\n%s", want, buf.String())
+	}
+}
+
+func TestWriteDissectorErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := frames.WriteDissector(&buf, frames.DissectorConfig{LinkType: 1}); err != frames.ErrDissectorLinkType {
+		t.Errorf("got error %v, want %v", err, frames.ErrDissectorLinkType)
+	}
+
+	config := frames.DissectorConfig{Codec: frames.Codec{HammingHeader: true}, LinkType: frames.LinkTypeUser0}
+	if err := frames.WriteDissector(&buf, config); err != frames.ErrDissectorCodec {
+		t.Errorf("got error %v, want %v", err, frames.ErrDissectorCodec)
+	}
+}
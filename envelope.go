@@ -0,0 +1,21 @@
+package frames
+
+import "time"
+
+// Envelope is a received frame together with metadata describing where and
+// when it arrived.
+type Envelope struct {
+	Frame Frame
+
+	// Time is when the frame was received.
+	Time time.Time
+
+	// Source identifies the transport the frame was received from.
+	Source string
+
+	// Valid reports whether the frame is valid.
+	Valid bool
+
+	// Corrected reports whether the frame was repaired, see Codec.Correct.
+	Corrected bool
+}
@@ -0,0 +1,74 @@
+package frames_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestReaderReadEnvelope(t *testing.T) {
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("test"))
+	reader := frames.NewReader(bytes.NewReader(frame), frames.Codec{}, frames.Limits{})
+	reader.Source = "usb"
+
+	before := time.Now()
+	got, err := reader.ReadEnvelope()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got.Frame, frame) {
+		t.Errorf("got frame % x, want frame % x", []byte(got.Frame), []byte(frame))
+	}
+
+	if got.Source != "usb" || !got.Valid || got.Corrected || got.Time.Before(before) {
+		t.Errorf("got envelope %+v, want a valid frame from usb received after %v", got, before)
+	}
+}
+
+func TestMuxEnvelopes(t *testing.T) {
+	mux := frames.NewMux()
+
+	frameSub, cancelFrames := mux.Subscribe(nil)
+	defer cancelFrames()
+
+	envelopeSub, cancelEnvelopes := mux.SubscribeEnvelopes(func(frame frames.Frame) bool {
+		return string(frame.Header()) == "MT"
+	})
+	defer cancelEnvelopes()
+
+	envelopes := []frames.Envelope{
+		{Frame: frames.Create([2]byte{'L', 'D'}, []byte("a")), Source: "radio1", Valid: true},
+		{Frame: frames.Create([2]byte{'M', 'T'}, []byte("b")), Source: "radio2", Valid: true},
+	}
+
+	var i int
+	err := mux.ServeEnvelopes(func() (frames.Envelope, error) {
+		if i == len(envelopes) {
+			return frames.Envelope{}, io.EOF
+		}
+		i++
+		return envelopes[i-1], nil
+	})
+	if err != io.EOF {
+		t.Fatalf("got error %v, want %v", err, io.EOF)
+	}
+
+	for i, envelope := range envelopes {
+		if got := <-frameSub; !bytes.Equal(got, envelope.Frame) {
+			t.Errorf("test %d: got frame % x, want frame % x", i, []byte(got), []byte(envelope.Frame))
+		}
+	}
+
+	if got := <-envelopeSub; got.Source != "radio2" || !bytes.Equal(got.Frame, envelopes[1].Frame) {
+		t.Errorf("got envelope %+v, want envelope %+v", got, envelopes[1])
+	}
+
+	mux.Publish(envelopes[1].Frame)
+	if got := <-envelopeSub; got.Source != "" || !got.Valid || got.Time.IsZero() {
+		t.Errorf("got envelope %+v, want a valid frame without a source", got)
+	}
+}
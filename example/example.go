@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"log"
+	"os"
 
 	"github.com/knei-knurow/frames"
 )
@@ -19,4 +21,12 @@ func main() {
 	for i, v := range f2 {
 		fmt.Printf("%d: %s\n", i, frames.DescribeByte(v))
 	}
+
+	// a simulated LIDAR connected to the host
+	summary, err := simulate(os.Stdout, 50)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("received %d measurements and %d status reports, skipped %d corrupted frames\n",
+		summary.measurements, summary.statuses, 50-summary.measurements)
 }
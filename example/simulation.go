@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/knei-knurow/frames"
+)
+
+// Every corruptEvery-th measurement sent by the simulated LIDAR has a flipped
+// bit, and every noiseEvery-th one is preceded by line noise, including what
+// looks like the start of a frame.
+const (
+	corruptEvery = 10
+	noiseEvery   = 7
+	statusEvery  = 20
+)
+
+var noise = []byte{0x00, 0xff, 'L', 'D', 0x08, '+'}
+
+// summary counts frames received by the host.
+type summary struct {
+	measurements int
+	statuses     int
+}
+
+// lidar simulates a LIDAR sending n measurements ("LD" frames with a 2-byte
+// angle and a 2-byte distance) and a status report ("ST" frame) every
+// statusEvery measurements. Some measurements are corrupted or preceded by
+// noise.
+func lidar(w io.WriteCloser, n int) error {
+	defer w.Close()
+
+	for i := 1; i <= n; i++ {
+		data := make([]byte, 4)
+		binary.BigEndian.PutUint16(data[0:], uint16(i*360/n))
+		binary.BigEndian.PutUint16(data[2:], uint16(1000+i))
+		frame := frames.Create([2]byte{'L', 'D'}, data)
+
+		if i%noiseEvery == 0 {
+			if _, err := w.Write(noise); err != nil {
+				return err
+			}
+		}
+		if i%corruptEvery == 0 {
+			frame[5] ^= 0x01
+		}
+		if _, err := w.Write(frame); err != nil {
+			return err
+		}
+
+		if i%statusEvery == 0 {
+			if _, err := w.Write(frames.Create([2]byte{'S', 'T'}, []byte("ok"))); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// simulate connects a simulated LIDAR sending n measurements to a host, which
+// reads frames with a Reader and distributes them with a Mux to a handler of
+// measurements and a handler of status reports. Invalid frames and noise are
+// skipped by the Reader.
+func simulate(out io.Writer, n int) (summary, error) {
+	r, w := io.Pipe()
+
+	go func() {
+		w.CloseWithError(lidar(w, n))
+	}()
+
+	mux := frames.NewMux()
+	measurements, cancelMeasurements := mux.Subscribe(func(frame frames.Frame) bool {
+		return string(frame.Header()) == "LD" && frame.LenData() == 4
+	})
+	statuses, cancelStatuses := mux.Subscribe(func(frame frames.Frame) bool {
+		return string(frame.Header()) == "ST"
+	})
+
+	var s summary
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for frame := range measurements {
+			data := frame.Data()
+			fmt.Fprintf(out, "angle %3d: distance %d mm\n", binary.BigEndian.Uint16(data), binary.BigEndian.Uint16(data[2:]))
+			s.measurements++
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for frame := range statuses {
+			fmt.Fprintf(out, "status: %s\n", frame.Data())
+			s.statuses++
+		}
+	}()
+
+	reader := frames.NewReader(r, frames.Codec{}, frames.Limits{MaxDataLength: 16})
+	err := mux.Serve(reader.ReadFrame)
+
+	cancelMeasurements()
+	cancelStatuses()
+	wg.Wait()
+
+	if err != io.EOF {
+		return s, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"io"
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	const n = 50
+
+	got, err := simulate(io.Discard, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := summary{measurements: n - n/corruptEvery, statuses: n / statusEvery}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package frames
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoExtensions is returned when setting an extension with a codec that does
+// not have Codec.Extensions enabled.
+var ErrNoExtensions = errors.New("frames: codec has no extensions")
+
+// ErrExtensionsLength is returned when extensions do not fit in the extensions
+// area, whose length must not overflow byte.
+var ErrExtensionsLength = errors.New("frames: extensions too long")
+
+// Extension returns the value of the first extension of type typ in frame,
+// which must be valid according to c, and whether it is present. The value
+// refers to frame.
+func (c Codec) Extension(frame Frame, typ byte) (value []byte, ok bool) {
+	if !c.Extensions {
+		return nil, false
+	}
+
+	return TLV(c.extensions(frame)).Field(typ)
+}
+
+// SetExtension returns a copy of frame, which must be valid according to c,
+// with the extension of type typ set to value. Extensions of the same type are
+// replaced; if value is nil, they are removed. Other extensions, including
+// unknown ones, are kept in order.
+func (c Codec) SetExtension(frame Frame, typ byte, value []byte) (Frame, error) {
+	if !c.Extensions {
+		return nil, ErrNoExtensions
+	}
+
+	var area TLV
+	for it := TLV(c.extensions(frame)).Fields(); it.Next(); {
+		if field := it.Field(); field.Tag != typ {
+			if err := area.AddField(field.Tag, field.Value); err != nil {
+				return nil, ErrExtensionsLength
+			}
+		}
+	}
+	if value != nil {
+		if err := area.AddField(typ, value); err != nil {
+			return nil, ErrExtensionsLength
+		}
+	}
+
+	return c.withExtensions(frame, area), nil
+}
+
+// extensions returns the extension entries of frame encoded with c, without
+// the area's length byte.
+func (c Codec) extensions(frame Frame) []byte {
+	begin := c.dataEnd(frame) + 1
+	if end := c.suffixOffset(frame); begin < end {
+		return frame[begin:end]
+	}
+
+	return nil
+}
+
+// withExtensions returns a copy of valid frame encoded with c whose extension
+// entries are replaced with area.
+func (c Codec) withExtensions(frame Frame, area []byte) Frame {
+	end := c.dataEnd(frame)
+	copied := make(Frame, end+1+len(area)+c.suffixLen())
+	copy(copied, frame[:end])
+	copied[end] = byte(len(area))
+	copy(copied[end+1:], area)
+	copied[c.hashOffset(copied)] = '#'
+	if c.CRLF {
+		copy(copied[len(copied)-2:], "\r\n")
+	}
+	c.putChecksum(copied, c.CalculateChecksum(copied))
+
+	return copied
+}
+
+// validateExtensions checks the extensions area of frame, which must be at
+// least as long as its fixed parts.
+func (c Codec) validateExtensions(frame Frame) error {
+	begin := c.dataOffset() + c.LenData(frame)
+	end := c.suffixOffset(frame)
+	if begin >= end {
+		return &VerifyError{Offset: c.lengthOffset(), Want: fmt.Sprintf("length at most %#02x", end-1-c.dataOffset()), Got: c.LenData(frame)}
+	}
+
+	if want := end - begin - 1; int(frame[begin]) != want {
+		return &VerifyError{Offset: begin, Want: fmt.Sprintf("extensions length %#02x", want), Got: int(frame[begin])}
+	}
+
+	for i := begin + 1; i < end; i += 2 + int(frame[i+1]) {
+		if i+2 > end || i+2+int(frame[i+1]) > end {
+			return &VerifyError{Offset: i, Want: "extension entry", Got: int(frame[i])}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package frames_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestCodecExtensions(t *testing.T) {
+	codec := frames.Codec{Extensions: true}
+
+	frame := codec.Create([2]byte{'L', 'D'}, []byte("AB"))
+	want := []byte{'L', 'D', 0x02, '+', 'A', 'B', 0x00, '#', 0x01}
+	if !bytes.Equal(frame, want) {
+		t.Fatalf("got frame % x, want % x", []byte(frame), want)
+	}
+
+	extended, err := codec.SetExtension(frame, 0x07, []byte{0x09})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []byte{'L', 'D', 0x02, '+', 'A', 'B', 0x03, 0x07, 0x01, 0x09, '#', 0x0d}
+	if !bytes.Equal(extended, want) {
+		t.Fatalf("got frame % x, want % x", []byte(extended), want)
+	}
+
+	if err := codec.Validate(extended); err != nil {
+		t.Errorf("frame % x: %v", []byte(extended), err)
+	}
+	if got := codec.Data(extended); !bytes.Equal(got, []byte("AB")) {
+		t.Errorf("got data %q, want %q", got, "AB")
+	}
+	if value, ok := codec.Extension(extended, 0x07); !ok || !bytes.Equal(value, []byte{0x09}) {
+		t.Errorf("got extension % x, %t, want 09, true", value, ok)
+	}
+	if _, ok := codec.Extension(extended, 0x08); ok {
+		t.Errorf("got extension 0x08, want none")
+	}
+
+	parsed, n, err := codec.ParseFrame(append(append([]byte("xx"), extended...), 'L'))
+	if err != nil || n != 2+len(extended) || !bytes.Equal(parsed, extended) {
+		t.Errorf("got frame % x, %d, %v, want % x, %d, nil", []byte(parsed), n, err, []byte(extended), 2+len(extended))
+	}
+
+	removed, err := codec.SetExtension(extended, 0x07, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(removed, frame) {
+		t.Errorf("got frame % x, want % x", []byte(removed), []byte(frame))
+	}
+}
+
+func TestCodecExtensionsUnknown(t *testing.T) {
+	codec := frames.Codec{Extensions: true, ChecksumAlgorithm: frames.ChecksumXMODEM}
+
+	frame := codec.Create([2]byte{'L', 'D'}, []byte("AB"))
+	for _, ext := range []struct {
+		typ   byte
+		value []byte
+	}{
+		{0x01, []byte{0xaa}},
+		{0x02, []byte{}},
+		{0x03, []byte{0xbb, 0xcc}},
+		{0x01, []byte{0xdd, 0xee}},
+	} {
+		var err error
+		if frame, err = codec.SetExtension(frame, ext.typ, ext.value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := codec.Validate(frame); err != nil {
+		t.Fatalf("frame % x: %v", []byte(frame), err)
+	}
+
+	// replaced extensions move to the end, others keep their order
+	want := []byte{0x02, 0x00, 0x03, 0x02, 0xbb, 0xcc, 0x01, 0x02, 0xdd, 0xee}
+	if got := frame[7 : 7+len(want)]; !bytes.Equal(got, want) {
+		t.Errorf("got extensions % x, want % x", got, want)
+	}
+
+	// a codec without extensions treats the frame as invalid
+	if (frames.Codec{ChecksumAlgorithm: frames.ChecksumXMODEM}).Verify(frame) {
+		t.Errorf("frame % x valid without extensions", []byte(frame))
+	}
+
+	to := frames.Codec{Extensions: true, CRLF: true}
+	converted, err := frames.Convert(frame, codec, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := to.Extension(converted, 0x03); !ok || !bytes.Equal(value, []byte{0xbb, 0xcc}) {
+		t.Errorf("got extension % x, %t after conversion, want bb cc, true", value, ok)
+	}
+
+	if _, err := codec.SetExtension(frame, 0x04, make([]byte, 0x100)); err != frames.ErrExtensionsLength {
+		t.Errorf("got error %v, want %v", err, frames.ErrExtensionsLength)
+	}
+	if _, err := (frames.Codec{}).SetExtension(frame, 0x04, nil); err != frames.ErrNoExtensions {
+		t.Errorf("got error %v, want %v", err, frames.ErrNoExtensions)
+	}
+}
+
+func TestCodecExtensionsInvalid(t *testing.T) {
+	codec := frames.Codec{Extensions: true, ChecksumBeforeHash: true}
+
+	for _, frame := range [][]byte{
+		{'L', 'D', 0x02, '+', 'A', 'B', 0x01, 0x07, 0x00, '#'},
+		{'L', 'D', 0x02, '+', 'A', 'B', 0x02, 0x07, 0x01, 0x00, '#'},
+		{'L', 'D', 0x03, '+', 'A', 'B', 0x00, 0x00, '#'},
+		{'L', 'D', 0x02, '+', 'A', 'B', 0x05, 0x00, '#'},
+	} {
+		var checksum byte
+		for _, b := range frame[:len(frame)-2] {
+			checksum ^= b
+		}
+		frame[len(frame)-2] = checksum
+
+		if err := codec.Validate(frame); err == nil {
+			t.Errorf("frame % x valid", frame)
+		}
+	}
+
+	frame := codec.Create([2]byte{'L', 'D'}, []byte("AB"))
+	frame, err := codec.SetExtension(frame, 0x07, []byte{0x09})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := codec.Validate(frame); err != nil {
+		t.Errorf("frame % x: %v", []byte(frame), err)
+	}
+}
@@ -0,0 +1,264 @@
+package frames
+
+import "errors"
+
+var (
+	// ErrFECLength is returned by DecodeFEC when the block is too short or too
+	// long for the number of parity bytes.
+	ErrFECLength = errors.New("frames: invalid FEC block length")
+
+	// ErrFECParity is returned by EncodeFEC and DecodeFEC when the number of
+	// parity bytes is not positive and even, or does not leave room for data
+	// in a block.
+	ErrFECParity = errors.New("frames: invalid number of FEC parity bytes")
+
+	// ErrFECUncorrectable is returned by DecodeFEC when the block has more
+	// corrupted bytes than can be corrected.
+	ErrFECUncorrectable = errors.New("frames: too many corrupted bytes to correct")
+)
+
+// MaxFECBlockSize is the maximum length of a block, i.e. a frame followed by
+// its parity bytes, created by EncodeFEC.
+const MaxFECBlockSize = 255
+
+// EncodeFEC returns a block consisting of frame followed by parity Reed-Solomon
+// parity bytes, calculated over GF(2^8) with the polynomial 0x11d. DecodeFEC
+// can correct up to parity/2 corrupted bytes anywhere in the block, which lets
+// a receiver without a back channel recover frames without retransmission.
+//
+// Parity must be positive and even. If the block would be longer than
+// MaxFECBlockSize, EncodeFEC returns ErrFECLength.
+func EncodeFEC(frame Frame, parity int) ([]byte, error) {
+	if !validFECParity(parity) {
+		return nil, ErrFECParity
+	}
+	if len(frame)+parity > MaxFECBlockSize {
+		return nil, ErrFECLength
+	}
+
+	generator := rsGenerator(parity)
+
+	block := make([]byte, len(frame)+parity)
+	copy(block, frame)
+	for i := range frame {
+		coef := block[i]
+		if coef == 0 {
+			continue
+		}
+
+		for j := 1; j < len(generator); j++ {
+			block[i+j] ^= gfMul(generator[j], coef)
+		}
+	}
+	copy(block, frame)
+
+	return block, nil
+}
+
+// validFECParity reports whether parity is a valid number of parity bytes.
+func validFECParity(parity int) bool {
+	return parity > 0 && parity%2 == 0 && parity < MaxFECBlockSize
+}
+
+// DecodeFEC corrects block created by EncodeFEC with parity parity bytes. It
+// returns the frame with corrupted bytes corrected and the number of corrected
+// bytes. The frame must be valid after correction, see Validate.
+func DecodeFEC(block []byte, parity int) (frame Frame, corrected int, err error) {
+	if !validFECParity(parity) {
+		return nil, 0, ErrFECParity
+	}
+	if len(block) <= parity || len(block) > MaxFECBlockSize {
+		return nil, 0, ErrFECLength
+	}
+
+	block = append([]byte{}, block...)
+
+	syndromes := make([]byte, parity)
+	clean := true
+	for j := range syndromes {
+		syndromes[j] = gfPolyEval(block, gfExp[j])
+		clean = clean && syndromes[j] == 0
+	}
+
+	if !clean {
+		if corrected, err = rsCorrect(block, syndromes); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	frame = Frame(block[:len(block)-parity])
+	if err := Validate(frame); err != nil {
+		return nil, 0, err
+	}
+
+	return frame, corrected, nil
+}
+
+// rsCorrect corrects block in place given its non-zero syndromes. It returns
+// the number of corrected bytes.
+func rsCorrect(block, syndromes []byte) (int, error) {
+	// error locator with the Berlekamp-Massey algorithm, lowest degree first
+	locator, previous := []byte{1}, []byte{1}
+	length, shift, lastDiscrepancy := 0, 1, byte(1)
+	for n := range syndromes {
+		discrepancy := syndromes[n]
+		for i := 1; i <= length; i++ {
+			discrepancy ^= gfMul(locator[i], syndromes[n-i])
+		}
+
+		if discrepancy == 0 {
+			shift++
+			continue
+		}
+
+		scale := gfDiv(discrepancy, lastDiscrepancy)
+		updated := make([]byte, max2(len(locator), len(previous)+shift))
+		copy(updated, locator)
+		for i, c := range previous {
+			updated[i+shift] ^= gfMul(scale, c)
+		}
+
+		if 2*length <= n {
+			previous, lastDiscrepancy = locator, discrepancy
+			length = n + 1 - length
+			shift = 1
+		} else {
+			shift++
+		}
+		locator = updated
+	}
+	locator = locator[:length+1]
+
+	if 2*length > len(syndromes) {
+		return 0, ErrFECUncorrectable
+	}
+
+	// error evaluator: syndromes times locator, modulo x^len(syndromes)
+	evaluator := make([]byte, len(syndromes))
+	for i, s := range syndromes {
+		for j, l := range locator {
+			if i+j < len(evaluator) {
+				evaluator[i+j] ^= gfMul(s, l)
+			}
+		}
+	}
+
+	// find the roots of the locator and the error magnitudes
+	n := len(block)
+	found := 0
+	for power := 0; power < n; power++ {
+		xInv := gfExp[(255-power)%255]
+		if gfPolyEvalLow(locator, xInv) != 0 {
+			continue
+		}
+
+		var derivative byte
+		for i := 1; i < len(locator); i += 2 {
+			derivative ^= gfMul(locator[i], gfPow(xInv, i-1))
+		}
+		if derivative == 0 {
+			return 0, ErrFECUncorrectable
+		}
+
+		magnitude := gfDiv(gfMul(gfExp[power], gfPolyEvalLow(evaluator, xInv)), derivative)
+		block[n-1-power] ^= magnitude
+		found++
+	}
+
+	if found != length {
+		return 0, ErrFECUncorrectable
+	}
+
+	return found, nil
+}
+
+// rsGenerator returns the generator polynomial with the roots from α^0 to
+// α^(parity-1), highest degree first.
+func rsGenerator(parity int) []byte {
+	generator := []byte{1}
+	for j := 0; j < parity; j++ {
+		next := make([]byte, len(generator)+1)
+		for i, c := range generator {
+			next[i] ^= c
+			next[i+1] ^= gfMul(c, gfExp[j])
+		}
+		generator = next
+	}
+
+	return generator
+}
+
+// gfExp and gfLog are the exponent and logarithm tables of GF(2^8) with the
+// polynomial 0x11d and the generator 2. gfExp is doubled to avoid reducing
+// sums of logarithms.
+var gfExp, gfLog = gfTables()
+
+func gfTables() (exp [510]byte, log [256]byte) {
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		exp[i+255] = byte(x)
+		log[x] = byte(i)
+
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+
+	return
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// gfDiv divides a by b, which must not be zero.
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+
+	return gfExp[(int(gfLog[a])+255-int(gfLog[b]))%255]
+}
+
+func gfPow(a byte, n int) byte {
+	if n == 0 {
+		return 1
+	}
+	if a == 0 {
+		return 0
+	}
+
+	return gfExp[int(gfLog[a])*n%255]
+}
+
+// gfPolyEval evaluates polynomial p, highest degree first, at x.
+func gfPolyEval(p []byte, x byte) (y byte) {
+	for _, c := range p {
+		y = gfMul(y, x) ^ c
+	}
+
+	return
+}
+
+// gfPolyEvalLow evaluates polynomial p, lowest degree first, at x.
+func gfPolyEvalLow(p []byte, x byte) (y byte) {
+	for i := len(p) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ p[i]
+	}
+
+	return
+}
+
+func max2(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,88 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestFEC(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for _, parity := range []int{2, 8, 16} {
+		for i, tc := range testCases {
+			if len(tc.frame)+parity > frames.MaxFECBlockSize {
+				continue
+			}
+
+			block, err := frames.EncodeFEC(tc.frame, parity)
+			if err != nil {
+				t.Fatalf("parity %d, test %d: %v", parity, i, err)
+			}
+			if !bytes.Equal(block[:len(tc.frame)], tc.frame) {
+				t.Fatalf("parity %d, test %d: got block % x not starting with the frame", parity, i, block)
+			}
+
+			for errs := 0; errs <= parity/2; errs++ {
+				corrupted := append([]byte{}, block...)
+				for _, offset := range random.Perm(len(block))[:errs] {
+					corrupted[offset] ^= byte(1 + random.Intn(255))
+				}
+
+				got, corrected, err := frames.DecodeFEC(corrupted, parity)
+				if err != nil {
+					t.Errorf("parity %d, test %d, %d errors: %v", parity, i, errs, err)
+					continue
+				}
+
+				if !bytes.Equal(got, tc.frame) || corrected != errs {
+					t.Errorf("parity %d, test %d: got frame % x with %d corrected bytes, want frame % x with %d", parity, i, []byte(got), corrected, tc.frame, errs)
+				}
+			}
+		}
+	}
+}
+
+func TestDecodeFECErrors(t *testing.T) {
+	frame := frames.Create([2]byte{'T', 'M'}, []byte("telemetry"))
+	block, err := frames.EncodeFEC(frame, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// too many errors for 4 parity bytes
+	for i := 0; i < 6; i++ {
+		block[i] ^= 0x55
+	}
+	if _, _, err := frames.DecodeFEC(block, 4); err == nil {
+		t.Error("got no error for an uncorrectable block")
+	}
+
+	if _, _, err := frames.DecodeFEC(block[:4], 4); !errors.Is(err, frames.ErrFECLength) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrFECLength)
+	}
+}
+
+func TestEncodeFECErrors(t *testing.T) {
+	frame := frames.Create([2]byte{'T', 'M'}, []byte("telemetry"))
+
+	for _, parity := range []int{-2, 0, 3, frames.MaxFECBlockSize + 1} {
+		if _, err := frames.EncodeFEC(frame, parity); !errors.Is(err, frames.ErrFECParity) {
+			t.Errorf("parity %d: got error %v, want error %v", parity, err, frames.ErrFECParity)
+		}
+		if _, _, err := frames.DecodeFEC(frame, parity); !errors.Is(err, frames.ErrFECParity) {
+			t.Errorf("parity %d: got error %v, want error %v", parity, err, frames.ErrFECParity)
+		}
+	}
+
+	long := frames.Create([2]byte{'T', 'M'}, make([]byte, frames.MaxFECBlockSize-6-4+1))
+	if _, err := frames.EncodeFEC(long, 4); !errors.Is(err, frames.ErrFECLength) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrFECLength)
+	}
+	if _, err := frames.EncodeFEC(long[:len(long)-1], 4); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+}
@@ -0,0 +1,55 @@
+package frames
+
+import "fmt"
+
+// Fields holds the fields of a frame, as returned by VerifyAndParse. Data
+// refers to the parsed frame.
+type Fields struct {
+	Header   [2]byte
+	Data     []byte
+	Checksum byte
+}
+
+// VerifyAndParse checks whether the frame is valid and returns its fields. It
+// does so in a single pass over the frame, so it is faster than calling Verify
+// followed by Header, Data and Checksum. If the frame is invalid, it returns a
+// *VerifyError, just like Validate.
+func VerifyAndParse(frame Frame) (fields Fields, err error) {
+	if len(frame) < minFrameLen {
+		return Fields{}, &VerifyError{Offset: len(frame), Want: fmt.Sprintf("at least %d bytes", minFrameLen), Got: -1}
+	}
+
+	for i := 0; i < 2; i++ {
+		if !validHeaderByte(frame[i]) {
+			return Fields{}, &VerifyError{Offset: i, Want: "uppercase ASCII letter or digit", Got: int(frame[i])}
+		}
+	}
+
+	if frame[3] != '+' {
+		return Fields{}, &VerifyError{Offset: 3, Want: "'+'", Got: int(frame[3])}
+	}
+
+	end := len(frame) - 2
+	if int(frame[2]) != end-4 {
+		return Fields{}, &VerifyError{Offset: 2, Want: fmt.Sprintf("length %#02x", end-4), Got: int(frame[2])}
+	}
+
+	if frame[end] != '#' {
+		return Fields{}, &VerifyError{Offset: end, Want: "'#'", Got: int(frame[end])}
+	}
+
+	checksum := frame[0] ^ frame[1] ^ frame[2] ^ frame[3] ^ frame[end]
+	for _, b := range frame[4:end] {
+		checksum ^= b
+	}
+
+	if checksum != frame[end+1] {
+		return Fields{}, &VerifyError{Offset: end + 1, Want: fmt.Sprintf("checksum %#02x", checksum), Got: int(frame[end+1])}
+	}
+
+	return Fields{
+		Header:   [2]byte{frame[0], frame[1]},
+		Data:     frame[4:end],
+		Checksum: checksum,
+	}, nil
+}
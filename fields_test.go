@@ -0,0 +1,70 @@
+package frames_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestVerifyAndParse(t *testing.T) {
+	for i, tc := range testCases {
+		testName := fmt.Sprintf("test %d", i)
+		t.Run(testName, func(t *testing.T) {
+			fields, err := frames.VerifyAndParse(tc.frame)
+			if err != nil {
+				t.Fatalf("got error %v, want no error", err)
+			}
+
+			if fields.Header != tc.inputHeader {
+				t.Errorf("got header % x, want header % x", fields.Header, tc.inputHeader)
+			}
+
+			if !bytes.Equal(fields.Data, tc.inputData) {
+				t.Errorf("got data % x, want data % x", fields.Data, tc.inputData)
+			}
+
+			if fields.Checksum != tc.expectedChecksum {
+				t.Errorf("got checksum % x, want checksum % x", fields.Checksum, tc.expectedChecksum)
+			}
+		})
+	}
+}
+
+func TestVerifyAndParseInvalid(t *testing.T) {
+	invalid := [][]byte{
+		nil,
+		{'x', 'd'},
+		{'L', 'd', 0x1, '+', 'A', '#', 0x60},
+		{'M', 'T', 0x6, '+', 'd', 'o', 'n', 'd', 'u', '#', 0x63},
+		{'L', 'D', 0x1, 'A', 'A', '#', 0x2a},
+		{'L', 'D', 0x1, '+', 'A', '+', 0x48},
+		{'L', 'D', 0x1, '+', 'A', '#', 0x00},
+	}
+
+	for i, frame := range invalid {
+		_, err := frames.VerifyAndParse(frame)
+		want := frames.Validate(frame)
+		if err == nil || err.Error() != want.Error() {
+			t.Errorf("test %d: got error %v, want error %v", i, err, want)
+		}
+	}
+}
+
+func FuzzVerifyAndParse(f *testing.F) {
+	for _, tc := range testCases {
+		f.Add(tc.frame)
+	}
+
+	f.Fuzz(func(t *testing.T, frame []byte) {
+		fields, err := frames.VerifyAndParse(frame)
+		if want := frames.Validate(frame); fmt.Sprint(err) != fmt.Sprint(want) {
+			t.Fatalf("frame % x: got error %v, want error %v", frame, err, want)
+		}
+
+		if err == nil && !bytes.Equal(frames.Create(fields.Header, fields.Data), frame) {
+			t.Errorf("frame % x: got fields %v", frame, fields)
+		}
+	})
+}
@@ -0,0 +1,358 @@
+package frames
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Filter reports whether a frame should be kept.
+type Filter func(Frame) bool
+
+// FilterError describes a syntax or type error in a filter expression.
+type FilterError struct {
+	// Offset is the index of the offending character in the expression.
+	Offset int
+
+	// Msg describes the error.
+	Msg string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("frames: filter offset %d: %s", e.Offset, e.Msg)
+}
+
+// ParseFilter parses a filter expression, e.g.
+//
+//	header == "LD" && len > 3 && data[0] == 0x01
+//
+// The expression compares fields of a frame with constants or with each other:
+//
+// - header: the frame's header as a string
+//
+// - len: the length byte as an integer
+//
+// - data[i]: the i-th data byte as an integer; comparisons with a missing
+// byte are false
+//
+// - valid: whether the frame is valid, usable on its own as a condition
+//
+// Strings are double-quoted and can only be compared with == and !=. Integers
+// are decimal or hexadecimal with the 0x prefix and can be compared with ==,
+// !=, <, <=, > and >=. Conditions can be combined with &&, || and !, and
+// grouped with parentheses.
+//
+// Frames shorter than an empty frame never match.
+func ParseFilter(expr string) (Filter, error) {
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens, end: len(expr)}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != filterEOF {
+		return nil, &FilterError{Offset: tok.offset, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+
+	return func(frame Frame) bool {
+		return len(frame) >= minFrameLen && filter(frame)
+	}, nil
+}
+
+type filterTokenKind int
+
+const (
+	filterEOF filterTokenKind = iota
+	filterIdent
+	filterInt
+	filterString
+	filterOp
+)
+
+type filterToken struct {
+	kind   filterTokenKind
+	text   string
+	offset int
+}
+
+// filterOps are the operators and punctuation of filter expressions, two
+// character ones first.
+var filterOps = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]"}
+
+func lexFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+
+		case c >= 'a' && c <= 'z':
+			j := i
+			for j < len(expr) && expr[j] >= 'a' && expr[j] <= 'z' {
+				j++
+			}
+			tokens = append(tokens, filterToken{filterIdent, expr[i:j], i})
+			i = j
+
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(expr) && (expr[j] >= '0' && expr[j] <= '9' || expr[j] >= 'a' && expr[j] <= 'f' || expr[j] >= 'A' && expr[j] <= 'F' || expr[j] == 'x' || expr[j] == 'X') {
+				j++
+			}
+			tokens = append(tokens, filterToken{filterInt, expr[i:j], i})
+			i = j
+
+		case c == '"':
+			j := i + 1
+			for j < len(expr) && expr[j] != '"' {
+				if expr[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(expr) {
+				return nil, &FilterError{Offset: i, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, filterToken{filterString, expr[i : j+1], i})
+			i = j + 1
+
+		default:
+			op := ""
+			for _, o := range filterOps {
+				if strings.HasPrefix(expr[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, &FilterError{Offset: i, Msg: fmt.Sprintf("unexpected %q", c)}
+			}
+			tokens = append(tokens, filterToken{filterOp, op, i})
+			i += len(op)
+		}
+	}
+
+	return tokens, nil
+}
+
+// filterValue is an operand of a comparison, evaluated for a frame. ok is
+// false if the value is missing, e.g. a data byte past the end of data.
+type filterValue struct {
+	isString bool
+	str      func(Frame) string
+	num      func(Frame) (n int, ok bool)
+}
+
+// filterComparisons are the operators comparing integers.
+var filterComparisons = map[string]func(a, b int) bool{
+	"==": func(a, b int) bool { return a == b },
+	"!=": func(a, b int) bool { return a != b },
+	"<":  func(a, b int) bool { return a < b },
+	"<=": func(a, b int) bool { return a <= b },
+	">":  func(a, b int) bool { return a > b },
+	">=": func(a, b int) bool { return a >= b },
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+	end    int
+}
+
+func (p *filterParser) peek() filterToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return filterToken{kind: filterEOF, text: "end of expression", offset: p.end}
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+
+	return tok
+}
+
+func (p *filterParser) expect(op string) error {
+	if tok := p.next(); tok.kind != filterOp || tok.text != op {
+		return &FilterError{Offset: tok.offset, Msg: fmt.Sprintf("want %q, got %q", op, tok.text)}
+	}
+
+	return nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for tok := p.peek(); tok.kind == filterOp && tok.text == "||"; tok = p.peek() {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(f Frame) bool { return l(f) || right(f) }
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for tok := p.peek(); tok.kind == filterOp && tok.text == "&&"; tok = p.peek() {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(f Frame) bool { return l(f) && right(f) }
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == filterOp && tok.text == "!":
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(f Frame) bool { return !operand(f) }, nil
+
+	case tok.kind == filterOp && tok.text == "(":
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+
+	case tok.kind == filterIdent && tok.text == "valid":
+		p.next()
+		return func(f Frame) bool { return Verify(f) }, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (Filter, error) {
+	left, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	op := p.next()
+	compare, ok := filterComparisons[op.text]
+	if op.kind != filterOp || !ok {
+		return nil, &FilterError{Offset: op.offset, Msg: fmt.Sprintf("want comparison operator, got %q", op.text)}
+	}
+
+	rightOffset := p.peek().offset
+	right, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if left.isString != right.isString {
+		return nil, &FilterError{Offset: rightOffset, Msg: "cannot compare string with integer"}
+	}
+
+	if left.isString {
+		switch op.text {
+		case "==":
+			return func(f Frame) bool { return left.str(f) == right.str(f) }, nil
+		case "!=":
+			return func(f Frame) bool { return left.str(f) != right.str(f) }, nil
+		default:
+			return nil, &FilterError{Offset: op.offset, Msg: fmt.Sprintf("cannot compare strings with %q", op.text)}
+		}
+	}
+
+	return func(f Frame) bool {
+		a, ok := left.num(f)
+		if !ok {
+			return false
+		}
+		b, ok := right.num(f)
+		return ok && compare(a, b)
+	}, nil
+}
+
+func (p *filterParser) parseValue() (filterValue, error) {
+	tok := p.next()
+	switch tok.kind {
+	case filterString:
+		s, err := strconv.Unquote(tok.text)
+		if err != nil {
+			return filterValue{}, &FilterError{Offset: tok.offset, Msg: fmt.Sprintf("invalid string %s", tok.text)}
+		}
+		return filterValue{isString: true, str: func(Frame) string { return s }}, nil
+
+	case filterInt:
+		n, err := strconv.ParseInt(tok.text, 0, 64)
+		if err != nil {
+			return filterValue{}, &FilterError{Offset: tok.offset, Msg: fmt.Sprintf("invalid integer %s", tok.text)}
+		}
+		return filterValue{num: func(Frame) (int, bool) { return int(n), true }}, nil
+
+	case filterIdent:
+		switch tok.text {
+		case "header":
+			return filterValue{isString: true, str: func(f Frame) string { return string(f.Header()) }}, nil
+
+		case "len":
+			return filterValue{num: func(f Frame) (int, bool) { return f.LenData(), true }}, nil
+
+		case "data":
+			if err := p.expect("["); err != nil {
+				return filterValue{}, err
+			}
+
+			index := p.next()
+			i, err := strconv.ParseInt(index.text, 0, 64)
+			if index.kind != filterInt || err != nil {
+				return filterValue{}, &FilterError{Offset: index.offset, Msg: fmt.Sprintf("want data index, got %q", index.text)}
+			}
+
+			if err := p.expect("]"); err != nil {
+				return filterValue{}, err
+			}
+
+			return filterValue{num: func(f Frame) (int, bool) {
+				data := f.Data()
+				if int(i) >= len(data) {
+					return 0, false
+				}
+				return int(data[i]), true
+			}}, nil
+		}
+	}
+
+	return filterValue{}, &FilterError{Offset: tok.offset, Msg: fmt.Sprintf("want value, got %q", tok.text)}
+}
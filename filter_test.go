@@ -0,0 +1,86 @@
+package frames_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestParseFilter(t *testing.T) {
+	ld := frames.Create([2]byte{'L', 'D'}, []byte{0x01, 'a', 'b', 'c'})
+	mt := frames.Create([2]byte{'M', 'T'}, []byte{0x02})
+	corrupted := frames.Recreate(mt)
+	corrupted[len(corrupted)-1] ^= 0xff
+
+	tests := []struct {
+		expr string
+		want []bool // for ld, mt, corrupted and a too short frame
+	}{
+		{`header == "LD" && len > 3 && data[0] == 0x01`, []bool{true, false, false, false}},
+		{`header != "LD"`, []bool{false, true, true, false}},
+		{`len <= 1 || data[3] == 'c'`, nil},
+		{`data[3] == 0x63`, []bool{true, false, false, false}},
+		{`!(data[3] == 0x63)`, []bool{false, true, true, false}},
+		{`valid`, []bool{true, true, false, false}},
+		{`!valid || len >= 4`, []bool{true, false, true, false}},
+		{`data[0] < 2 || header == "XX" && len == 1`, []bool{true, false, false, false}},
+		{`(data[0] < 2 || header == "MT") && len == 1`, []bool{false, true, true, false}},
+		{`len == data[0]`, []bool{false, false, false, false}},
+		{`len == 1 && data[0] == 2`, []bool{false, true, true, false}},
+	}
+
+	for _, tc := range tests {
+		filter, err := frames.ParseFilter(tc.expr)
+		if tc.want == nil {
+			var filterErr *frames.FilterError
+			if !errors.As(err, &filterErr) {
+				t.Errorf("%s: got error %v, want *FilterError", tc.expr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+
+		for i, frame := range []frames.Frame{ld, mt, corrupted, {'L', 'D'}} {
+			if got := filter(frame); got != tc.want[i] {
+				t.Errorf("%s: got %t for frame % x, want %t", tc.expr, got, []byte(frame), tc.want[i])
+			}
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		offset int
+	}{
+		{`header == 1`, 10},
+		{`len == "LD"`, 7},
+		{`header < "LD"`, 7},
+		{`len`, 3},
+		{`len == 1 &&`, 11},
+		{`(len == 1`, 9},
+		{`len == 1)`, 8},
+		{`header == "LD`, 10},
+		{`data[x] == 1`, 5},
+		{`len == 1 $`, 9},
+		{`size > 1`, 0},
+	}
+
+	for _, tc := range tests {
+		_, err := frames.ParseFilter(tc.expr)
+
+		var filterErr *frames.FilterError
+		if !errors.As(err, &filterErr) {
+			t.Errorf("%s: got error %v, want *FilterError", tc.expr, err)
+			continue
+		}
+
+		if filterErr.Offset != tc.offset {
+			t.Errorf("%s: got error %v, want it at offset %d", tc.expr, err, tc.offset)
+		}
+	}
+}
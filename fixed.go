@@ -0,0 +1,95 @@
+package frames
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// QFormat is a signed fixed-point number format: a Bits-bit two's complement
+// integer whose lowest Frac bits are the fractional part, i.e. the value is
+// the integer divided by 2^Frac. Bits must be 8, 16, 32 or 64.
+type QFormat struct {
+	Bits int
+	Frac int
+}
+
+// Common Q formats.
+var (
+	Q8_8   = QFormat{Bits: 16, Frac: 8}
+	Q1_15  = QFormat{Bits: 16, Frac: 15}
+	Q16_16 = QFormat{Bits: 32, Frac: 16}
+)
+
+// Size returns the number of bytes taken by a number in format q.
+func (q QFormat) Size() int {
+	return q.Bits / 8
+}
+
+// Encode returns the integer representing f in format q, rounded to the
+// nearest representable value. Values out of range saturate at the smallest
+// or largest representable value, NaN becomes zero.
+func (q QFormat) Encode(f float64) int64 {
+	if math.IsNaN(f) {
+		return 0
+	}
+
+	maxInt := int64(1)<<(q.Bits-1) - 1
+	minInt := -maxInt - 1
+	scaled := math.Round(math.Ldexp(f, q.Frac))
+	if scaled >= float64(maxInt) {
+		return maxInt
+	}
+	if scaled <= float64(minInt) {
+		return minInt
+	}
+
+	return int64(scaled)
+}
+
+// Decode returns the value represented by n in format q.
+func (q QFormat) Decode(n int64) float64 {
+	return math.Ldexp(float64(n), -q.Frac)
+}
+
+// Put encodes f in format q into the first Size bytes of b in order.
+func (q QFormat) Put(b []byte, f float64, order binary.ByteOrder) {
+	n := q.Encode(f)
+	switch q.Bits {
+	case 8:
+		b[0] = byte(n)
+	case 16:
+		order.PutUint16(b, uint16(n))
+	case 32:
+		order.PutUint32(b, uint32(n))
+	case 64:
+		order.PutUint64(b, uint64(n))
+	}
+}
+
+// Get decodes a number in format q from the first Size bytes of b in order.
+func (q QFormat) Get(b []byte, order binary.ByteOrder) float64 {
+	var n int64
+	switch q.Bits {
+	case 8:
+		n = int64(int8(b[0]))
+	case 16:
+		n = int64(int16(order.Uint16(b)))
+	case 32:
+		n = int64(int32(order.Uint32(b)))
+	case 64:
+		n = int64(order.Uint64(b))
+	}
+
+	return q.Decode(n)
+}
+
+// Resolution returns the difference between consecutive values in format q.
+func (q QFormat) Resolution() float64 {
+	return math.Ldexp(1, -q.Frac)
+}
+
+// String returns q in the usual notation, e.g. "Q8.8".
+func (q QFormat) String() string {
+	return fmt.Sprintf("Q%d.%d", q.Bits-q.Frac, q.Frac)
+}
@@ -0,0 +1,84 @@
+package frames_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestQFormat(t *testing.T) {
+	tests := []struct {
+		format frames.QFormat
+		value  float64
+		n      int64
+	}{
+		{frames.Q8_8, 1.5, 0x0180},
+		{frames.Q8_8, -1, -0x0100},
+		{frames.Q8_8, 0.001, 0},
+		{frames.Q8_8, 0.003, 1},
+		{frames.Q8_8, 1000, math.MaxInt16},
+		{frames.Q8_8, -1000, math.MinInt16},
+		{frames.Q8_8, math.NaN(), 0},
+		{frames.Q1_15, 0.5, 0x4000},
+		{frames.Q1_15, 1, math.MaxInt16},
+		{frames.Q16_16, -273.15, -17901158},
+		{frames.QFormat{Bits: 8, Frac: 4}, 2.25, 0x24},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.Encode(tt.value); got != tt.n {
+			t.Errorf("%v: encoding %g: got %#x, want %#x", tt.format, tt.value, got, tt.n)
+		}
+	}
+}
+
+func TestQFormatDecode(t *testing.T) {
+	for _, value := range []float64{0, 1.5, -1, -127.99609375, 127.99609375} {
+		if got := frames.Q8_8.Decode(frames.Q8_8.Encode(value)); got != value {
+			t.Errorf("got %g, want %g", got, value)
+		}
+	}
+
+	if got, want := frames.Q16_16.Decode(-17901158), -273.15; math.Abs(got-want) > frames.Q16_16.Resolution()/2 {
+		t.Errorf("got %g, want %g", got, want)
+	}
+}
+
+func TestQFormatPutGet(t *testing.T) {
+	tests := []struct {
+		format frames.QFormat
+		order  binary.ByteOrder
+		value  float64
+		data   []byte
+	}{
+		{frames.Q8_8, binary.BigEndian, -1.5, []byte{0xfe, 0x80}},
+		{frames.Q8_8, binary.LittleEndian, 2.75, []byte{0xc0, 0x02}},
+		{frames.Q16_16, binary.BigEndian, -0.5, []byte{0xff, 0xff, 0x80, 0x00}},
+		{frames.QFormat{Bits: 8, Frac: 4}, binary.BigEndian, -2.25, []byte{0xdc}},
+		{frames.QFormat{Bits: 64, Frac: 32}, binary.LittleEndian, 1.25, []byte{0x00, 0x00, 0x00, 0x40, 0x01, 0x00, 0x00, 0x00}},
+	}
+
+	for _, tt := range tests {
+		data := make([]byte, tt.format.Size())
+		tt.format.Put(data, tt.value, tt.order)
+		if !bytes.Equal(data, tt.data) {
+			t.Errorf("%v: got data % x, want % x", tt.format, data, tt.data)
+		}
+
+		frame := frames.Create([2]byte{'T', 'M'}, data)
+		if got := tt.format.Get(frame.Data(), tt.order); got != tt.value {
+			t.Errorf("%v: got %g, want %g", tt.format, got, tt.value)
+		}
+	}
+}
+
+func TestQFormatString(t *testing.T) {
+	for format, want := range map[frames.QFormat]string{frames.Q8_8: "Q8.8", frames.Q1_15: "Q1.15", frames.Q16_16: "Q16.16"} {
+		if got := format.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
@@ -1,7 +1,14 @@
 // Package frames provides useful functions to deal with data frames.
 package frames
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"strings"
+)
 
 // Frame represents a data frame that can be e.g sent by USART.
 //
@@ -18,8 +25,16 @@ import "fmt"
 // LD5+DDDDD#C
 //
 // XD7+DDDDDDD#C
+//
+// The shortest frame is an empty frame, with no data. Frame's methods must not
+// be called on shorter byte slices, which have no place for a header, length
+// byte and checksum.
 type Frame []byte
 
+// minFrameLen is the length of an empty frame: the header, the length byte,
+// the plus sign, the hash sign and the checksum.
+const minFrameLen = 6
+
 // Header returns frame's header, i.e the first 2 bytes.
 //
 // If the frame is invalid, it may return a slice of any length.
@@ -45,26 +60,160 @@ func (f Frame) Data() []byte {
 	return f[begin:end]
 }
 
+// DataString returns frame's data as a string, e.g. a text command.
+func (f Frame) DataString() string {
+	return string(f.Data())
+}
+
 // Checksum returns frame's simple CRC checksum, i.e the last byte.
 func (f Frame) Checksum() byte {
 	return f[len(f)-1]
 }
 
+// Hash returns the 64-bit FNV-1a hash of frame's header and data. Frames with
+// equal headers and data have equal hashes.
+func (f Frame) Hash() uint64 {
+	h := fnv.New64a()
+	h.Write(f.Header())
+	h.Write(f.Data())
+	return h.Sum64()
+}
+
+// WriteTo writes the frame to w with a single call to w.Write. It implements
+// io.WriterTo.
+func (f Frame) WriteTo(w io.Writer) (n int64, err error) {
+	m, err := w.Write(f)
+	return int64(m), err
+}
+
+// ReadFrameFrom reads a single frame from r, which must start exactly at the
+// frame's first byte. The number of bytes read is determined by the length
+// byte. If the frame is invalid, it is returned together with a *VerifyError,
+// see Validate. If r ends before the frame is complete, io.ErrUnexpectedEOF is
+// returned, or io.EOF if r ends before the frame starts.
+func ReadFrameFrom(r io.Reader) (Frame, error) {
+	prefix := make([]byte, 4)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, err
+	}
+
+	frame := make(Frame, len(prefix)+int(prefix[2])+2)
+	copy(frame, prefix)
+	if _, err := io.ReadFull(r, frame[len(prefix):]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return frame, Validate(frame)
+}
+
 // Create creates a new frame. The frame starts with header and contains data.
 // Create also calculates the checksum using CalculateChecksum. Data length must
 // not overflow byte.
 func Create(header [2]byte, data []byte) (frame Frame) {
-	frame = make(Frame, len(header)+1+1+len(data)+2)
+	frame, dst := newFrame(header, len(data))
+	copy(dst, data)
+	frame[len(frame)-1] = CalculateChecksum(frame)
+
+	return
+}
+
+// newFrame returns a new frame starting with header and having room for n
+// bytes of data, together with the slice of its data. The caller fills the
+// data and then sets the checksum.
+func newFrame(header [2]byte, n int) (frame Frame, data []byte) {
+	frame = make(Frame, len(header)+1+1+n+2)
 	copy(frame[:2], header[:])
-	frame[len(header)] = byte(len(data))
+	frame[len(header)] = byte(n)
 	frame[len(header)+1] = '+'
-	copy(frame[len(header)+2:len(frame)-2], data)
 	frame[len(frame)-2] = '#'
+
+	return frame, frame[len(header)+2 : len(frame)-2]
+}
+
+// CreateFromSegments creates a new frame, just like Create, whose data is the
+// concatenation of segments. The segments are copied directly into the frame,
+// so they need not be joined first. Total data length must not overflow byte.
+func CreateFromSegments(header [2]byte, segments ...[]byte) (frame Frame) {
+	length := 0
+	for _, segment := range segments {
+		length += len(segment)
+	}
+
+	frame, data := newFrame(header, length)
+	offset := 0
+	for _, segment := range segments {
+		offset += copy(data[offset:], segment)
+	}
+	frame[len(frame)-1] = CalculateChecksum(frame)
+
+	return
+}
+
+// ErrDataLength is returned when a requested data length does not fit in the
+// length byte.
+var ErrDataLength = errors.New("frames: data length out of range")
+
+// CreateFromReader creates a new frame, just like Create, whose data are
+// exactly n bytes read from r. The bytes are read directly into the frame. If r
+// ends before n bytes are read, CreateFromReader returns io.ErrUnexpectedEOF,
+// or io.EOF if no bytes were read.
+func CreateFromReader(header [2]byte, r io.Reader, n int) (Frame, error) {
+	if n < 0 || n > math.MaxUint8 {
+		return nil, ErrDataLength
+	}
+
+	frame, data := newFrame(header, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	frame[len(frame)-1] = CalculateChecksum(frame)
+
+	return frame, nil
+}
+
+// CreateString creates a new frame, just like Create, whose data is s. Length
+// of s must not overflow byte.
+func CreateString(header [2]byte, s string) (frame Frame) {
+	frame, data := newFrame(header, len(s))
+	copy(data, s)
 	frame[len(frame)-1] = CalculateChecksum(frame)
 
 	return
 }
 
+// ErrNotPrintable is returned when text expected to be printable contains
+// other characters.
+var ErrNotPrintable = errors.New("frames: text not printable")
+
+// CreatePrintable creates a new frame, just like CreateString, checking first
+// that s is printable, see Printable, and that its length fits in the length
+// byte.
+func CreatePrintable(header [2]byte, s string) (Frame, error) {
+	if len(s) > math.MaxUint8 {
+		return nil, ErrDataLength
+	}
+	if !Printable([]byte(s)) {
+		return nil, ErrNotPrintable
+	}
+
+	return CreateString(header, s), nil
+}
+
+// Printable reports whether text consists of printable ASCII characters only,
+// i.e. from space (0x20) to tilde (0x7e).
+func Printable(text []byte) bool {
+	for _, b := range text {
+		if b < ' ' || b > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Recreate creates a new frame from already available byte buffer. It does not
 // check whether buf represents a correct frame. To check if the newly created
 // frame is correct, use Verify function. Data length must not overflow byte.
@@ -104,41 +253,48 @@ func Assemble(header [2]byte, length byte, data []byte, checksum byte) (frame Fr
 // - at penultimate position: a hash sign ("#")
 //
 // - at last position: a simple CRC checksum that must be correct
+//
+// To find out why a frame is invalid, use Validate.
 func Verify(frame Frame) bool {
-	if len(frame) < 6 {
-		return false
-	}
+	return Validate(frame) == nil
+}
 
-	first := frame[0]
-	valid1 := (first >= 'A' && first <= 'Z') || (first >= '0' && first <= '9')
-	if !valid1 {
-		return false
-	}
+// Correct tries to repair an invalid frame by flipping a single bit, see
+// Codec.Correct.
+func Correct(frame Frame) (Frame, bool) {
+	return Codec{}.Correct(frame)
+}
 
-	second := frame[1]
-	valid2 := (second >= 'A' && second <= 'Z') || (second >= '0' && second <= '9')
-	if !valid2 {
-		return false
-	}
+// VerifyError describes the first byte that makes a frame invalid.
+type VerifyError struct {
+	// Offset is the index of the offending byte.
+	Offset int
 
-	if frame[2] != byte(frame.LenData()) {
-		return false
-	}
+	// Want describes what was expected at Offset.
+	Want string
 
-	if frame.LenData() != len(frame.Data()) {
-		return false
-	}
+	// Got is the byte found at Offset, or -1 if the frame ended before it.
+	Got int
+}
 
-	if frame[3] != '+' {
-		return false
+func (e *VerifyError) Error() string {
+	got := "end of frame"
+	if e.Got >= 0 {
+		got = fmt.Sprintf("%#02x", e.Got)
 	}
 
-	if frame[len(frame)-2] != '#' {
-		return false
-	}
+	return fmt.Sprintf("frames: offset %d: want %s, got %s", e.Offset, e.Want, got)
+}
 
-	checksum := CalculateChecksum(frame)
-	return checksum == frame.Checksum()
+// Validate checks whether the frame is valid, just like Verify does. If the
+// frame is invalid, it returns a *VerifyError describing the offending byte.
+func Validate(frame Frame) error {
+	return Codec{}.Validate(frame)
+}
+
+// validHeaderByte reports whether b may appear in a frame's header.
+func validHeaderByte(b byte) bool {
+	return (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
 }
 
 // CalculateChecksum calculates the simple CRC checksum of frame.
@@ -164,3 +320,61 @@ func (f Frame) String() string {
 func DescribeByte(b byte) string {
 	return fmt.Sprintf("byte(bin: %08b, dec: %3d, hex: %02x, ASCII: %+q)", b, b, b, b)
 }
+
+// DescribeBytes returns a hex dump of buf in the format of "hexdump -C": every
+// line shows an offset, 16 bytes in hex and the same bytes in ASCII. The last
+// line contains the length of buf.
+//
+// Valid frames found in buf are marked with an additional line, placed before
+// the line on which the frame starts, with the frame's offset and length.
+func DescribeBytes(buf []byte) string {
+	var sb strings.Builder
+
+	boundaries := findFrames(buf)
+	for offset := 0; offset < len(buf); offset += 16 {
+		line := buf[offset:]
+		if len(line) > 16 {
+			line = line[:16]
+		}
+
+		for len(boundaries) > 0 && boundaries[0][0] < offset+16 {
+			begin, end := boundaries[0][0], boundaries[0][1]
+			fmt.Fprintf(&sb, "-------- frame at %08x, %d bytes: %s\n", begin, end-begin, Frame(buf[begin:end]))
+			boundaries = boundaries[1:]
+		}
+
+		fmt.Fprintf(&sb, "%08x ", offset)
+		for i := 0; i < 16; i++ {
+			if i == 8 {
+				sb.WriteByte(' ')
+			}
+
+			if i < len(line) {
+				fmt.Fprintf(&sb, " %02x", line[i])
+			} else {
+				sb.WriteString("   ")
+			}
+		}
+		fmt.Fprintf(&sb, "  |%s|\n", printableASCII(line))
+	}
+	fmt.Fprintf(&sb, "%08x\n", len(buf))
+
+	return sb.String()
+}
+
+// findFrames returns the beginning and the end of every valid frame in buf.
+// Frames are searched from the start of buf and do not overlap.
+func findFrames(buf []byte) (boundaries [][2]int) {
+	for i := 0; i+minFrameLen <= len(buf); {
+		end := i + minFrameLen + int(buf[i+2])
+		if end <= len(buf) && Verify(buf[i:end]) {
+			boundaries = append(boundaries, [2]int{i, end})
+			i = end
+			continue
+		}
+
+		i++
+	}
+
+	return
+}
@@ -2,7 +2,9 @@ package frames_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"testing"
 
@@ -82,6 +84,97 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestCreateFromSegments(t *testing.T) {
+	for i, tc := range testCases {
+		testName := fmt.Sprintf("test %d", i)
+		t.Run(testName, func(t *testing.T) {
+			half := len(tc.inputData) / 2
+			gotFrame := frames.CreateFromSegments(tc.inputHeader, tc.inputData[:half], nil, tc.inputData[half:])
+
+			if !bytes.Equal(gotFrame, tc.frame) {
+				t.Errorf("got frame % x, want frame % x", []byte(gotFrame), tc.frame)
+			}
+		})
+	}
+}
+
+func TestCreateFromReader(t *testing.T) {
+	for i, tc := range testCases {
+		testName := fmt.Sprintf("test %d", i)
+		t.Run(testName, func(t *testing.T) {
+			r := bytes.NewReader(append(tc.inputData, "rest"...))
+			gotFrame, err := frames.CreateFromReader(tc.inputHeader, r, len(tc.inputData))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(gotFrame, tc.frame) {
+				t.Errorf("got frame % x, want frame % x", []byte(gotFrame), tc.frame)
+			}
+
+			if r.Len() != len("rest") {
+				t.Errorf("got %d bytes left in reader, want %d", r.Len(), len("rest"))
+			}
+		})
+	}
+}
+
+func TestCreateFromReaderErrors(t *testing.T) {
+	header := [2]byte{'L', 'D'}
+
+	if _, err := frames.CreateFromReader(header, bytes.NewReader([]byte("abc")), 4); err != io.ErrUnexpectedEOF {
+		t.Errorf("got error %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	if _, err := frames.CreateFromReader(header, bytes.NewReader(nil), 4); err != io.EOF {
+		t.Errorf("got error %v, want %v", err, io.EOF)
+	}
+
+	if _, err := frames.CreateFromReader(header, bytes.NewReader(nil), 256); err != frames.ErrDataLength {
+		t.Errorf("got error %v, want %v", err, frames.ErrDataLength)
+	}
+}
+
+func TestCreateString(t *testing.T) {
+	for i, tc := range testCases {
+		testName := fmt.Sprintf("test %d", i)
+		t.Run(testName, func(t *testing.T) {
+			gotFrame := frames.CreateString(tc.inputHeader, string(tc.inputData))
+
+			if !bytes.Equal(gotFrame, tc.frame) {
+				t.Errorf("got frame % x, want frame % x", []byte(gotFrame), tc.frame)
+			}
+
+			if gotFrame.DataString() != string(tc.inputData) {
+				t.Errorf("got data %q, want data %q", gotFrame.DataString(), tc.inputData)
+			}
+
+			printable, err := frames.CreatePrintable(tc.inputHeader, string(tc.inputData))
+			if err != nil || !bytes.Equal(printable, tc.frame) {
+				t.Errorf("got frame % x, %v, want frame % x, nil", []byte(printable), err, tc.frame)
+			}
+		})
+	}
+}
+
+func TestCreatePrintableErrors(t *testing.T) {
+	header := [2]byte{'C', 'M'}
+
+	for _, s := range []string{"reset\r\n", "tab\there", "\x7f", "zażółć"} {
+		if _, err := frames.CreatePrintable(header, s); err != frames.ErrNotPrintable {
+			t.Errorf("text %q: got error %v, want %v", s, err, frames.ErrNotPrintable)
+		}
+	}
+
+	if _, err := frames.CreatePrintable(header, string(make([]byte, 256))); err != frames.ErrDataLength {
+		t.Errorf("got error %v, want %v", err, frames.ErrDataLength)
+	}
+
+	if !frames.Printable([]byte(" ~")) {
+		t.Errorf("text %q not printable", " ~")
+	}
+}
+
 func TestAssemble(t *testing.T) {
 	for i, tc := range testCases {
 		testName := fmt.Sprintf("test %d", i)
@@ -175,6 +268,64 @@ func TestVerify(t *testing.T) {
 	}
 }
 
+func TestValidate(t *testing.T) {
+	validateTestCases := []struct {
+		frame []byte
+		err   string
+	}{
+		{
+			frame: []byte{'L', 'D', 0x1, '+', 'A', '#', 0x40},
+			err:   "",
+		},
+		{
+			frame: []byte{'x', 'd'},
+			err:   "frames: offset 2: want at least 6 bytes, got end of frame",
+		},
+		{
+			frame: []byte{'L', 'd', 0x1, '+', 'A', '#', 0x60},
+			err:   "frames: offset 1: want uppercase ASCII letter or digit, got 0x64",
+		},
+		{
+			frame: []byte{'M', 'T', 0x6, '+', 'd', 'o', 'n', 'd', 'u', '#', 0x63},
+			err:   "frames: offset 2: want length 0x05, got 0x06",
+		},
+		{
+			frame: []byte{'L', 'D', 0x1, 'A', 'A', '#', 0x2a},
+			err:   "frames: offset 3: want '+', got 0x41",
+		},
+		{
+			frame: []byte{'L', 'D', 0x1, '+', 'A', '+', 0x48},
+			err:   "frames: offset 5: want '#', got 0x2b",
+		},
+		{
+			frame: []byte{'L', 'D', 0x1, '+', 'A', '#', 0x00},
+			err:   "frames: offset 6: want checksum 0x40, got 0x00",
+		},
+	}
+
+	for i, tc := range validateTestCases {
+		testName := fmt.Sprintf("test %d", i)
+		t.Run(testName, func(t *testing.T) {
+			err := frames.Validate(tc.frame)
+			if tc.err == "" {
+				if err != nil {
+					t.Errorf("got error %q, want no error", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("got error %v, want error %q", err, tc.err)
+			}
+
+			var verifyErr *frames.VerifyError
+			if !errors.As(err, &verifyErr) {
+				t.Errorf("got error of type %T, want *frames.VerifyError", err)
+			}
+		})
+	}
+}
+
 func TestRecreate(t *testing.T) {
 	for i, tc := range testCases {
 		testName := fmt.Sprintf("test %d", i)
@@ -189,6 +340,85 @@ func TestRecreate(t *testing.T) {
 	}
 }
 
+func TestWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	for i, tc := range testCases {
+		n, err := frames.Frame(tc.frame).WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != int64(len(tc.frame)) {
+			t.Errorf("test %d: got %d bytes written, want %d", i, n, len(tc.frame))
+		}
+	}
+
+	for i, tc := range testCases {
+		frame, err := frames.ReadFrameFrom(&buf)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+
+		if !bytes.Equal(frame, tc.frame) {
+			t.Errorf("test %d: got frame % x, want frame % x", i, []byte(frame), tc.frame)
+		}
+	}
+
+	if _, err := frames.ReadFrameFrom(&buf); err != io.EOF {
+		t.Errorf("got error %v, want %v", err, io.EOF)
+	}
+}
+
+func TestReadFrameFromErrors(t *testing.T) {
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("test"))
+
+	if _, err := frames.ReadFrameFrom(bytes.NewReader(frame[:6])); err != io.ErrUnexpectedEOF {
+		t.Errorf("got error %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	corrupted := frames.Recreate(frame)
+	corrupted[len(corrupted)-1] ^= 0xff
+
+	got, err := frames.ReadFrameFrom(bytes.NewReader(corrupted))
+	var verifyErr *frames.VerifyError
+	if !errors.As(err, &verifyErr) || !bytes.Equal(got, corrupted) {
+		t.Errorf("got frame % x and error %v, want the frame and *VerifyError", []byte(got), err)
+	}
+}
+
+func TestHash(t *testing.T) {
+	for i, tc := range testCases {
+		testName := fmt.Sprintf("test %d", i)
+		t.Run(testName, func(t *testing.T) {
+			frame := frames.Create(tc.inputHeader, tc.inputData)
+			if frame.Hash() != frames.Frame(tc.frame).Hash() {
+				t.Errorf("equal frames have different hashes")
+			}
+
+			for j, other := range testCases {
+				if j != i && frame.Hash() == frames.Frame(other.frame).Hash() {
+					t.Errorf("frames %d and %d have equal hashes", i, j)
+				}
+			}
+		})
+	}
+}
+
+func TestDescribeBytes(t *testing.T) {
+	input := []byte("xdLD\x05+dondu#\x71MT\x01+A#\x51garbage")
+
+	want := "" +
+		"-------- frame at 00000002, 11 bytes: LD+646f6e6475#71\n" +
+		"-------- frame at 0000000d, 7 bytes: MT+41#51\n" +
+		"00000000  78 64 4c 44 05 2b 64 6f  6e 64 75 23 71 4d 54 01  |xdLD.+dondu#qMT.|\n" +
+		"00000010  2b 41 23 51 67 61 72 62  61 67 65                 |+A#Qgarbage|\n" +
+		"0000001b\n"
+
+	if got := frames.DescribeBytes(input); got != want {
+		t.Errorf("got dump\n%s\nwant dump\n%s", got, want)
+	}
+}
+
 func FuzzCreate(f *testing.F) {
 	for _, tc := range testCases {
 		f.Add(tc.inputData)
@@ -0,0 +1,53 @@
+// Package framestest provides helpers for testing code that produces frames.
+package framestest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+// AssertEqualFrames reports an error if got differs from want. The message
+// shows both frames and lists every differing byte, as found by
+// frames.DiffFrames, e.g.
+//
+//	frames differ:
+//	- want: 4c 44 04 2b 74 65 73 74 23 12
+//	+ got:  4c 44 04 2b 74 65 78 74 23 19
+//	  data offset 2: 0x73 != 0x78
+//	  checksum offset 0: 0x12 != 0x19
+//
+// It reports whether the frames are equal.
+func AssertEqualFrames(t testing.TB, want, got frames.Frame) bool {
+	t.Helper()
+
+	diffs := frames.DiffFrames(want, got)
+	if diffs == nil {
+		return true
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "frames differ:\n- want: % x\n+ got:  % x", []byte(want), []byte(got))
+	for _, diff := range diffs {
+		fmt.Fprintf(&sb, "\n  %s", diff)
+	}
+	t.Errorf("%s", sb.String())
+
+	return false
+}
+
+// AssertValid reports an error if frame is invalid. The message shows the
+// frame and describes the offending byte, as found by frames.Validate. It
+// reports whether the frame is valid.
+func AssertValid(t testing.TB, frame frames.Frame) bool {
+	t.Helper()
+
+	if err := frames.Validate(frame); err != nil {
+		t.Errorf("invalid frame % x: %v", []byte(frame), err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,55 @@
+package framestest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+	"github.com/knei-knurow/frames/framestest"
+)
+
+// recorder records errors reported by assertions instead of failing the test.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestAssertEqualFrames(t *testing.T) {
+	want := frames.Create([2]byte{'L', 'D'}, []byte("test"))
+
+	r := &recorder{}
+	if !framestest.AssertEqualFrames(r, want, frames.Recreate(want)) || len(r.errs) != 0 {
+		t.Errorf("got errors %q for equal frames", r.errs)
+	}
+
+	got := frames.Create([2]byte{'L', 'D'}, []byte("text"))
+	wantErr := "frames differ:\n" +
+		"- want: 4c 44 04 2b 74 65 73 74 23 12\n" +
+		"+ got:  4c 44 04 2b 74 65 78 74 23 19\n" +
+		"  data offset 2: 0x73 != 0x78\n" +
+		"  checksum offset 0: 0x12 != 0x19"
+
+	if framestest.AssertEqualFrames(r, want, got) || len(r.errs) != 1 || r.errs[0] != wantErr {
+		t.Errorf("got errors %q, want %q", r.errs, wantErr)
+	}
+}
+
+func TestAssertValid(t *testing.T) {
+	r := &recorder{}
+	if !framestest.AssertValid(r, frames.Create([2]byte{'L', 'D'}, []byte("test"))) || len(r.errs) != 0 {
+		t.Errorf("got errors %q for a valid frame", r.errs)
+	}
+
+	invalid := frames.Frame{'L', 'D', 0x1, '+', 'A', '#', 0x00}
+	wantErr := "invalid frame 4c 44 01 2b 41 23 00: frames: offset 6: want checksum 0x40, got 0x00"
+
+	if framestest.AssertValid(r, invalid) || len(r.errs) != 1 || r.errs[0] != wantErr {
+		t.Errorf("got errors %q, want %q", r.errs, wantErr)
+	}
+}
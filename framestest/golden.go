@@ -0,0 +1,82 @@
+package framestest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+// update is namespaced, so that it does not clash with -update flags of the
+// test packages importing framestest.
+var update = flag.Bool("framestest.update", false, "update golden files of framestest.Golden")
+
+// Golden compares got with the frames stored in the golden file
+// testdata/name.golden, reporting an error listing the differing lines if they
+// differ. Frames are stored one per line in the format of frames.Canonical, so
+// changes to golden files are easy to review.
+//
+// If the test binary is run with the -framestest.update flag, e.g.
+// "go test -framestest.update", Golden writes got to the golden file instead.
+// It reports whether got matches the golden file.
+func Golden(t testing.TB, name string, got []frames.Frame) bool {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+
+	var sb strings.Builder
+	for _, frame := range got {
+		sb.WriteString(frames.Canonical(frame))
+		sb.WriteByte('\n')
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("updating golden file: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+			t.Fatalf("updating golden file: %v", err)
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("reading golden file: %v (run with -framestest.update to create it)", err)
+		return false
+	}
+
+	wantLines := strings.Split(strings.TrimSuffix(string(want), "\n"), "\n")
+	gotLines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+
+	n := len(wantLines)
+	if len(gotLines) > n {
+		n = len(gotLines)
+	}
+
+	var diff strings.Builder
+	for i := 0; i < n; i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+
+		if w != g {
+			fmt.Fprintf(&diff, "\nline %d:\n- %s\n+ %s", i+1, w, g)
+		}
+	}
+
+	if diff.Len() > 0 {
+		t.Errorf("frames differ from golden file %s:%s", path, diff.String())
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,57 @@
+package framestest_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+	"github.com/knei-knurow/frames/framestest"
+)
+
+func TestGolden(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	captured := []frames.Frame{
+		frames.Create([2]byte{'L', 'D'}, []byte("test")),
+		frames.Create([2]byte{'M', 'T'}, []byte("dondu")),
+	}
+
+	r := &recorder{}
+	if framestest.Golden(r, "capture", captured) || len(r.errs) != 1 {
+		t.Errorf("got errors %q, want a missing golden file", r.errs)
+	}
+
+	flag.Set("framestest.update", "true")
+	framestest.Golden(t, "capture", captured)
+	flag.Set("framestest.update", "false")
+
+	want := "checksum=12 data=74657374 header=\"LD\" length=4 valid=true\n" +
+		"checksum=60 data=646f6e6475 header=\"MT\" length=5 valid=true\n"
+	if golden, err := os.ReadFile(filepath.Join("testdata", "capture.golden")); err != nil || string(golden) != want {
+		t.Fatalf("got golden file %q and error %v, want %q", golden, err, want)
+	}
+
+	r = &recorder{}
+	if !framestest.Golden(r, "capture", captured) || len(r.errs) != 0 {
+		t.Errorf("got errors %q for matching frames", r.errs)
+	}
+
+	changed := []frames.Frame{captured[0], frames.Create([2]byte{'M', 'T'}, []byte("donda"))}
+	wantErr := "frames differ from golden file testdata/capture.golden:\n" +
+		"line 2:\n" +
+		"- checksum=60 data=646f6e6475 header=\"MT\" length=5 valid=true\n" +
+		"+ checksum=74 data=646f6e6461 header=\"MT\" length=5 valid=true"
+	if framestest.Golden(r, "capture", changed) || len(r.errs) != 1 || r.errs[0] != wantErr {
+		t.Errorf("got errors %q, want %q", r.errs, wantErr)
+	}
+}
@@ -0,0 +1,34 @@
+package frames
+
+// HeaderMap maps frame headers to the headers replacing them, e.g. to let two
+// devices with conflicting header conventions share a link.
+type HeaderMap map[[2]byte][2]byte
+
+// Rewrite returns frame with its header replaced according to m, and its
+// checksum recalculated. Frames with headers missing from m and frames shorter
+// than an empty frame are returned unchanged.
+func (m HeaderMap) Rewrite(frame Frame) Frame {
+	if len(frame) < minFrameLen {
+		return frame
+	}
+
+	var header [2]byte
+	copy(header[:], frame.Header())
+
+	to, ok := m[header]
+	if !ok {
+		return frame
+	}
+
+	return rewriteHeader(frame, to)
+}
+
+// rewriteHeader returns a copy of frame with header replaced and the checksum
+// recalculated.
+func rewriteHeader(frame Frame, header [2]byte) Frame {
+	frame = Recreate(frame)
+	copy(frame, header[:])
+	frame[len(frame)-1] = CalculateChecksum(frame)
+
+	return frame
+}
@@ -0,0 +1,66 @@
+package frames_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestHeaderMapRewrite(t *testing.T) {
+	m := frames.HeaderMap{{'L', 'D'}: {'L', '2'}}
+
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("on"))
+	got := m.Rewrite(frame)
+	if want := frames.Create([2]byte{'L', '2'}, []byte("on")); !bytes.Equal(got, want) {
+		t.Errorf("got frame % x, want frame % x", []byte(got), []byte(want))
+	}
+	if string(frame.Header()) != "LD" {
+		t.Errorf("original frame modified to % x", []byte(frame))
+	}
+
+	other := frames.Create([2]byte{'M', 'T'}, []byte{0x10})
+	if got := m.Rewrite(other); !bytes.Equal(got, other) {
+		t.Errorf("got frame % x, want unchanged frame % x", []byte(got), []byte(other))
+	}
+}
+
+func TestBridgeGateway(t *testing.T) {
+	deviceA, bridgeA := net.Pipe()
+	deviceB, bridgeB := net.Pipe()
+
+	// both devices call their lamps LD, B knows A's lamp as L2
+	bridge := frames.NewBridge(bridgeA, bridgeB, frames.BridgeConfig{
+		RewriteAB: frames.HeaderMap{{'L', 'D'}: {'L', '2'}}.Rewrite,
+		RewriteBA: frames.HeaderMap{{'L', '2'}: {'L', 'D'}}.Rewrite,
+	})
+	done := make(chan error)
+	go func() { done <- bridge.Run() }()
+
+	go deviceA.Write(frames.Create([2]byte{'L', 'D'}, []byte("on")))
+	want := frames.Create([2]byte{'L', '2'}, []byte("on"))
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(deviceB, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x from A, want % x", got, []byte(want))
+	}
+
+	go deviceB.Write(frames.Create([2]byte{'L', '2'}, []byte("off")))
+	want = frames.Create([2]byte{'L', 'D'}, []byte("off"))
+	got = make([]byte, len(want))
+	if _, err := io.ReadFull(deviceA, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x from B, want % x", got, []byte(want))
+	}
+
+	deviceB.Close()
+	if err := <-done; err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+}
@@ -0,0 +1,189 @@
+package frames
+
+import (
+	"errors"
+	"io"
+)
+
+// CapabilitiesHeader is the header of frames exchanged by Handshake.
+var CapabilitiesHeader = [2]byte{'C', 'P'}
+
+var (
+	// ErrCapabilities is returned when a capabilities frame is malformed.
+	ErrCapabilities = errors.New("frames: malformed capabilities frame")
+
+	// ErrIncompatible is returned by Handshake when the two sides of a link
+	// have no checksum algorithm in common.
+	ErrIncompatible = errors.New("frames: incompatible peer")
+)
+
+// Features is a bitmask of optional features of a link.
+type Features uint16
+
+// Features that can be advertised in Capabilities.
+const (
+	FeatureCompression Features = 1 << iota
+	FeatureEncryption
+	FeatureLength16
+)
+
+// Has reports whether all features set in feature are set in f.
+func (f Features) Has(feature Features) bool {
+	return f&feature == feature
+}
+
+// Capabilities describes what one side of a link supports.
+type Capabilities struct {
+	// Version is the protocol version.
+	Version byte
+
+	// MaxFrameSize is the length of the longest frame that can be received.
+	MaxFrameSize uint16
+
+	// Features holds supported optional features.
+	Features Features
+
+	// Checksums lists supported checksum algorithms.
+	Checksums []ChecksumAlgorithm
+}
+
+// Negotiation is the outcome of a Handshake.
+type Negotiation struct {
+	// Codec is the codec both sides should use from now on.
+	Codec Codec
+
+	// Version is the lower of both sides' protocol versions.
+	Version byte
+
+	// MaxFrameSize is the lower of both sides' maximum frame sizes.
+	MaxFrameSize uint16
+
+	// Features holds the features supported by both sides.
+	Features Features
+
+	// Peer holds the capabilities advertised by the other side.
+	Peer Capabilities
+}
+
+// Supports reports whether feature is supported by both sides.
+func (n Negotiation) Supports(feature Features) bool {
+	return n.Features.Has(feature)
+}
+
+// Frame returns the capabilities frame advertising c. Its data holds the
+// version, the maximum frame size, the features bitmask (both 2 bytes,
+// big-endian) and one byte for every supported checksum algorithm.
+func (c Capabilities) Frame() Frame {
+	data := []byte{
+		c.Version,
+		byte(c.MaxFrameSize >> 8), byte(c.MaxFrameSize),
+		byte(c.Features >> 8), byte(c.Features),
+	}
+	for _, algorithm := range c.Checksums {
+		data = append(data, byte(algorithm))
+	}
+
+	return Create(CapabilitiesHeader, data)
+}
+
+// ParseCapabilities returns the capabilities advertised by frame, which must
+// be a valid capabilities frame.
+func ParseCapabilities(frame Frame) (Capabilities, error) {
+	if !Verify(frame) || string(frame.Header()) != string(CapabilitiesHeader[:]) {
+		return Capabilities{}, ErrCapabilities
+	}
+	if frame.LenData() < 5 {
+		return Capabilities{}, ErrCapabilities
+	}
+
+	data := frame.Data()
+	c := Capabilities{
+		Version:      data[0],
+		MaxFrameSize: uint16(data[1])<<8 | uint16(data[2]),
+		Features:     Features(data[3])<<8 | Features(data[4]),
+	}
+	for _, b := range data[5:] {
+		c.Checksums = append(c.Checksums, ChecksumAlgorithm(b))
+	}
+
+	return c, nil
+}
+
+// Handshake exchanges capabilities frames with the other side of rw and picks
+// the settings both sides support. It is meant to be called by both sides
+// right after connecting, before any other frames are sent.
+//
+// Capabilities frames are always sent in the default format. The negotiated
+// codec uses the strongest checksum algorithm supported by both sides, i.e the
+// one with the highest value, so that both sides pick the same one. Frames
+// other than capabilities frames received before the peer's capabilities are
+// discarded.
+func Handshake(rw io.ReadWriter, local Capabilities) (Negotiation, error) {
+	written := make(chan error, 1)
+	go func() {
+		_, err := rw.Write(local.Frame())
+		written <- err
+	}()
+
+	peer, err := readCapabilities(rw)
+	if err != nil {
+		return Negotiation{}, err
+	}
+
+	if err := <-written; err != nil {
+		return Negotiation{}, err
+	}
+
+	n := Negotiation{
+		Version:      local.Version,
+		MaxFrameSize: local.MaxFrameSize,
+		Features:     local.Features & peer.Features,
+		Peer:         peer,
+	}
+	if peer.Version < n.Version {
+		n.Version = peer.Version
+	}
+	if peer.MaxFrameSize < n.MaxFrameSize {
+		n.MaxFrameSize = peer.MaxFrameSize
+	}
+
+	found := false
+	for _, algorithm := range local.Checksums {
+		for _, peerAlgorithm := range peer.Checksums {
+			if algorithm == peerAlgorithm && (!found || algorithm > n.Codec.ChecksumAlgorithm) {
+				n.Codec = Codec{ChecksumAlgorithm: algorithm}
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		return Negotiation{}, ErrIncompatible
+	}
+
+	return n, nil
+}
+
+// readCapabilities reads from r until a valid capabilities frame is received.
+// It reads a single byte at a time, so that no bytes following the frame are
+// consumed.
+func readCapabilities(r io.Reader) (Capabilities, error) {
+	var buf []byte
+	b := make([]byte, 1)
+	for {
+		if _, err := io.ReadFull(r, b); err != nil {
+			return Capabilities{}, err
+		}
+		buf = append(buf, b[0])
+
+		frame, n, err := ParseFrame(buf)
+		buf = buf[n:]
+		if err != nil {
+			continue
+		}
+
+		if c, err := ParseCapabilities(frame); err == nil {
+			return c, nil
+		}
+	}
+}
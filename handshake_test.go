@@ -0,0 +1,105 @@
+package frames_test
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestCapabilitiesFrame(t *testing.T) {
+	capabilities := frames.Capabilities{
+		Version:      2,
+		MaxFrameSize: 261,
+		Features:     frames.FeatureCompression | frames.FeatureLength16,
+		Checksums:    []frames.ChecksumAlgorithm{frames.ChecksumXMODEM, frames.ChecksumXOR},
+	}
+
+	got, err := frames.ParseCapabilities(capabilities.Frame())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, capabilities) {
+		t.Errorf("got capabilities %+v, want capabilities %+v", got, capabilities)
+	}
+
+	if _, err := frames.ParseCapabilities(frames.Create([2]byte{'L', 'D'}, []byte{1, 2, 3})); !errors.Is(err, frames.ErrCapabilities) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrCapabilities)
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+
+	local := frames.Capabilities{
+		Version:      2,
+		MaxFrameSize: 261,
+		Features:     frames.FeatureCompression | frames.FeatureEncryption,
+		Checksums:    []frames.ChecksumAlgorithm{frames.ChecksumXMODEM, frames.ChecksumXOR},
+	}
+	remote := frames.Capabilities{
+		Version:      1,
+		MaxFrameSize: 64,
+		Features:     frames.FeatureEncryption | frames.FeatureLength16,
+		Checksums:    []frames.ChecksumAlgorithm{frames.ChecksumXOR, frames.ChecksumXMODEM},
+	}
+
+	type result struct {
+		negotiation frames.Negotiation
+		err         error
+	}
+	remoteResult := make(chan result)
+	go func() {
+		n, err := frames.Handshake(b, remote)
+		remoteResult <- result{n, err}
+	}()
+
+	got, err := frames.Handshake(a, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := <-remoteResult
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+
+	// both sides pick the strongest common checksum algorithm
+	if got.Codec.ChecksumAlgorithm != frames.ChecksumXMODEM || r.negotiation.Codec.ChecksumAlgorithm != frames.ChecksumXMODEM {
+		t.Errorf("got checksum algorithms %d and %d, want %d", got.Codec.ChecksumAlgorithm, r.negotiation.Codec.ChecksumAlgorithm, frames.ChecksumXMODEM)
+	}
+
+	if got.Version != 1 || got.MaxFrameSize != 64 {
+		t.Errorf("got version %d and max frame size %d, want 1 and 64", got.Version, got.MaxFrameSize)
+	}
+
+	if !got.Supports(frames.FeatureEncryption) || got.Supports(frames.FeatureCompression) || got.Supports(frames.FeatureLength16) {
+		t.Errorf("got negotiated features %03b, want %03b", got.Features, frames.FeatureEncryption)
+	}
+
+	if !got.Peer.Features.Has(frames.FeatureLength16) {
+		t.Errorf("peer features %03b do not include 16-bit length", got.Peer.Features)
+	}
+
+	if !reflect.DeepEqual(got.Peer, remote) {
+		t.Errorf("got peer capabilities %+v, want %+v", got.Peer, remote)
+	}
+}
+
+func TestHandshakeIncompatible(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+
+	go frames.Handshake(b, frames.Capabilities{Checksums: []frames.ChecksumAlgorithm{frames.ChecksumXMODEM}})
+
+	_, err := frames.Handshake(a, frames.Capabilities{Checksums: []frames.ChecksumAlgorithm{frames.ChecksumXOR}})
+	if !errors.Is(err, frames.ErrIncompatible) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrIncompatible)
+	}
+}
@@ -0,0 +1,135 @@
+package frames
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrIDFormat is returned when an identifier has the wrong length or format.
+var ErrIDFormat = errors.New("frames: invalid ID")
+
+// ID64 is an 8-byte identifier carried in frames' data, e.g. a device ID. Its
+// canonical string form is 16 lowercase hex digits.
+type ID64 [8]byte
+
+// NewID64 returns a random ID64.
+func NewID64() (ID64, error) {
+	var id ID64
+	_, err := rand.Read(id[:])
+	return id, err
+}
+
+// ID64FromBytes returns the identifier stored in the first 8 bytes of b. If b
+// is shorter, it returns ErrIDFormat.
+func ID64FromBytes(b []byte) (ID64, error) {
+	var id ID64
+	if len(b) < len(id) {
+		return ID64{}, ErrIDFormat
+	}
+
+	copy(id[:], b)
+	return id, nil
+}
+
+// ParseID64 parses an identifier in the canonical string form. Uppercase hex
+// digits are accepted too.
+func ParseID64(s string) (ID64, error) {
+	var id ID64
+	if len(s) != hex.EncodedLen(len(id)) {
+		return ID64{}, ErrIDFormat
+	}
+
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return ID64{}, ErrIDFormat
+	}
+
+	return id, nil
+}
+
+// IsZero reports whether id is all zeros, which usually means unset.
+func (id ID64) IsZero() bool {
+	return id == ID64{}
+}
+
+func (id ID64) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// UUID is a 16-byte identifier carried in frames' data, e.g. a transfer ID.
+// Its canonical string form is "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx" with
+// lowercase hex digits, as defined in RFC 4122.
+type UUID [16]byte
+
+// uuidDashes are the offsets of dashes in UUID's canonical string form.
+var uuidDashes = [...]int{8, 13, 18, 23}
+
+// NewUUID returns a random (version 4) UUID.
+func NewUUID() (UUID, error) {
+	var u UUID
+	if _, err := rand.Read(u[:]); err != nil {
+		return UUID{}, err
+	}
+
+	u[6] = u[6]&0x0f | 0x40 // version 4
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant
+
+	return u, nil
+}
+
+// UUIDFromBytes returns the identifier stored in the first 16 bytes of b. If
+// b is shorter, it returns ErrIDFormat.
+func UUIDFromBytes(b []byte) (UUID, error) {
+	var u UUID
+	if len(b) < len(u) {
+		return UUID{}, ErrIDFormat
+	}
+
+	copy(u[:], b)
+	return u, nil
+}
+
+// ParseUUID parses an identifier in the canonical string form. Uppercase hex
+// digits are accepted too.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 {
+		return UUID{}, ErrIDFormat
+	}
+
+	digits := make([]byte, 0, 32)
+	prev := 0
+	for _, dash := range uuidDashes {
+		if s[dash] != '-' {
+			return UUID{}, ErrIDFormat
+		}
+		digits = append(digits, s[prev:dash]...)
+		prev = dash + 1
+	}
+	digits = append(digits, s[prev:]...)
+
+	if _, err := hex.Decode(u[:], digits); err != nil {
+		return UUID{}, ErrIDFormat
+	}
+
+	return u, nil
+}
+
+// IsZero reports whether u is all zeros, which usually means unset.
+func (u UUID) IsZero() bool {
+	return u == UUID{}
+}
+
+func (u UUID) String() string {
+	s := make([]byte, 36)
+	hex.Encode(s[0:8], u[0:4])
+	hex.Encode(s[9:13], u[4:6])
+	hex.Encode(s[14:18], u[6:8])
+	hex.Encode(s[19:23], u[8:10])
+	hex.Encode(s[24:36], u[10:16])
+	for _, dash := range uuidDashes {
+		s[dash] = '-'
+	}
+
+	return string(s)
+}
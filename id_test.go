@@ -0,0 +1,84 @@
+package frames_test
+
+import (
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestID64(t *testing.T) {
+	frame := frames.Create([2]byte{'I', 'D'}, []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0xff})
+
+	id, err := frames.ID64FromBytes(frame.Data())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := id.String(), "0123456789abcdef"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	parsed, err := frames.ParseID64("0123456789ABCDEF")
+	if err != nil || parsed != id {
+		t.Errorf("got %v, %v, want %v, nil", parsed, err, id)
+	}
+
+	for _, s := range []string{"", "0123456789abcde", "0123456789abcdeg", "0x23456789abcdef"} {
+		if _, err := frames.ParseID64(s); err != frames.ErrIDFormat {
+			t.Errorf("%q: got error %v, want %v", s, err, frames.ErrIDFormat)
+		}
+	}
+	if _, err := frames.ID64FromBytes(make([]byte, 7)); err != frames.ErrIDFormat {
+		t.Errorf("got error %v, want %v", err, frames.ErrIDFormat)
+	}
+
+	random, err := frames.NewID64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if random.IsZero() || !(frames.ID64{}).IsZero() {
+		t.Errorf("got IsZero %t for %v, want false", random.IsZero(), random)
+	}
+}
+
+func TestUUID(t *testing.T) {
+	const s = "123e4567-e89b-42d3-a456-426614174000"
+
+	u, err := frames.ParseUUID("123E4567-E89B-42D3-A456-426614174000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.String(); got != s {
+		t.Errorf("got %q, want %q", got, s)
+	}
+
+	frame := frames.Create([2]byte{'T', 'X'}, u[:])
+	if got, err := frames.UUIDFromBytes(frame.Data()); err != nil || got != u {
+		t.Errorf("got %v, %v, want %v, nil", got, err, u)
+	}
+
+	for _, s := range []string{
+		"",
+		"123e4567e89b42d3a456426614174000",
+		"123e4567-e89b-42d3-a456_426614174000",
+		"123e4567-e89b-42d3-a456-42661417400g",
+		"123e4567-e89b-42d3-a456-4266141740000",
+	} {
+		if _, err := frames.ParseUUID(s); err != frames.ErrIDFormat {
+			t.Errorf("%q: got error %v, want %v", s, err, frames.ErrIDFormat)
+		}
+	}
+	if _, err := frames.UUIDFromBytes(make([]byte, 15)); err != frames.ErrIDFormat {
+		t.Errorf("got error %v, want %v", err, frames.ErrIDFormat)
+	}
+
+	random, err := frames.NewUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if random[6]>>4 != 4 || random[8]>>6 != 2 {
+		t.Errorf("got UUID %v, want version 4 and RFC 4122 variant", random)
+	}
+	if random.IsZero() || !(frames.UUID{}).IsZero() {
+		t.Errorf("got IsZero %t for %v, want false", random.IsZero(), random)
+	}
+}
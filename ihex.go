@@ -0,0 +1,174 @@
+package frames
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Intel HEX record types.
+const (
+	ihexData                   = 0x00
+	ihexEndOfFile              = 0x01
+	ihexExtendedSegmentAddress = 0x02
+	ihexExtendedLinearAddress  = 0x04
+)
+
+// ihexRecordSize is the number of data bytes in a single record written by
+// WriteIntelHex.
+const ihexRecordSize = 16
+
+// MaxIntelHexSize is the highest address plus one that ReadIntelHex accepts,
+// so that a small malicious file cannot make it allocate gigabytes. It is far
+// more than any capture written by WriteIntelHex is expected to take.
+const MaxIntelHexSize = 16 << 20
+
+var (
+	// ErrIntelHexFormat is returned by ReadIntelHex when a record is
+	// malformed.
+	ErrIntelHexFormat = errors.New("frames: malformed Intel HEX record")
+
+	// ErrIntelHexChecksum is returned by ReadIntelHex when a record's checksum
+	// is incorrect.
+	ErrIntelHexChecksum = errors.New("frames: incorrect Intel HEX record checksum")
+)
+
+// WriteIntelHex writes frames to w as Intel HEX records. The frames are laid
+// out one after another starting at address 0, split into data records of 16
+// bytes, and followed by an end-of-file record. Extended linear address
+// records are written when the frames do not fit in 64 KiB.
+func WriteIntelHex(w io.Writer, frames []Frame) error {
+	var buf []byte
+	for _, frame := range frames {
+		buf = append(buf, frame...)
+	}
+
+	bw := bufio.NewWriter(w)
+	for offset := 0; offset < len(buf); offset += ihexRecordSize {
+		if offset%0x10000 == 0 && offset > 0 {
+			upper := offset >> 16
+			writeIntelHexRecord(bw, 0, ihexExtendedLinearAddress, []byte{byte(upper >> 8), byte(upper)})
+		}
+
+		end := offset + ihexRecordSize
+		if end > len(buf) {
+			end = len(buf)
+		}
+		writeIntelHexRecord(bw, uint16(offset), ihexData, buf[offset:end])
+	}
+	writeIntelHexRecord(bw, 0, ihexEndOfFile, nil)
+
+	return bw.Flush()
+}
+
+// writeIntelHexRecord writes a single record, terminated with a newline.
+func writeIntelHexRecord(w *bufio.Writer, address uint16, recordType byte, data []byte) {
+	record := make([]byte, 0, 4+len(data)+1)
+	record = append(record, byte(len(data)), byte(address>>8), byte(address), recordType)
+	record = append(record, data...)
+
+	var sum byte
+	for _, b := range record {
+		sum += b
+	}
+	record = append(record, -sum)
+
+	fmt.Fprintf(w, ":%s\n", strings.ToUpper(hex.EncodeToString(record)))
+}
+
+// ReadIntelHex reads Intel HEX records from r and returns the frames stored in
+// them, such as written by WriteIntelHex. Reading stops at the end-of-file
+// record.
+//
+// Bytes are placed at the addresses given by the records, and frames are then
+// parsed from them with ParseFrame. If bytes remain after the last frame,
+// ReadIntelHex returns the frames found together with ErrIncomplete. Records
+// placing bytes at or beyond MaxIntelHexSize are rejected with
+// ErrIntelHexFormat.
+func ReadIntelHex(r io.Reader) ([]Frame, error) {
+	var buf []byte
+	var base int
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		if line[0] != ':' {
+			return nil, ErrIntelHexFormat
+		}
+
+		record := make([]byte, hex.DecodedLen(len(line)-1))
+		if _, err := hex.Decode(record, line[1:]); err != nil {
+			return nil, ErrIntelHexFormat
+		}
+
+		if len(record) < 5 || len(record) != 5+int(record[0]) {
+			return nil, ErrIntelHexFormat
+		}
+
+		var sum byte
+		for _, b := range record {
+			sum += b
+		}
+		if sum != 0 {
+			return nil, ErrIntelHexChecksum
+		}
+
+		data := record[4 : len(record)-1]
+		switch record[3] {
+		case ihexData:
+			address := base + int(record[1])<<8 + int(record[2])
+			end := address + len(data)
+			if end > MaxIntelHexSize {
+				return nil, ErrIntelHexFormat
+			}
+			if end > len(buf) {
+				buf = append(buf, make([]byte, end-len(buf))...)
+			}
+			copy(buf[address:], data)
+		case ihexEndOfFile:
+			return parseFrames(buf)
+		case ihexExtendedSegmentAddress:
+			if len(data) != 2 {
+				return nil, ErrIntelHexFormat
+			}
+			base = (int(data[0])<<8 + int(data[1])) << 4
+		case ihexExtendedLinearAddress:
+			if len(data) != 2 {
+				return nil, ErrIntelHexFormat
+			}
+			base = (int(data[0])<<8 + int(data[1])) << 16
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return parseFrames(buf)
+}
+
+// parseFrames parses all frames from buf with ParseFrame. If bytes remain
+// after the last frame, it returns the frames found together with
+// ErrIncomplete.
+func parseFrames(buf []byte) ([]Frame, error) {
+	var frames []Frame
+	for len(buf) > 0 {
+		frame, n, err := ParseFrame(buf)
+		if err != nil {
+			return frames, err
+		}
+
+		frames = append(frames, frame)
+		buf = buf[n:]
+	}
+
+	return frames, nil
+}
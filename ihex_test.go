@@ -0,0 +1,96 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestWriteIntelHex(t *testing.T) {
+	input := []frames.Frame{
+		frames.Create([2]byte{'L', 'D'}, []byte("dondu")),
+		frames.Create([2]byte{'M', 'T'}, []byte("test")),
+	}
+
+	want := "" +
+		":100000004C44052B646F6E647523714D54042B743E\n" +
+		":05001000657374230379\n" +
+		":00000001FF\n"
+
+	var buf bytes.Buffer
+	if err := frames.WriteIntelHex(&buf, input); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Errorf("got records\n%s\nwant records\n%s", got, want)
+	}
+
+	got, err := frames.ReadIntelHex(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(input) {
+		t.Fatalf("got %d frames, want %d", len(got), len(input))
+	}
+
+	for i := range input {
+		if !bytes.Equal(got[i], input[i]) {
+			t.Errorf("got frame % x, want frame % x", []byte(got[i]), []byte(input[i]))
+		}
+	}
+}
+
+func TestReadIntelHexLarge(t *testing.T) {
+	var input []frames.Frame
+	for i := 0; i < 400; i++ {
+		input = append(input, frames.Create([2]byte{'L', 'D'}, bytes.Repeat([]byte{byte(i)}, 200)))
+	}
+
+	var buf bytes.Buffer
+	if err := frames.WriteIntelHex(&buf, input); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), ":020000040001F9\n") {
+		t.Errorf("extended linear address record not written")
+	}
+
+	got, err := frames.ReadIntelHex(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(input) {
+		t.Fatalf("got %d frames, want %d", len(got), len(input))
+	}
+
+	for i := range input {
+		if !bytes.Equal(got[i], input[i]) {
+			t.Fatalf("frame %d differs", i)
+		}
+	}
+}
+
+func TestReadIntelHexErrors(t *testing.T) {
+	if _, err := frames.ReadIntelHex(strings.NewReader(":0500100065737423037A\n")); !errors.Is(err, frames.ErrIntelHexChecksum) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrIntelHexChecksum)
+	}
+
+	if _, err := frames.ReadIntelHex(strings.NewReader("05001000657374230379\n")); !errors.Is(err, frames.ErrIntelHexFormat) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrIntelHexFormat)
+	}
+
+	if _, err := frames.ReadIntelHex(strings.NewReader(":06001000657374230379\n")); !errors.Is(err, frames.ErrIntelHexFormat) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrIntelHexFormat)
+	}
+
+	// a single byte at 16 MiB would make ReadIntelHex allocate it all
+	if _, err := frames.ReadIntelHex(strings.NewReader(":020000040100F9\n:01000000AA55\n")); !errors.Is(err, frames.ErrIntelHexFormat) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrIntelHexFormat)
+	}
+}
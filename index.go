@@ -0,0 +1,47 @@
+package frames
+
+import "bytes"
+
+// Index is a set of frames, allowing to quickly check whether exactly the same
+// frame has been seen before. Frames are looked up by their Hash.
+//
+// The zero value is an empty Index ready to use.
+type Index struct {
+	frames map[uint64][]Frame
+	n      int
+}
+
+// Add adds frame to the index. It reports whether the frame was added, i.e
+// whether it was not in the index yet. The index keeps a reference to frame,
+// so it must not be modified afterwards.
+func (idx *Index) Add(frame Frame) bool {
+	if idx.Contains(frame) {
+		return false
+	}
+
+	if idx.frames == nil {
+		idx.frames = make(map[uint64][]Frame)
+	}
+
+	hash := frame.Hash()
+	idx.frames[hash] = append(idx.frames[hash], frame)
+	idx.n++
+
+	return true
+}
+
+// Contains reports whether exactly the same frame is in the index.
+func (idx *Index) Contains(frame Frame) bool {
+	for _, f := range idx.frames[frame.Hash()] {
+		if bytes.Equal(f, frame) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Len returns the number of frames in the index.
+func (idx *Index) Len() int {
+	return idx.n
+}
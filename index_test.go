@@ -0,0 +1,33 @@
+package frames_test
+
+import (
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestIndex(t *testing.T) {
+	var idx frames.Index
+
+	for _, tc := range testCases {
+		if !idx.Add(tc.frame) {
+			t.Errorf("frame % x not added", tc.frame)
+		}
+	}
+
+	for _, tc := range testCases {
+		if idx.Add(frames.Create(tc.inputHeader, tc.inputData)) {
+			t.Errorf("frame % x added twice", tc.frame)
+		}
+	}
+
+	if idx.Len() != len(testCases) {
+		t.Errorf("got %d frames in index, want %d", idx.Len(), len(testCases))
+	}
+
+	// same header and data, but different checksum
+	corrupted := frames.Frame{'L', 'D', 0x01, '+', 'A', '#', 0x41}
+	if idx.Contains(corrupted) {
+		t.Errorf("index contains corrupted frame % x", []byte(corrupted))
+	}
+}
@@ -0,0 +1,152 @@
+package frames
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrKeyValueFormat is returned when a key or value cannot be represented in
+// key-value data, e.g. an empty key or one containing '=' or '&'.
+var ErrKeyValueFormat = errors.New("frames: invalid key-value pair")
+
+// ErrKeyNotFound is returned when key-value data has no requested key.
+var ErrKeyNotFound = errors.New("frames: key not found")
+
+// KeyValueError describes a key whose value could not be retrieved.
+type KeyValueError struct {
+	Key string
+	Err error // ErrKeyNotFound or the conversion error
+}
+
+func (e *KeyValueError) Error() string {
+	return fmt.Sprintf("frames: key %q: %v", e.Key, e.Err)
+}
+
+func (e *KeyValueError) Unwrap() error {
+	return e.Err
+}
+
+// KeyValues is textual data made of "key=value" pairs separated by ampersands,
+// e.g. "mode=scan&rpm=600&gain=1.5", which is easy to type in a terminal.
+// Pairs keep their order.
+type KeyValues struct {
+	pairs [][2]string
+}
+
+// ParseKeyValues parses key-value data, e.g. a frame's data. Spaces around
+// keys and values are ignored, and a key without an equals sign gets an empty
+// value. Empty pairs are skipped.
+func ParseKeyValues(data []byte) (KeyValues, error) {
+	var kv KeyValues
+	for _, pair := range strings.Split(string(data), "&") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		key, value := pair, ""
+		if i := strings.IndexByte(pair, '='); i >= 0 {
+			key, value = pair[:i], pair[i+1:]
+		}
+		if err := kv.Set(strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+			return KeyValues{}, err
+		}
+	}
+
+	return kv, nil
+}
+
+// Set sets key to value, replacing its previous value.
+func (kv *KeyValues) Set(key, value string) error {
+	if key == "" || strings.ContainsAny(key, "=&") || strings.ContainsRune(value, '&') {
+		return ErrKeyValueFormat
+	}
+
+	for i := range kv.pairs {
+		if kv.pairs[i][0] == key {
+			kv.pairs[i][1] = value
+			return nil
+		}
+	}
+	kv.pairs = append(kv.pairs, [2]string{key, value})
+
+	return nil
+}
+
+// SetInt sets key to the decimal representation of value.
+func (kv *KeyValues) SetInt(key string, value int) error {
+	return kv.Set(key, strconv.Itoa(value))
+}
+
+// SetFloat sets key to the shortest representation of value.
+func (kv *KeyValues) SetFloat(key string, value float64) error {
+	return kv.Set(key, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// Keys returns the keys in order.
+func (kv KeyValues) Keys() []string {
+	keys := make([]string, len(kv.pairs))
+	for i, pair := range kv.pairs {
+		keys[i] = pair[0]
+	}
+
+	return keys
+}
+
+// String returns the value of key.
+func (kv KeyValues) String(key string) (string, error) {
+	for _, pair := range kv.pairs {
+		if pair[0] == key {
+			return pair[1], nil
+		}
+	}
+
+	return "", &KeyValueError{Key: key, Err: ErrKeyNotFound}
+}
+
+// Int returns the value of key parsed as a decimal integer.
+func (kv KeyValues) Int(key string) (int, error) {
+	value, err := kv.String(key)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &KeyValueError{Key: key, Err: err}
+	}
+
+	return n, nil
+}
+
+// Float returns the value of key parsed as a floating-point number.
+func (kv KeyValues) Float(key string) (float64, error) {
+	value, err := kv.String(key)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, &KeyValueError{Key: key, Err: err}
+	}
+
+	return f, nil
+}
+
+// Bytes returns the key-value data, ready to be passed to Create. Its length
+// must not overflow byte for that.
+func (kv KeyValues) Bytes() []byte {
+	var data []byte
+	for i, pair := range kv.pairs {
+		if i > 0 {
+			data = append(data, '&')
+		}
+		data = append(data, pair[0]...)
+		data = append(data, '=')
+		data = append(data, pair[1]...)
+	}
+
+	return data
+}
@@ -0,0 +1,79 @@
+package frames_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestParseKeyValues(t *testing.T) {
+	frame := frames.Create([2]byte{'C', 'F'}, []byte("mode=scan & rpm=600&gain=1.5&debug&&rpm=700\r\n"))
+
+	kv, err := frames.ParseKeyValues(frame.Data())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mode, err := kv.String("mode"); err != nil || mode != "scan" {
+		t.Errorf("got mode %q, %v, want %q, nil", mode, err, "scan")
+	}
+	if rpm, err := kv.Int("rpm"); err != nil || rpm != 700 {
+		t.Errorf("got rpm %d, %v, want 700, nil", rpm, err)
+	}
+	if gain, err := kv.Float("gain"); err != nil || gain != 1.5 {
+		t.Errorf("got gain %g, %v, want 1.5, nil", gain, err)
+	}
+	if debug, err := kv.String("debug"); err != nil || debug != "" {
+		t.Errorf("got debug %q, %v, want \"\", nil", debug, err)
+	}
+
+	want := "mode=scan&rpm=700&gain=1.5&debug="
+	if got := string(kv.Bytes()); got != want {
+		t.Errorf("got data %q, want %q", got, want)
+	}
+}
+
+func TestKeyValuesErrors(t *testing.T) {
+	var kv frames.KeyValues
+	if err := kv.Set("mode", "scan"); err != nil {
+		t.Fatal(err)
+	}
+	if err := kv.SetInt("rpm", -600); err != nil {
+		t.Fatal(err)
+	}
+	if err := kv.SetFloat("gain", 0.25); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := kv.Int("speed"); !errors.Is(err, frames.ErrKeyNotFound) {
+		t.Errorf("got error %v, want %v", err, frames.ErrKeyNotFound)
+	}
+	if _, err := kv.Int("mode"); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("got error %v, want %v", err, strconv.ErrSyntax)
+	}
+	if _, err := kv.Float("mode"); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("got error %v, want %v", err, strconv.ErrSyntax)
+	}
+	if got, err := kv.Float("rpm"); err != nil || got != -600 {
+		t.Errorf("got rpm %g, %v, want -600, nil", got, err)
+	}
+
+	for _, pair := range [][2]string{{"", "x"}, {"a=b", "x"}, {"a&b", "x"}, {"a", "x&y"}} {
+		if err := kv.Set(pair[0], pair[1]); err != frames.ErrKeyValueFormat {
+			t.Errorf("pair %q: got error %v, want %v", pair, err, frames.ErrKeyValueFormat)
+		}
+	}
+	if _, err := frames.ParseKeyValues([]byte("mode=scan&=600")); err != frames.ErrKeyValueFormat {
+		t.Errorf("got error %v, want %v", err, frames.ErrKeyValueFormat)
+	}
+
+	parsed, err := frames.ParseKeyValues(kv.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := parsed.Keys(), []string{"mode", "rpm", "gain"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("got keys %q, want %q", got, want)
+	}
+}
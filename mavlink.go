@@ -0,0 +1,93 @@
+package frames
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// mavlinkSTX is the first byte of every MAVLink v1 packet.
+const mavlinkSTX = 0xfe
+
+var (
+	// ErrMAVLinkFormat is returned when a MAVLink v1 packet, or a frame
+	// converted into one, is malformed.
+	ErrMAVLinkFormat = errors.New("frames: malformed MAVLink packet")
+
+	// ErrMAVLinkMessage is returned when there is no CRC_EXTRA for
+	// a MAVLink message ID.
+	ErrMAVLinkMessage = errors.New("frames: unknown MAVLink message")
+
+	// ErrMAVLinkCRC is returned when a MAVLink v1 packet's CRC is incorrect.
+	ErrMAVLinkCRC = errors.New("frames: incorrect MAVLink CRC")
+)
+
+// DecodeMAVLink converts a MAVLink v1 packet into a frame with the given
+// header. The frame's data holds the packet's sequence number, system ID,
+// component ID, message ID and payload, in that order.
+//
+// crcExtras maps message IDs to their CRC_EXTRA values, as defined by the
+// message set in use. The packet's CRC must be correct.
+func DecodeMAVLink(header [2]byte, packet []byte, crcExtras map[byte]byte) (Frame, error) {
+	if len(packet) < 8 || packet[0] != mavlinkSTX || len(packet) != 8+int(packet[1]) {
+		return nil, ErrMAVLinkFormat
+	}
+
+	// payload, sequence number, system, component and message IDs must fit in
+	// the frame's data
+	if int(packet[1]) > 255-4 {
+		return nil, ErrMAVLinkFormat
+	}
+
+	crcExtra, ok := crcExtras[packet[5]]
+	if !ok {
+		return nil, ErrMAVLinkMessage
+	}
+
+	body := packet[1 : len(packet)-2]
+	if binary.LittleEndian.Uint16(packet[len(packet)-2:]) != mavlinkCRC(body, crcExtra) {
+		return nil, ErrMAVLinkCRC
+	}
+
+	return Create(header, body[1:]), nil
+}
+
+// EncodeMAVLink converts frame into a MAVLink v1 packet. It is the inverse of
+// DecodeMAVLink.
+func EncodeMAVLink(frame Frame, crcExtras map[byte]byte) ([]byte, error) {
+	data := frame.Data()
+	if len(data) < 4 {
+		return nil, ErrMAVLinkFormat
+	}
+
+	crcExtra, ok := crcExtras[data[3]]
+	if !ok {
+		return nil, ErrMAVLinkMessage
+	}
+
+	packet := make([]byte, 2+len(data)+2)
+	packet[0] = mavlinkSTX
+	packet[1] = byte(len(data) - 4)
+	copy(packet[2:], data)
+	crc := mavlinkCRC(packet[1:len(packet)-2], crcExtra)
+	binary.LittleEndian.PutUint16(packet[len(packet)-2:], crc)
+
+	return packet, nil
+}
+
+// mavlinkCRC calculates the CRC-16/MCRF4XX (X.25) checksum of body followed by
+// crcExtra, as used by MAVLink.
+func mavlinkCRC(body []byte, crcExtra byte) uint16 {
+	crc := uint16(0xffff)
+	accumulate := func(b byte) {
+		tmp := b ^ byte(crc)
+		tmp ^= tmp << 4
+		crc = crc>>8 ^ uint16(tmp)<<8 ^ uint16(tmp)<<3 ^ uint16(tmp)>>4
+	}
+
+	for _, b := range body {
+		accumulate(b)
+	}
+	accumulate(crcExtra)
+
+	return crc
+}
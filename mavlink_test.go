@@ -0,0 +1,58 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+// heartbeat has message ID 0 and CRC_EXTRA 50
+var mavlinkCRCExtras = map[byte]byte{0: 50}
+
+func TestMAVLink(t *testing.T) {
+	// heartbeat from system 1, component 1
+	packet := []byte{0xfe, 0x09, 0x00, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x51, 0x04, 0x03, 0x7d, 0xdd}
+
+	frame, err := frames.DecodeMAVLink([2]byte{'M', 'V'}, packet, mavlinkCRCExtras)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !frames.Verify(frame) {
+		t.Errorf("frame verification failed for % x", []byte(frame))
+	}
+
+	if !bytes.Equal(frame.Data(), packet[2:len(packet)-2]) {
+		t.Errorf("got data % x, want data % x", frame.Data(), packet[2:len(packet)-2])
+	}
+
+	got, err := frames.EncodeMAVLink(frame, mavlinkCRCExtras)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, packet) {
+		t.Errorf("got packet % x, want packet % x", got, packet)
+	}
+}
+
+func TestMAVLinkErrors(t *testing.T) {
+	packet := []byte{0xfe, 0x09, 0x00, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x51, 0x04, 0x03, 0x7d, 0xde}
+	if _, err := frames.DecodeMAVLink([2]byte{'M', 'V'}, packet, mavlinkCRCExtras); !errors.Is(err, frames.ErrMAVLinkCRC) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrMAVLinkCRC)
+	}
+
+	if _, err := frames.DecodeMAVLink([2]byte{'M', 'V'}, packet[:10], mavlinkCRCExtras); !errors.Is(err, frames.ErrMAVLinkFormat) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrMAVLinkFormat)
+	}
+
+	if _, err := frames.DecodeMAVLink([2]byte{'M', 'V'}, packet, nil); !errors.Is(err, frames.ErrMAVLinkMessage) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrMAVLinkMessage)
+	}
+
+	if _, err := frames.EncodeMAVLink(frames.Create([2]byte{'M', 'V'}, []byte{0x00}), mavlinkCRCExtras); !errors.Is(err, frames.ErrMAVLinkFormat) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrMAVLinkFormat)
+	}
+}
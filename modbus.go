@@ -0,0 +1,67 @@
+package frames
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	// ErrModbusLength is returned when a Modbus RTU ADU is too short to hold
+	// an address, a function code and a CRC.
+	ErrModbusLength = errors.New("frames: Modbus ADU too short")
+
+	// ErrModbusCRC is returned when a Modbus RTU ADU's CRC is incorrect.
+	ErrModbusCRC = errors.New("frames: incorrect Modbus CRC")
+)
+
+// DecodeModbus converts a Modbus RTU ADU (address, function code, data and
+// CRC-16) into a frame with the given header. The frame's data holds the ADU
+// without the CRC, i.e the address, the function code and the function's data.
+//
+// The ADU's CRC must be correct.
+func DecodeModbus(header [2]byte, adu []byte) (Frame, error) {
+	if len(adu) < 4 {
+		return nil, ErrModbusLength
+	}
+
+	pdu := adu[:len(adu)-2]
+	if binary.LittleEndian.Uint16(adu[len(adu)-2:]) != modbusCRC(pdu) {
+		return nil, ErrModbusCRC
+	}
+
+	return Create(header, pdu), nil
+}
+
+// EncodeModbus converts frame into a Modbus RTU ADU. It is the inverse of
+// DecodeModbus: the frame's data, which must start with an address and
+// a function code, is followed by its CRC-16.
+func EncodeModbus(frame Frame) ([]byte, error) {
+	pdu := frame.Data()
+	if len(pdu) < 2 {
+		return nil, ErrModbusLength
+	}
+
+	adu := make([]byte, len(pdu)+2)
+	copy(adu, pdu)
+	binary.LittleEndian.PutUint16(adu[len(pdu):], modbusCRC(pdu))
+
+	return adu, nil
+}
+
+// modbusCRC calculates the Modbus CRC-16 of b (reflected polynomial 0xA001,
+// initial value 0xFFFF).
+func modbusCRC(b []byte) uint16 {
+	crc := uint16(0xffff)
+	for _, v := range b {
+		crc ^= uint16(v)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xa001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+
+	return crc
+}
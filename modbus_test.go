@@ -0,0 +1,50 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestModbus(t *testing.T) {
+	// read holding registers 0x006b-0x006d from slave 0x11
+	adu := []byte{0x11, 0x03, 0x00, 0x6b, 0x00, 0x03, 0x76, 0x87}
+
+	frame, err := frames.DecodeModbus([2]byte{'M', 'B'}, adu)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !frames.Verify(frame) {
+		t.Errorf("frame verification failed for % x", []byte(frame))
+	}
+
+	if !bytes.Equal(frame.Data(), adu[:len(adu)-2]) {
+		t.Errorf("got data % x, want data % x", frame.Data(), adu[:len(adu)-2])
+	}
+
+	got, err := frames.EncodeModbus(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, adu) {
+		t.Errorf("got ADU % x, want ADU % x", got, adu)
+	}
+}
+
+func TestModbusErrors(t *testing.T) {
+	if _, err := frames.DecodeModbus([2]byte{'M', 'B'}, []byte{0x11, 0x03, 0x00, 0x6b, 0x00, 0x03, 0x76, 0x88}); !errors.Is(err, frames.ErrModbusCRC) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrModbusCRC)
+	}
+
+	if _, err := frames.DecodeModbus([2]byte{'M', 'B'}, []byte{0x11, 0x03, 0x76}); !errors.Is(err, frames.ErrModbusLength) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrModbusLength)
+	}
+
+	if _, err := frames.EncodeModbus(frames.Create([2]byte{'M', 'B'}, []byte{0x11})); !errors.Is(err, frames.ErrModbusLength) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrModbusLength)
+	}
+}
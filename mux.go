@@ -0,0 +1,139 @@
+package frames
+
+import (
+	"sync"
+	"time"
+)
+
+// subscriptionBufferSize is how many frames may wait for a single slow
+// subscriber before further frames are dropped for that subscriber.
+const subscriptionBufferSize = 64
+
+// Mux distributes frames, e.g. read from a Session, to multiple independent
+// subscribers, each receiving only the frames it cares about.
+//
+// Mux is safe for concurrent use.
+type Mux struct {
+	mu        sync.Mutex
+	subs      map[chan Frame]Filter
+	envelopes map[chan Envelope]Filter
+}
+
+// NewMux creates a new Mux without any subscribers.
+func NewMux() *Mux {
+	return &Mux{
+		subs:      make(map[chan Frame]Filter),
+		envelopes: make(map[chan Envelope]Filter),
+	}
+}
+
+// Subscribe returns a channel receiving frames accepted by filter, or all
+// frames if filter is nil. Calling cancel unsubscribes and closes the channel.
+func (m *Mux) Subscribe(filter Filter) (frames <-chan Frame, cancel func()) {
+	c := make(chan Frame, subscriptionBufferSize)
+
+	m.mu.Lock()
+	m.subs[c] = filter
+	m.mu.Unlock()
+
+	var once sync.Once
+	return c, func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+
+			delete(m.subs, c)
+			close(c)
+		})
+	}
+}
+
+// SubscribeEnvelopes works like Subscribe, but the channel receives whole
+// envelopes, so that subscribers know where and when frames arrived.
+func (m *Mux) SubscribeEnvelopes(filter Filter) (envelopes <-chan Envelope, cancel func()) {
+	c := make(chan Envelope, subscriptionBufferSize)
+
+	m.mu.Lock()
+	m.envelopes[c] = filter
+	m.mu.Unlock()
+
+	var once sync.Once
+	return c, func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+
+			delete(m.envelopes, c)
+			close(c)
+		})
+	}
+}
+
+// Publish delivers frame to all subscribers whose filters accept it. It never
+// blocks: subscribers that do not keep up miss frames.
+//
+// Envelope subscribers receive frame in an Envelope with the current time and
+// without a source. The frame is assumed to be valid.
+func (m *Mux) Publish(frame Frame) {
+	m.PublishEnvelope(Envelope{Frame: frame, Time: time.Now(), Valid: true})
+}
+
+// PublishPayload publishes a frame carrying payload, see Publish.
+func (m *Mux) PublishPayload(payload Payload) {
+	m.Publish(CreatePayload(payload))
+}
+
+// PublishEnvelope works like Publish, but subscribers of envelopes receive
+// envelope as is.
+func (m *Mux) PublishEnvelope(envelope Envelope) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for c, filter := range m.subs {
+		if filter != nil && !filter(envelope.Frame) {
+			continue
+		}
+
+		select {
+		case c <- envelope.Frame:
+		default:
+		}
+	}
+
+	for c, filter := range m.envelopes {
+		if filter != nil && !filter(envelope.Frame) {
+			continue
+		}
+
+		select {
+		case c <- envelope:
+		default:
+		}
+	}
+}
+
+// Serve reads frames with read, e.g. Session.ReadFrame, and publishes them
+// until read returns an error, which is then returned.
+func (m *Mux) Serve(read func() (Frame, error)) error {
+	for {
+		frame, err := read()
+		if err != nil {
+			return err
+		}
+
+		m.Publish(frame)
+	}
+}
+
+// ServeEnvelopes works like Serve, but reads envelopes, e.g. with
+// Session.ReadEnvelope.
+func (m *Mux) ServeEnvelopes(read func() (Envelope, error)) error {
+	for {
+		envelope, err := read()
+		if err != nil {
+			return err
+		}
+
+		m.PublishEnvelope(envelope)
+	}
+}
@@ -0,0 +1,60 @@
+package frames_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestMux(t *testing.T) {
+	mux := frames.NewMux()
+
+	all, cancelAll := mux.Subscribe(nil)
+	defer cancelAll()
+
+	ld, cancelLD := mux.Subscribe(func(frame frames.Frame) bool {
+		return string(frame.Header()) == "LD"
+	})
+	defer cancelLD()
+
+	long, cancelLong := mux.Subscribe(func(frame frames.Frame) bool {
+		return frame.LenData() > 4
+	})
+	cancelLong()
+
+	var i int
+	err := mux.Serve(func() (frames.Frame, error) {
+		if i == len(testCases) {
+			return nil, io.EOF
+		}
+		i++
+		return testCases[i-1].frame, nil
+	})
+	if err != io.EOF {
+		t.Fatalf("got error %v, want %v", err, io.EOF)
+	}
+
+	for i, tc := range testCases {
+		if got := <-all; !bytes.Equal(got, tc.frame) {
+			t.Errorf("test %d: got frame % x, want frame % x", i, []byte(got), tc.frame)
+		}
+
+		if tc.inputHeader != [2]byte{'L', 'D'} {
+			continue
+		}
+
+		if got := <-ld; !bytes.Equal(got, tc.frame) {
+			t.Errorf("test %d: got LD frame % x, want frame % x", i, []byte(got), tc.frame)
+		}
+	}
+
+	if n := len(ld); n != 0 {
+		t.Errorf("got %d unexpected frames in LD subscription", n)
+	}
+
+	if _, ok := <-long; ok {
+		t.Error("got frame from cancelled subscription")
+	}
+}
@@ -0,0 +1,77 @@
+package frames
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrNMEAFormat is returned by DecodeNMEA when the sentence is malformed.
+	ErrNMEAFormat = errors.New("frames: malformed NMEA sentence")
+
+	// ErrNMEAChecksum is returned by DecodeNMEA when the sentence's checksum is
+	// incorrect.
+	ErrNMEAChecksum = errors.New("frames: incorrect NMEA sentence checksum")
+)
+
+// EncodeNMEA converts frame into an NMEA 0183 style sentence. The sentence
+// starts with a dollar sign ("$") followed by the frame's header and data.
+// After an asterisk ("*") comes the XOR checksum of all characters between the
+// dollar sign and the asterisk, as two hex digits, and the sentence ends with
+// "\r\n".
+//
+// For example, a frame with header "GP" and data "GGA,1" is converted to
+// "$GPGGA,1*CS\r\n". It does not check whether the frame is correct.
+func EncodeNMEA(frame Frame) []byte {
+	body := append(append([]byte{}, frame.Header()...), frame.Data()...)
+
+	sentence := make([]byte, 0, len(body)+6)
+	sentence = append(sentence, '$')
+	sentence = append(sentence, body...)
+	sentence = append(sentence, fmt.Sprintf("*%02X\r\n", nmeaChecksum(body))...)
+
+	return sentence
+}
+
+// DecodeNMEA converts an NMEA 0183 style sentence into a frame. It is the
+// inverse of EncodeNMEA: the first 2 characters after the dollar sign become
+// the frame's header (for real NMEA sentences it is the talker ID, e.g. "GP"),
+// and the rest up to the asterisk becomes the frame's data.
+//
+// The sentence's checksum must be correct. The trailing "\r\n" is optional.
+func DecodeNMEA(sentence []byte) (Frame, error) {
+	sentence = bytes.TrimSuffix(sentence, []byte("\r\n"))
+
+	star := bytes.LastIndexByte(sentence, '*')
+	if len(sentence) < 1 || sentence[0] != '$' || star < 3 || len(sentence)-star != 3 {
+		return nil, ErrNMEAFormat
+	}
+
+	body := sentence[1:star]
+	if !validHeaderByte(body[0]) || !validHeaderByte(body[1]) || len(body)-2 > math.MaxUint8 {
+		return nil, ErrNMEAFormat
+	}
+
+	checksum, err := hex.DecodeString(string(sentence[star+1:]))
+	if err != nil {
+		return nil, ErrNMEAFormat
+	}
+
+	if checksum[0] != nmeaChecksum(body) {
+		return nil, ErrNMEAChecksum
+	}
+
+	return Create([2]byte{body[0], body[1]}, body[2:]), nil
+}
+
+// nmeaChecksum calculates the XOR checksum of an NMEA sentence's body.
+func nmeaChecksum(body []byte) (crc byte) {
+	for _, b := range body {
+		crc ^= b
+	}
+
+	return
+}
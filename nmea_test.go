@@ -0,0 +1,82 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestEncodeNMEA(t *testing.T) {
+	frame := frames.Create([2]byte{'G', 'P'}, []byte("GLL,4916.45,N,12311.12,W,225444,A"))
+	want := "$GPGLL,4916.45,N,12311.12,W,225444,A*31\r\n"
+
+	if got := string(frames.EncodeNMEA(frame)); got != want {
+		t.Errorf("got sentence %q, want sentence %q", got, want)
+	}
+}
+
+func TestDecodeNMEA(t *testing.T) {
+	nmeaTestCases := []struct {
+		sentence string
+		header   []byte
+		data     []byte
+		err      error
+	}{
+		{
+			sentence: "$GPGLL,4916.45,N,12311.12,W,225444,A*31\r\n",
+			header:   []byte("GP"),
+			data:     []byte("GLL,4916.45,N,12311.12,W,225444,A"),
+		},
+		// without line ending
+		{
+			sentence: "$LD,dondu*50",
+			header:   []byte("LD"),
+			data:     []byte(",dondu"),
+		},
+		{
+			sentence: "$LD,dondu*51",
+			err:      frames.ErrNMEAChecksum,
+		},
+		{
+			sentence: "LD,dondu*50",
+			err:      frames.ErrNMEAFormat,
+		},
+		{
+			sentence: "$LD,dondu",
+			err:      frames.ErrNMEAFormat,
+		},
+		{
+			sentence: "$LD,dondu*XY",
+			err:      frames.ErrNMEAFormat,
+		},
+		{
+			sentence: "$L*4C",
+			err:      frames.ErrNMEAFormat,
+		},
+	}
+
+	for i, tc := range nmeaTestCases {
+		testName := fmt.Sprintf("test %d", i)
+		t.Run(testName, func(t *testing.T) {
+			frame, err := frames.DecodeNMEA([]byte(tc.sentence))
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, want error %v", err, tc.err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if !frames.Verify(frame) {
+				t.Errorf("frame verification failed for % x", []byte(frame))
+			}
+
+			if !bytes.Equal(frame.Header(), tc.header) || !bytes.Equal(frame.Data(), tc.data) {
+				t.Errorf("got header %q and data %q, want header %q and data %q", frame.Header(), frame.Data(), tc.header, tc.data)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package frames
+
+import (
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// NoisyChannelConfig configures a simulated channel created by NewNoisyChannel.
+// The zero value describes a perfect channel.
+type NoisyChannelConfig struct {
+	// BitErrorRate is the probability of flipping each transmitted bit.
+	BitErrorRate float64
+
+	// BurstProbability is the probability of a burst of errors starting at
+	// each transmitted bit. During a burst, every bit is flipped with the
+	// probability of 0.5.
+	BurstProbability float64
+
+	// BurstLength is the number of bits affected by a burst.
+	BurstLength int
+
+	// Latency delays the delivery of every written byte.
+	Latency time.Duration
+
+	// Bandwidth limits the channel's throughput in bytes per second. Zero
+	// means no limit.
+	Bandwidth int
+
+	// Seed seeds the errors, so that a simulation can be repeated.
+	// Independent pseudo-random generators are used for both directions.
+	Seed int64
+}
+
+// NewNoisyChannel creates a simulated full-duplex channel, e.g. a radio link.
+// Bytes written to one end are corrupted, delayed and throttled according to
+// config, and can be read from the other end. Writes never block. Closing
+// either end closes the whole channel.
+//
+// Errors depend only on config.Seed and the sequence of written bytes, so the
+// full stack can be tested deterministically.
+func NewNoisyChannel(config NoisyChannelConfig) (a, b io.ReadWriteCloser) {
+	ab := newNoisyLink(config, config.Seed)
+	ba := newNoisyLink(config, config.Seed+1)
+
+	return &noisyEnd{in: ba, out: ab}, &noisyEnd{in: ab, out: ba}
+}
+
+// noisyEnd is an end of a channel created by NewNoisyChannel.
+type noisyEnd struct {
+	in  *noisyLink
+	out *noisyLink
+}
+
+func (e *noisyEnd) Read(p []byte) (int, error) {
+	return e.in.read(p)
+}
+
+func (e *noisyEnd) Write(p []byte) (int, error) {
+	return e.out.write(p)
+}
+
+func (e *noisyEnd) Close() error {
+	e.in.close()
+	e.out.close()
+	return nil
+}
+
+// noisyLink carries bytes in one direction of a noisy channel.
+type noisyLink struct {
+	config NoisyChannelConfig
+
+	mu     sync.Mutex
+	rand   *rand.Rand
+	burst  int       // bits left in the current burst
+	busy   time.Time // when previously written bytes are transmitted
+	chunks []noisyChunk
+	ready  chan struct{} // closed when chunks are added or the link is closed
+	closed bool
+}
+
+// noisyChunk is written data with the time of its delivery.
+type noisyChunk struct {
+	data []byte
+	at   time.Time
+}
+
+func newNoisyLink(config NoisyChannelConfig, seed int64) *noisyLink {
+	return &noisyLink{
+		config: config,
+		rand:   rand.New(rand.NewSource(seed)),
+		ready:  make(chan struct{}),
+	}
+}
+
+func (l *noisyLink) write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	data := make([]byte, len(p))
+	for i, b := range p {
+		data[i] = b ^ l.noise()
+	}
+
+	now := time.Now()
+	if l.busy.Before(now) {
+		l.busy = now
+	}
+	if l.config.Bandwidth > 0 {
+		l.busy = l.busy.Add(time.Duration(len(p)) * time.Second / time.Duration(l.config.Bandwidth))
+	}
+
+	l.chunks = append(l.chunks, noisyChunk{data: data, at: l.busy.Add(l.config.Latency)})
+	close(l.ready)
+	l.ready = make(chan struct{})
+
+	return len(p), nil
+}
+
+// noise returns the mask of bits to flip in the next transmitted byte.
+func (l *noisyLink) noise() (mask byte) {
+	for bit := 0; bit < 8; bit++ {
+		if l.burst == 0 && l.config.BurstProbability > 0 && l.rand.Float64() < l.config.BurstProbability {
+			l.burst = l.config.BurstLength
+		}
+
+		flip := l.config.BitErrorRate > 0 && l.rand.Float64() < l.config.BitErrorRate
+		if l.burst > 0 {
+			l.burst--
+			flip = flip || l.rand.Intn(2) == 0
+		}
+
+		if flip {
+			mask |= 1 << bit
+		}
+	}
+
+	return
+}
+
+func (l *noisyLink) read(p []byte) (int, error) {
+	for {
+		l.mu.Lock()
+		if len(l.chunks) == 0 {
+			if l.closed {
+				l.mu.Unlock()
+				return 0, io.EOF
+			}
+
+			ready := l.ready
+			l.mu.Unlock()
+			<-ready
+			continue
+		}
+
+		at := l.chunks[0].at
+		l.mu.Unlock()
+		time.Sleep(time.Until(at))
+
+		l.mu.Lock()
+		if len(l.chunks) == 0 {
+			l.mu.Unlock()
+			continue
+		}
+
+		n := copy(p, l.chunks[0].data)
+		if n < len(l.chunks[0].data) {
+			l.chunks[0].data = l.chunks[0].data[n:]
+		} else {
+			l.chunks = l.chunks[1:]
+		}
+		l.mu.Unlock()
+
+		return n, nil
+	}
+}
+
+func (l *noisyLink) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.closed {
+		l.closed = true
+		close(l.ready)
+	}
+}
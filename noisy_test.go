@@ -0,0 +1,97 @@
+package frames_test
+
+import (
+	"bytes"
+	"io"
+	"math/bits"
+	"testing"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+// transmit writes data to a and reads it from b.
+func transmit(t *testing.T, a, b io.ReadWriter, data []byte) []byte {
+	t.Helper()
+
+	if _, err := a.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]byte, len(data))
+	if _, err := io.ReadFull(b, got); err != nil {
+		t.Fatal(err)
+	}
+
+	return got
+}
+
+func TestNoisyChannelPerfect(t *testing.T) {
+	a, b := frames.NewNoisyChannel(frames.NoisyChannelConfig{})
+	defer a.Close()
+
+	for i, tc := range testCases {
+		if got := transmit(t, a, b, tc.frame); !bytes.Equal(got, tc.frame) {
+			t.Errorf("test %d: got % x, want % x", i, got, tc.frame)
+		}
+
+		if got := transmit(t, b, a, tc.frame); !bytes.Equal(got, tc.frame) {
+			t.Errorf("test %d: got % x back, want % x", i, got, tc.frame)
+		}
+	}
+
+	b.Close()
+	if _, err := a.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("got error %v after closing, want %v", err, io.EOF)
+	}
+	if _, err := a.Write([]byte{0}); err != io.ErrClosedPipe {
+		t.Errorf("got error %v after closing, want %v", err, io.ErrClosedPipe)
+	}
+}
+
+func TestNoisyChannelErrors(t *testing.T) {
+	config := frames.NoisyChannelConfig{
+		BitErrorRate:     0.01,
+		BurstProbability: 0.001,
+		BurstLength:      16,
+		Seed:             42,
+	}
+	data := bytes.Repeat([]byte("dupcia"), 1000)
+
+	var received [2][]byte
+	for i := range received {
+		a, b := frames.NewNoisyChannel(config)
+		received[i] = transmit(t, a, b, data)
+		a.Close()
+	}
+
+	if !bytes.Equal(received[0], received[1]) {
+		t.Error("got different errors with the same seed")
+	}
+
+	flipped := 0
+	for i := range data {
+		flipped += bits.OnesCount8(data[i] ^ received[0][i])
+	}
+
+	// at least the expected number of independent errors
+	if flipped < len(data)*8/100/2 {
+		t.Errorf("got %d flipped bits in %d bytes, want more", flipped, len(data))
+	}
+}
+
+func TestNoisyChannelTiming(t *testing.T) {
+	a, b := frames.NewNoisyChannel(frames.NoisyChannelConfig{
+		Latency:   20 * time.Millisecond,
+		Bandwidth: 10000,
+	})
+	defer a.Close()
+
+	start := time.Now()
+	transmit(t, a, b, make([]byte, 200))
+
+	// 20ms of latency and 20ms of transmission
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("got data after %v, want it after at least 40ms", elapsed)
+	}
+}
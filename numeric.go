@@ -0,0 +1,77 @@
+package frames
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// CreateUint16 creates a new frame whose data is value encoded in order, e.g.
+// binary.BigEndian.
+func CreateUint16(header [2]byte, value uint16, order binary.ByteOrder) Frame {
+	var data [2]byte
+	order.PutUint16(data[:], value)
+	return Create(header, data[:])
+}
+
+// CreateInt32 creates a new frame whose data is value encoded in order in two's
+// complement.
+func CreateInt32(header [2]byte, value int32, order binary.ByteOrder) Frame {
+	var data [4]byte
+	order.PutUint32(data[:], uint32(value))
+	return Create(header, data[:])
+}
+
+// CreateFloat32 creates a new frame whose data is value encoded in order as an
+// IEEE 754 single-precision number.
+func CreateFloat32(header [2]byte, value float32, order binary.ByteOrder) Frame {
+	var data [4]byte
+	order.PutUint32(data[:], math.Float32bits(value))
+	return Create(header, data[:])
+}
+
+// DecodeUint16 decodes the data of frame created with CreateUint16. If the
+// frame is invalid, it returns a *VerifyError, see Validate. If the data is
+// not exactly 2 bytes long, it returns ErrPayloadLength.
+func DecodeUint16(frame Frame, order binary.ByteOrder) (uint16, error) {
+	data, err := numericData(frame, 2)
+	if err != nil {
+		return 0, err
+	}
+
+	return order.Uint16(data), nil
+}
+
+// DecodeInt32 decodes the data of frame created with CreateInt32, see
+// DecodeUint16.
+func DecodeInt32(frame Frame, order binary.ByteOrder) (int32, error) {
+	data, err := numericData(frame, 4)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(order.Uint32(data)), nil
+}
+
+// DecodeFloat32 decodes the data of frame created with CreateFloat32, see
+// DecodeUint16.
+func DecodeFloat32(frame Frame, order binary.ByteOrder) (float32, error) {
+	data, err := numericData(frame, 4)
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Float32frombits(order.Uint32(data)), nil
+}
+
+// numericData returns the data of valid frame carrying a number of size bytes.
+func numericData(frame Frame, size int) ([]byte, error) {
+	if err := Validate(frame); err != nil {
+		return nil, err
+	}
+
+	if len(frame.Data()) != size {
+		return nil, ErrPayloadLength
+	}
+
+	return frame.Data(), nil
+}
@@ -0,0 +1,66 @@
+package frames_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestCreateNumeric(t *testing.T) {
+	header := [2]byte{'N', 'M'}
+
+	tests := []struct {
+		frame frames.Frame
+		data  []byte
+	}{
+		{frames.CreateUint16(header, 0x1234, binary.BigEndian), []byte{0x12, 0x34}},
+		{frames.CreateUint16(header, 0x1234, binary.LittleEndian), []byte{0x34, 0x12}},
+		{frames.CreateInt32(header, -2, binary.BigEndian), []byte{0xff, 0xff, 0xff, 0xfe}},
+		{frames.CreateInt32(header, 0x01020304, binary.LittleEndian), []byte{0x04, 0x03, 0x02, 0x01}},
+		{frames.CreateFloat32(header, 1.5, binary.BigEndian), []byte{0x3f, 0xc0, 0x00, 0x00}},
+		{frames.CreateFloat32(header, -0.25, binary.LittleEndian), []byte{0x00, 0x00, 0x80, 0xbe}},
+	}
+
+	for _, tt := range tests {
+		if err := frames.Validate(tt.frame); err != nil {
+			t.Errorf("frame % x: %v", []byte(tt.frame), err)
+		}
+		if !bytes.Equal(tt.frame.Data(), tt.data) {
+			t.Errorf("got data % x, want % x", tt.frame.Data(), tt.data)
+		}
+	}
+}
+
+func TestDecodeNumeric(t *testing.T) {
+	header := [2]byte{'N', 'M'}
+
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		if got, err := frames.DecodeUint16(frames.CreateUint16(header, math.MaxUint16, order), order); err != nil || got != math.MaxUint16 {
+			t.Errorf("%v: got %d, %v, want %d, nil", order, got, err, math.MaxUint16)
+		}
+		if got, err := frames.DecodeInt32(frames.CreateInt32(header, math.MinInt32, order), order); err != nil || got != math.MinInt32 {
+			t.Errorf("%v: got %d, %v, want %d, nil", order, got, err, math.MinInt32)
+		}
+		if got, err := frames.DecodeFloat32(frames.CreateFloat32(header, -3.25, order), order); err != nil || got != -3.25 {
+			t.Errorf("%v: got %g, %v, want -3.25, nil", order, got, err)
+		}
+	}
+}
+
+func TestDecodeNumericErrors(t *testing.T) {
+	frame := frames.CreateUint16([2]byte{'N', 'M'}, 1, binary.BigEndian)
+
+	if _, err := frames.DecodeInt32(frame, binary.BigEndian); err != frames.ErrPayloadLength {
+		t.Errorf("got error %v, want %v", err, frames.ErrPayloadLength)
+	}
+
+	frame[len(frame)-1] ^= 0xff
+	var verifyErr *frames.VerifyError
+	if _, err := frames.DecodeUint16(frame, binary.BigEndian); !errors.As(err, &verifyErr) {
+		t.Errorf("got error %v, want *VerifyError", err)
+	}
+}
@@ -0,0 +1,20 @@
+package frames
+
+import "errors"
+
+// ErrIncomplete is returned by ParseFrame when buf does not contain a complete
+// frame.
+var ErrIncomplete = errors.New("frames: no complete frame")
+
+// ParseFrame parses the first valid frame in buf. Bytes preceding the frame
+// that cannot be a part of any frame are skipped, and bytes following the
+// frame are left untouched. The returned frame is a copy, so buf may be reused
+// afterwards.
+//
+// It returns the number of bytes consumed from buf, i.e. the number of skipped
+// bytes plus the length of the frame. If buf does not contain a complete
+// frame, ParseFrame returns ErrIncomplete. In that case n is the number of
+// skipped bytes, which can be discarded before more bytes are appended to buf.
+func ParseFrame(buf []byte) (frame Frame, n int, err error) {
+	return Codec{}.ParseFrame(buf)
+}
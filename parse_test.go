@@ -0,0 +1,99 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestParseFrame(t *testing.T) {
+	parseTestCases := []struct {
+		buf   []byte
+		frame []byte
+		n     int
+		err   error
+	}{
+		// exactly one frame
+		{
+			buf:   []byte{'L', 'D', 0x1, '+', 'A', '#', 0x40},
+			frame: []byte{'L', 'D', 0x1, '+', 'A', '#', 0x40},
+			n:     7,
+		},
+		// leading garbage and trailing bytes
+		{
+			buf:   []byte{'x', 0x00, 'L', 'D', 0x1, '+', 'A', '#', 0x40, 'M', 'T'},
+			frame: []byte{'L', 'D', 0x1, '+', 'A', '#', 0x40},
+			n:     9,
+		},
+		// garbage looking like a frame start
+		{
+			buf:   []byte{'A', 'B', 0x0, '+', '#', 0x01, 'L', 'D', 0x0, '+', '#', 0x00},
+			frame: []byte{'L', 'D', 0x0, '+', '#', 0x00},
+			n:     12,
+		},
+		// incomplete frame after garbage
+		{
+			buf: []byte{'x', 'd', 'L', 'D', 0x4, '+', 't', 'e'},
+			n:   2,
+			err: frames.ErrIncomplete,
+		},
+		// incomplete header
+		{
+			buf: []byte{'x', 'L'},
+			n:   1,
+			err: frames.ErrIncomplete,
+		},
+		// garbage only
+		{
+			buf: []byte{'x', 'd', 0x00},
+			n:   3,
+			err: frames.ErrIncomplete,
+		},
+		// empty buffer
+		{
+			buf: nil,
+			n:   0,
+			err: frames.ErrIncomplete,
+		},
+	}
+
+	for i, tc := range parseTestCases {
+		testName := fmt.Sprintf("test %d", i)
+		t.Run(testName, func(t *testing.T) {
+			frame, n, err := frames.ParseFrame(tc.buf)
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got error %v, want error %v", err, tc.err)
+			}
+
+			if n != tc.n {
+				t.Errorf("got %d bytes consumed, want %d", n, tc.n)
+			}
+
+			if !bytes.Equal(frame, tc.frame) {
+				t.Errorf("got frame % x, want frame % x", []byte(frame), tc.frame)
+			}
+		})
+	}
+}
+
+func FuzzParseFrame(f *testing.F) {
+	for _, tc := range testCases {
+		f.Add(tc.frame)
+	}
+
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		frame, n, err := frames.ParseFrame(buf)
+
+		if n < 0 || n > len(buf) {
+			t.Fatalf("got %d bytes consumed from %d bytes", n, len(buf))
+		}
+
+		if err == nil && !frames.Verify(frame) {
+			t.Errorf("parsed invalid frame % x", []byte(frame))
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package frames
+
+// ParsedFrame is a frame together with its fields and validity, which are
+// computed once by NewParsedFrame, so that accessing them repeatedly, e.g. in a
+// chain of handlers, is cheap.
+type ParsedFrame struct {
+	frame  Frame
+	fields Fields
+	err    error
+}
+
+// NewParsedFrame parses frame using VerifyAndParse. The ParsedFrame refers to
+// frame, so it must not be modified afterwards.
+func NewParsedFrame(frame Frame) ParsedFrame {
+	fields, err := VerifyAndParse(frame)
+	return ParsedFrame{frame: frame, fields: fields, err: err}
+}
+
+// Frame returns the parsed frame.
+func (p ParsedFrame) Frame() Frame {
+	return p.frame
+}
+
+// Valid reports whether the frame is valid.
+func (p ParsedFrame) Valid() bool {
+	return p.err == nil
+}
+
+// Err returns the *VerifyError describing why the frame is invalid, or nil if
+// it is valid.
+func (p ParsedFrame) Err() error {
+	return p.err
+}
+
+// Header returns frame's header. If the frame is invalid, it returns nil.
+func (p ParsedFrame) Header() []byte {
+	if p.err != nil {
+		return nil
+	}
+
+	return p.frame[:2]
+}
+
+// Data returns frame's data. If the frame is invalid, it returns nil.
+func (p ParsedFrame) Data() []byte {
+	return p.fields.Data
+}
+
+// LenData returns the length of frame's data in bytes. If the frame is
+// invalid, it returns 0.
+func (p ParsedFrame) LenData() int {
+	return len(p.fields.Data)
+}
+
+// Checksum returns frame's checksum. If the frame is invalid, it returns 0.
+func (p ParsedFrame) Checksum() byte {
+	return p.fields.Checksum
+}
+
+// Fields returns frame's fields. If the frame is invalid, they are all zero.
+func (p ParsedFrame) Fields() Fields {
+	return p.fields
+}
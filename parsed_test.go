@@ -0,0 +1,59 @@
+package frames_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestParsedFrame(t *testing.T) {
+	for i, tc := range testCases {
+		testName := fmt.Sprintf("test %d", i)
+		t.Run(testName, func(t *testing.T) {
+			parsed := frames.NewParsedFrame(tc.frame)
+
+			if !parsed.Valid() || parsed.Err() != nil {
+				t.Fatalf("got error %v, want valid frame", parsed.Err())
+			}
+
+			if !bytes.Equal(parsed.Frame(), tc.frame) {
+				t.Errorf("got frame % x, want frame % x", []byte(parsed.Frame()), tc.frame)
+			}
+
+			if !bytes.Equal(parsed.Header(), tc.inputHeader[:]) {
+				t.Errorf("got header % x, want header % x", parsed.Header(), tc.inputHeader)
+			}
+
+			if !bytes.Equal(parsed.Data(), tc.inputData) {
+				t.Errorf("got data % x, want data % x", parsed.Data(), tc.inputData)
+			}
+
+			if parsed.LenData() != len(tc.inputData) {
+				t.Errorf("got data length %d, want data %d", parsed.LenData(), len(tc.inputData))
+			}
+
+			if parsed.Checksum() != tc.expectedChecksum {
+				t.Errorf("got checksum % x, want checksum % x", parsed.Checksum(), tc.expectedChecksum)
+			}
+		})
+	}
+}
+
+func TestParsedFrameInvalid(t *testing.T) {
+	frame := []byte{'L', 'D', 0x1, '+', 'A', '#', 0x00}
+	parsed := frames.NewParsedFrame(frame)
+
+	if parsed.Valid() {
+		t.Fatalf("frame % x is valid", frame)
+	}
+
+	if want := frames.Validate(frame); parsed.Err().Error() != want.Error() {
+		t.Errorf("got error %v, want error %v", parsed.Err(), want)
+	}
+
+	if parsed.Header() != nil || parsed.Data() != nil || parsed.LenData() != 0 || parsed.Checksum() != 0 {
+		t.Errorf("got header % x, data % x and checksum %#02x, want zero values", parsed.Header(), parsed.Data(), parsed.Checksum())
+	}
+}
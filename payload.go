@@ -0,0 +1,73 @@
+package frames
+
+import "errors"
+
+// Errors returned when decoding payloads.
+var (
+	ErrPayloadLength = errors.New("frames: wrong payload length")
+	ErrPayloadHeader = errors.New("frames: wrong payload header")
+)
+
+// Payload is a message carried in frames' data, e.g. implemented by code
+// generated by payloadgen.
+type Payload interface {
+	// Header returns the header of frames carrying the payload.
+	Header() [2]byte
+
+	// AppendTo appends the payload, encoded as frame data, to b.
+	AppendTo(b []byte) []byte
+
+	// ParseFrom decodes the payload from frame data.
+	ParseFrom(data []byte) error
+}
+
+// CreatePayload creates a frame carrying payload.
+func CreatePayload(payload Payload) Frame {
+	return Create(payload.Header(), payload.AppendTo(nil))
+}
+
+// PayloadPointer is a pointer to T implementing Payload. It lets TypedFrame
+// work with payload types whose methods have pointer receivers.
+type PayloadPointer[T any] interface {
+	*T
+	Payload
+}
+
+// TypedFrame is a frame together with its decoded payload of type T, so that
+// handlers work with typed messages instead of raw data.
+type TypedFrame[T any] struct {
+	Frame   Frame
+	Payload T
+}
+
+// CreateTyped creates a frame carrying payload. The type of the payload is
+// inferred, e.g.
+//
+//	tf := frames.CreateTyped(Measurement{Angle: 90})
+func CreateTyped[T any, P PayloadPointer[T]](payload T) TypedFrame[T] {
+	return TypedFrame[T]{Frame: CreatePayload(P(&payload)), Payload: payload}
+}
+
+// DecodeTyped decodes the payload of frame as T, e.g.
+//
+//	tf, err := frames.DecodeTyped[Measurement](frame)
+//
+// If the frame is invalid, it returns a *VerifyError, see Validate. If the
+// frame's header is not T's header, it returns ErrPayloadHeader.
+func DecodeTyped[T any, P PayloadPointer[T]](frame Frame) (TypedFrame[T], error) {
+	if err := Validate(frame); err != nil {
+		return TypedFrame[T]{}, err
+	}
+
+	var payload T
+	p := P(&payload)
+	if header := p.Header(); frame[0] != header[0] || frame[1] != header[1] {
+		return TypedFrame[T]{}, ErrPayloadHeader
+	}
+
+	if err := p.ParseFrom(frame.Data()); err != nil {
+		return TypedFrame[T]{}, err
+	}
+
+	return TypedFrame[T]{Frame: frame, Payload: payload}, nil
+}
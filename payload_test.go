@@ -0,0 +1,87 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+// measurement is a payload with a 2-byte angle and a 2-byte distance.
+type measurement struct {
+	angle    uint16
+	distance uint16
+}
+
+func (m *measurement) Header() [2]byte {
+	return [2]byte{'L', 'D'}
+}
+
+func (m *measurement) AppendTo(b []byte) []byte {
+	return append(b, byte(m.angle>>8), byte(m.angle), byte(m.distance>>8), byte(m.distance))
+}
+
+func (m *measurement) ParseFrom(data []byte) error {
+	if len(data) != 4 {
+		return frames.ErrPayloadLength
+	}
+
+	m.angle = uint16(data[0])<<8 | uint16(data[1])
+	m.distance = uint16(data[2])<<8 | uint16(data[3])
+	return nil
+}
+
+func TestTypedFrame(t *testing.T) {
+	want := measurement{angle: 90, distance: 1234}
+
+	created := frames.CreateTyped(want)
+	wantFrame := frames.Create([2]byte{'L', 'D'}, []byte{0x00, 90, 0x04, 0xd2})
+	if !bytes.Equal(created.Frame, wantFrame) || created.Payload != want {
+		t.Errorf("got frame % x and payload %+v, want frame % x and payload %+v", []byte(created.Frame), created.Payload, []byte(wantFrame), want)
+	}
+
+	decoded, err := frames.DecodeTyped[measurement](created.Frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Payload != want {
+		t.Errorf("got payload %+v, want %+v", decoded.Payload, want)
+	}
+}
+
+func TestDecodeTypedErrors(t *testing.T) {
+	invalid := frames.Frame{'L', 'D', 0x1, '+', 'A', '#', 0x00}
+	var verifyErr *frames.VerifyError
+	if _, err := frames.DecodeTyped[measurement](invalid); !errors.As(err, &verifyErr) {
+		t.Errorf("got error %v, want *frames.VerifyError", err)
+	}
+
+	other := frames.Create([2]byte{'M', 'T'}, []byte{1, 2, 3, 4})
+	if _, err := frames.DecodeTyped[measurement](other); err != frames.ErrPayloadHeader {
+		t.Errorf("got error %v, want %v", err, frames.ErrPayloadHeader)
+	}
+
+	short := frames.Create([2]byte{'L', 'D'}, []byte{1, 2, 3})
+	if _, err := frames.DecodeTyped[measurement](short); err != frames.ErrPayloadLength {
+		t.Errorf("got error %v, want %v", err, frames.ErrPayloadLength)
+	}
+}
+
+func TestCreatePayload(t *testing.T) {
+	m := &measurement{angle: 1, distance: 2}
+	want := frames.Create([2]byte{'L', 'D'}, []byte{0, 1, 0, 2})
+
+	if got := frames.CreatePayload(m); !bytes.Equal(got, want) {
+		t.Errorf("got frame % x, want frame % x", []byte(got), []byte(want))
+	}
+
+	mux := frames.NewMux()
+	sub, cancel := mux.Subscribe(nil)
+	defer cancel()
+
+	mux.PublishPayload(m)
+	if got := <-sub; !bytes.Equal(got, want) {
+		t.Errorf("got published frame % x, want frame % x", []byte(got), []byte(want))
+	}
+}
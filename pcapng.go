@@ -0,0 +1,143 @@
+package frames
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// LinkTypeUser0 is the first of the link types reserved for private use
+// (LINKTYPE_USER0 to LINKTYPE_USER15, i.e. 147 to 162). Wireshark can be told
+// to decode them with a custom dissector.
+const LinkTypeUser0 = 147
+
+// Block types and options of pcapng.
+const (
+	pcapngSectionHeader    = 0x0a0d0d0a
+	pcapngInterface        = 0x00000001
+	pcapngEnhancedPacket   = 0x00000006
+	pcapngByteOrderMagic   = 0x1a2b3c4d
+	pcapngOptionEnd        = 0
+	pcapngOptionTSResol    = 9
+	pcapngOptionFlags      = 2
+	pcapngFlagsInbound     = 1
+	pcapngFlagsOutbound    = 2
+	pcapngNanosecondsResol = 9
+)
+
+// PCAPNGWriter writes records in the pcapng format, so that captures can be
+// analyzed with Wireshark or tshark. Every frame becomes a packet with the
+// record's timestamp, in nanoseconds, and direction. All packets share a
+// single interface with a user-defined link type.
+type PCAPNGWriter struct {
+	w        io.Writer
+	linkType uint16
+	started  bool
+}
+
+// NewPCAPNGWriter creates a PCAPNGWriter writing to w. Packets are marked with
+// linkType, e.g. LinkTypeUser0.
+func NewPCAPNGWriter(w io.Writer, linkType uint16) *PCAPNGWriter {
+	return &PCAPNGWriter{w: w, linkType: linkType}
+}
+
+// Write writes record as a single packet. The section and interface headers
+// are written before the first packet.
+func (p *PCAPNGWriter) Write(record Record) error {
+	if !p.started {
+		p.started = true
+		if err := p.writeHeaders(); err != nil {
+			return err
+		}
+	}
+
+	flags := uint32(pcapngFlagsInbound)
+	if record.Direction == Sent {
+		flags = pcapngFlagsOutbound
+	}
+
+	timestamp := uint64(record.Time.UnixNano())
+	body := make([]byte, 20, 20+len(record.Frame)+3+12)
+	binary.LittleEndian.PutUint32(body[0:], 0) // interface
+	binary.LittleEndian.PutUint32(body[4:], uint32(timestamp>>32))
+	binary.LittleEndian.PutUint32(body[8:], uint32(timestamp))
+	binary.LittleEndian.PutUint32(body[12:], uint32(len(record.Frame)))
+	binary.LittleEndian.PutUint32(body[16:], uint32(len(record.Frame)))
+	body = append(body, record.Frame...)
+	body = append(body, make([]byte, pcapngPadding(len(record.Frame)))...)
+	body = appendPCAPNGOption(body, pcapngOptionFlags, appendUint32(nil, flags))
+	body = appendPCAPNGOption(body, pcapngOptionEnd, nil)
+
+	return p.writeBlock(pcapngEnhancedPacket, body)
+}
+
+// writeHeaders writes the section header block and the interface description
+// block.
+func (p *PCAPNGWriter) writeHeaders() error {
+	section := make([]byte, 16)
+	binary.LittleEndian.PutUint32(section[0:], pcapngByteOrderMagic)
+	binary.LittleEndian.PutUint16(section[4:], 1) // major version
+	binary.LittleEndian.PutUint16(section[6:], 0) // minor version
+	binary.LittleEndian.PutUint64(section[8:], ^uint64(0))
+	if err := p.writeBlock(pcapngSectionHeader, section); err != nil {
+		return err
+	}
+
+	iface := make([]byte, 8)
+	binary.LittleEndian.PutUint16(iface[0:], p.linkType)
+	binary.LittleEndian.PutUint32(iface[4:], 0) // no snapshot length limit
+	iface = appendPCAPNGOption(iface, pcapngOptionTSResol, []byte{pcapngNanosecondsResol})
+	iface = appendPCAPNGOption(iface, pcapngOptionEnd, nil)
+
+	return p.writeBlock(pcapngInterface, iface)
+}
+
+// writeBlock writes a block of type blockType, surrounding body with the
+// block's type and length.
+func (p *PCAPNGWriter) writeBlock(blockType uint32, body []byte) error {
+	length := uint32(12 + len(body))
+
+	block := make([]byte, 0, length)
+	block = appendUint32(block, blockType)
+	block = appendUint32(block, length)
+	block = append(block, body...)
+	block = appendUint32(block, length)
+
+	_, err := p.w.Write(block)
+	return err
+}
+
+// appendPCAPNGOption appends an option with code and value to b, padding the
+// value to 32 bits.
+func appendPCAPNGOption(b []byte, code uint16, value []byte) []byte {
+	b = appendUint16(b, code)
+	b = appendUint16(b, uint16(len(value)))
+	b = append(b, value...)
+	return append(b, make([]byte, pcapngPadding(len(value)))...)
+}
+
+// appendUint16 appends v to b in little-endian byte order.
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v), byte(v>>8))
+}
+
+// appendUint32 appends v to b in little-endian byte order.
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+// pcapngPadding returns the number of bytes padding n bytes to 32 bits.
+func pcapngPadding(n int) int {
+	return (4 - n%4) % 4
+}
+
+// WritePCAPNG writes records to w with a PCAPNGWriter.
+func WritePCAPNG(w io.Writer, records []Record, linkType uint16) error {
+	p := NewPCAPNGWriter(w, linkType)
+	for _, record := range records {
+		if err := p.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package frames_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+// pcapngBlock is a block read back from a pcapng file.
+type pcapngBlock struct {
+	blockType uint32
+	body      []byte
+}
+
+func readPCAPNGBlocks(t *testing.T, b []byte) (blocks []pcapngBlock) {
+	t.Helper()
+
+	for len(b) > 0 {
+		if len(b) < 12 {
+			t.Fatalf("got %d trailing bytes, want a complete block", len(b))
+		}
+
+		length := binary.LittleEndian.Uint32(b[4:])
+		if length%4 != 0 || int(length) > len(b) || binary.LittleEndian.Uint32(b[length-4:]) != length {
+			t.Fatalf("got invalid block length %d", length)
+		}
+
+		blocks = append(blocks, pcapngBlock{blockType: binary.LittleEndian.Uint32(b), body: b[8 : length-4]})
+		b = b[length:]
+	}
+
+	return blocks
+}
+
+func TestWritePCAPNG(t *testing.T) {
+	start := time.Date(2021, 3, 14, 15, 9, 26, 535897932, time.UTC)
+	records := []frames.Record{
+		{Time: start, Direction: frames.Received, Frame: frames.Create([2]byte{'L', 'D'}, []byte("test"))},
+		{Time: start.Add(time.Millisecond), Direction: frames.Sent, Frame: frames.Frame{'x', 'd'}},
+	}
+
+	var buf bytes.Buffer
+	if err := frames.WritePCAPNG(&buf, records, frames.LinkTypeUser0); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := readPCAPNGBlocks(t, buf.Bytes())
+	if len(blocks) != 2+len(records) {
+		t.Fatalf("got %d blocks, want %d", len(blocks), 2+len(records))
+	}
+
+	if blocks[0].blockType != 0x0a0d0d0a || binary.LittleEndian.Uint32(blocks[0].body) != 0x1a2b3c4d {
+		t.Errorf("got first block % x, want section header", blocks[0].body)
+	}
+
+	if blocks[1].blockType != 1 || binary.LittleEndian.Uint16(blocks[1].body) != frames.LinkTypeUser0 {
+		t.Errorf("got second block % x, want interface description with link type %d", blocks[1].body, frames.LinkTypeUser0)
+	}
+
+	for i, record := range records {
+		block := blocks[2+i]
+		if block.blockType != 6 {
+			t.Fatalf("record %d: got block type %#x, want enhanced packet block", i, block.blockType)
+		}
+
+		body := block.body
+		timestamp := uint64(binary.LittleEndian.Uint32(body[4:]))<<32 | uint64(binary.LittleEndian.Uint32(body[8:]))
+		if want := uint64(record.Time.UnixNano()); timestamp != want {
+			t.Errorf("record %d: got timestamp %d, want %d", i, timestamp, want)
+		}
+
+		length := binary.LittleEndian.Uint32(body[12:])
+		if got := body[20 : 20+length]; !bytes.Equal(got, record.Frame) {
+			t.Errorf("record %d: got packet % x, want % x", i, got, []byte(record.Frame))
+		}
+
+		options := body[20+(length+3)/4*4:]
+		wantFlags := uint32(1)
+		if record.Direction == frames.Sent {
+			wantFlags = 2
+		}
+		if code, flags := binary.LittleEndian.Uint16(options), binary.LittleEndian.Uint32(options[4:]); code != 2 || flags != wantFlags {
+			t.Errorf("record %d: got option %d with value %d, want flags %d", i, code, flags, wantFlags)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package frames
+
+import (
+	"io"
+	"time"
+)
+
+// Reader reads frames from a byte stream, e.g. a serial port, skipping bytes
+// that are not part of any valid frame.
+type Reader struct {
+	// Source identifies the stream in envelopes returned by ReadEnvelope.
+	Source string
+
+	fr *frameReader
+
+	// pending is a frame that did not fit in the buffer passed to
+	// ReadFrameInto
+	pending Frame
+}
+
+// NewReader creates a Reader reading frames encoded with codec from r. Reading
+// is guarded by limits.
+func NewReader(r io.Reader, codec Codec, limits Limits) *Reader {
+	return &Reader{fr: newFrameReader(r, codec, limits)}
+}
+
+// ReadFrame returns the next valid frame.
+func (r *Reader) ReadFrame() (Frame, error) {
+	frame, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+
+	return Recreate(frame), nil
+}
+
+// ReadEnvelope returns the next valid frame in an Envelope.
+func (r *Reader) ReadEnvelope() (Envelope, error) {
+	frame, err := r.ReadFrame()
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{Frame: frame, Time: time.Now(), Source: r.Source, Valid: true}, nil
+}
+
+// ReadFrameInto reads the next valid frame into buf, which is owned by the
+// caller, and returns the frame's length. Apart from growing the Reader's
+// internal buffer when needed, reading does not allocate.
+//
+// If the frame does not fit in buf, ReadFrameInto returns io.ErrShortBuffer
+// and keeps the frame, so that it can be read with a larger buffer.
+func (r *Reader) ReadFrameInto(buf []byte) (n int, err error) {
+	frame, err := r.next()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(frame) > len(buf) {
+		r.pending = frame
+		return 0, io.ErrShortBuffer
+	}
+
+	return copy(buf, frame), nil
+}
+
+// ReadFrameView works like ReadFrameInto, but returns the frame as a view of
+// buf, without copying.
+func (r *Reader) ReadFrameView(buf []byte) (Frame, error) {
+	n, err := r.ReadFrameInto(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return Frame(buf[:n]), nil
+}
+
+// next returns the pending frame, if any, or the next frame read from the
+// stream. The frame may refer to the internal buffer.
+func (r *Reader) next() (Frame, error) {
+	if frame := r.pending; frame != nil {
+		r.pending = nil
+		return frame, nil
+	}
+
+	return r.fr.next()
+}
@@ -0,0 +1,95 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+// frameLoop endlessly repeats a stream of frames.
+type frameLoop struct {
+	stream []byte
+	offset int
+}
+
+func (l *frameLoop) Read(p []byte) (int, error) {
+	n := copy(p, l.stream[l.offset:])
+	l.offset = (l.offset + n) % len(l.stream)
+	return n, nil
+}
+
+func TestReader(t *testing.T) {
+	var stream bytes.Buffer
+	for _, tc := range testCases {
+		stream.WriteString("xd")
+		stream.Write(tc.frame)
+	}
+
+	reader := frames.NewReader(&stream, frames.Codec{}, frames.Limits{})
+	for i, tc := range testCases {
+		got, err := reader.ReadFrame()
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+
+		if !bytes.Equal(got, tc.frame) {
+			t.Errorf("test %d: got frame % x, want frame % x", i, []byte(got), tc.frame)
+		}
+	}
+
+	if _, err := reader.ReadFrame(); err != io.EOF {
+		t.Errorf("got error %v, want %v", err, io.EOF)
+	}
+}
+
+func TestReaderReadFrameInto(t *testing.T) {
+	long := frames.Create([2]byte{'L', 'D'}, bytes.Repeat([]byte{'x'}, 20))
+	short := frames.Create([2]byte{'M', 'T'}, []byte{0x01})
+
+	reader := frames.NewReader(bytes.NewReader(append(long, short...)), frames.Codec{}, frames.Limits{})
+
+	buf := make([]byte, 16)
+	if _, err := reader.ReadFrameInto(buf); !errors.Is(err, io.ErrShortBuffer) {
+		t.Fatalf("got error %v, want %v", err, io.ErrShortBuffer)
+	}
+
+	// the frame is kept for a larger buffer
+	buf = make([]byte, 32)
+	got, err := reader.ReadFrameView(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, long) || &got[0] != &buf[0] {
+		t.Errorf("got frame % x, want frame % x in the buffer", []byte(got), []byte(long))
+	}
+
+	n, err := reader.ReadFrameInto(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf[:n], short) {
+		t.Errorf("got frame % x, want frame % x", buf[:n], []byte(short))
+	}
+}
+
+func TestReaderReadFrameIntoAllocs(t *testing.T) {
+	var stream []byte
+	for _, tc := range testCases {
+		stream = append(stream, tc.frame...)
+	}
+
+	reader := frames.NewReader(&frameLoop{stream: stream}, frames.Codec{}, frames.Limits{})
+	buf := make([]byte, 512)
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		if _, err := reader.ReadFrameInto(buf); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations per frame, want 0", allocs)
+	}
+}
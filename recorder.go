@@ -0,0 +1,125 @@
+package frames
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// Direction tells whether a frame was received or sent.
+type Direction int
+
+// Directions of a frame.
+const (
+	Received Direction = iota
+	Sent
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Received:
+		return "rx"
+	case Sent:
+		return "tx"
+	default:
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+}
+
+// Record is a frame captured at a point in time.
+type Record struct {
+	Time      time.Time
+	Direction Direction
+	Frame     Frame
+}
+
+// FlightRecorder keeps the most recent received and sent frames in memory, so
+// that they can be dumped when something goes wrong, e.g. on panic or on
+// SIGQUIT. Once full, every new frame replaces the oldest one.
+//
+// FlightRecorder is safe for concurrent use.
+type FlightRecorder struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+}
+
+// NewFlightRecorder creates a FlightRecorder keeping the last n frames.
+func NewFlightRecorder(n int) *FlightRecorder {
+	return &FlightRecorder{records: make([]Record, n)}
+}
+
+// Record stores a copy of frame, timestamped with the current time.
+func (r *FlightRecorder) Record(direction Direction, frame Frame) {
+	record := Record{Time: time.Now(), Direction: direction, Frame: Recreate(frame)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.records) == 0 {
+		return
+	}
+
+	r.records[r.next] = record
+	r.next = (r.next + 1) % len(r.records)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Records returns the stored records, oldest first.
+func (r *FlightRecorder) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]Record(nil), r.records[:r.next]...)
+	}
+
+	return append(append([]Record(nil), r.records[r.next:]...), r.records[:r.next]...)
+}
+
+// Dump writes the stored records to w, oldest first, one per line: the time in
+// RFC 3339 format, the direction and the frame's bytes in hex.
+func (r *FlightRecorder) Dump(w io.Writer) error {
+	for _, record := range r.Records() {
+		_, err := fmt.Fprintf(w, "%s %s %s\n", record.Time.Format(time.RFC3339Nano), record.Direction, hex.EncodeToString(record.Frame))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DumpOnSignal dumps the stored records to w every time one of signals, e.g.
+// syscall.SIGQUIT, is received. Calling the returned function stops it.
+func (r *FlightRecorder) DumpOnSignal(w io.Writer, signals ...os.Signal) (stop func()) {
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, signals...)
+
+	go func() {
+		for {
+			select {
+			case <-c:
+				r.Dump(w)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
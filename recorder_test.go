@@ -0,0 +1,51 @@
+package frames_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	recorder := frames.NewFlightRecorder(3)
+
+	for i, tc := range testCases {
+		direction := frames.Received
+		if i%2 == 1 {
+			direction = frames.Sent
+		}
+		recorder.Record(direction, tc.frame)
+	}
+
+	records := recorder.Records()
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	for i, record := range records {
+		tc := testCases[len(testCases)-3+i]
+		if !bytes.Equal(record.Frame, tc.frame) {
+			t.Errorf("got frame % x, want frame % x", []byte(record.Frame), tc.frame)
+		}
+
+		if i > 0 && record.Time.Before(records[i-1].Time) {
+			t.Errorf("records not ordered by time")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := recorder.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+
+	if !strings.HasSuffix(lines[2], " tx 4d54052b646f6e64752360") {
+		t.Errorf("got line %q, want it to end with the last frame", lines[2])
+	}
+}
@@ -0,0 +1,90 @@
+package frames
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+// RedundantWriter writes every frame several times, to let a receiver without
+// a back channel recover frames lost to corruption. Copies of a frame carry the
+// same 2-byte big-endian sequence number prepended to its data, so that a
+// RedundantReader can deliver only the first valid copy.
+//
+// RedundantWriter is safe for concurrent use.
+type RedundantWriter struct {
+	w      io.Writer
+	copies int
+
+	mu  sync.Mutex
+	seq uint16
+}
+
+// NewRedundantWriter creates a RedundantWriter writing copies copies of every
+// frame to w.
+func NewRedundantWriter(w io.Writer, copies int) *RedundantWriter {
+	return &RedundantWriter{w: w, copies: copies}
+}
+
+// WriteFrame writes the copies of frame. Frame's data length plus 2 must not
+// overflow byte.
+func (rw *RedundantWriter) WriteFrame(frame Frame) error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	var header [2]byte
+	copy(header[:], frame.Header())
+
+	data := make([]byte, 2, 2+len(frame.Data()))
+	binary.BigEndian.PutUint16(data, rw.seq)
+	sequenced := Create(header, append(data, frame.Data()...))
+	rw.seq++
+
+	for i := 0; i < rw.copies; i++ {
+		if _, err := rw.w.Write(sequenced); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RedundantReader reads frames written by a RedundantWriter, delivering only
+// the first valid copy of every frame.
+type RedundantReader struct {
+	reader *frameReader
+	dedup  *Dedup
+}
+
+// NewRedundantReader creates a RedundantReader reading from r. It remembers the
+// sequence numbers of the last window frames, which should be a few times
+// larger than the number of copies.
+func NewRedundantReader(r io.Reader, window int) *RedundantReader {
+	return &RedundantReader{
+		reader: newFrameReader(r, Codec{}, Limits{}),
+		dedup: NewDedup(window, func(frame Frame) uint64 {
+			return uint64(binary.BigEndian.Uint16(frame.Data()))
+		}),
+	}
+}
+
+// ReadFrame returns the next frame that is not a copy of an already returned
+// one, with the sequence number removed from its data. Frames too short to
+// carry a sequence number are skipped.
+func (rr *RedundantReader) ReadFrame() (Frame, error) {
+	for {
+		frame, err := rr.reader.ReadFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(frame.Data()) < 2 || rr.dedup.Duplicate(frame) {
+			continue
+		}
+
+		var header [2]byte
+		copy(header[:], frame.Header())
+
+		return Create(header, frame.Data()[2:]), nil
+	}
+}
@@ -0,0 +1,50 @@
+package frames_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestRedundant(t *testing.T) {
+	var buf bytes.Buffer
+	writer := frames.NewRedundantWriter(&buf, 3)
+
+	var want []frames.Frame
+	for _, tc := range testCases {
+		if len(tc.inputData)+2 > 255 {
+			continue
+		}
+
+		if err := writer.WriteFrame(tc.frame); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, tc.frame)
+	}
+
+	// corrupt the checksums of the first copy of the first frame and of the
+	// first two copies of the second frame
+	stream := buf.Bytes()
+	first, second := len(want[0])+2, len(want[1])+2
+	stream[first-1] ^= 0xff
+	stream[3*first+second-1] ^= 0xff
+	stream[3*first+2*second-1] ^= 0xff
+
+	reader := frames.NewRedundantReader(bytes.NewReader(stream), 8)
+	for i, frame := range want {
+		got, err := reader.ReadFrame()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+
+		if !bytes.Equal(got, frame) {
+			t.Errorf("frame %d: got frame % x, want frame % x", i, []byte(got), []byte(frame))
+		}
+	}
+
+	if _, err := reader.ReadFrame(); err != io.EOF {
+		t.Errorf("got error %v, want %v", err, io.EOF)
+	}
+}
@@ -0,0 +1,149 @@
+package frames
+
+import (
+	"errors"
+	"sync"
+)
+
+// remapWildcard matches any header byte in a RemapTable rule.
+const remapWildcard = '?'
+
+// ErrRemapPattern is returned by RemapTable.Add when a header pattern is
+// invalid.
+var ErrRemapPattern = errors.New("frames: invalid header pattern")
+
+// RemapTable rewrites frame headers according to rules that can be added and
+// removed at runtime, e.g. to let devices with conflicting header conventions
+// share a link, or to replay old captures whose headers were renamed.
+//
+// A rule maps a header pattern to a replacement pattern. Both are 2 bytes
+// long, and every byte is either a valid header byte or a wildcard "?". In the
+// header pattern, the wildcard matches any byte; in the replacement, it keeps
+// the matched byte. For example the rule "L?" to "X?" renames LD to XD and L1
+// to X1.
+//
+// If several rules match a header, the one with fewer wildcards wins, then the
+// one added first.
+//
+// The zero value is an empty RemapTable ready to use. RemapTable is safe for
+// concurrent use.
+type RemapTable struct {
+	mu    sync.RWMutex
+	rules []remapRule
+}
+
+type remapRule struct {
+	from [2]byte
+	to   [2]byte
+}
+
+// Add adds a rule rewriting headers matching from according to to. A rule with
+// the same from pattern is replaced.
+func (t *RemapTable) Add(from, to string) error {
+	if !validRemapPattern(from) || !validRemapPattern(to) {
+		return ErrRemapPattern
+	}
+
+	rule := remapRule{}
+	copy(rule.from[:], from)
+	copy(rule.to[:], to)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.rules {
+		if t.rules[i].from == rule.from {
+			t.rules[i] = rule
+			return nil
+		}
+	}
+	t.rules = append(t.rules, rule)
+
+	return nil
+}
+
+// Remove removes the rule with the from pattern. It reports whether the rule
+// existed.
+func (t *RemapTable) Remove(from string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, rule := range t.rules {
+		if string(rule.from[:]) == from {
+			t.rules = append(t.rules[:i], t.rules[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Lookup returns the header replacing header, and whether any rule matched.
+func (t *RemapTable) Lookup(header [2]byte) ([2]byte, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	best, bestWildcards := -1, 3
+	for i, rule := range t.rules {
+		wildcards := 0
+		matches := true
+		for j, b := range rule.from {
+			if b == remapWildcard {
+				wildcards++
+			} else if b != header[j] {
+				matches = false
+			}
+		}
+
+		if matches && wildcards < bestWildcards {
+			best, bestWildcards = i, wildcards
+		}
+	}
+
+	if best < 0 {
+		return header, false
+	}
+
+	to := t.rules[best].to
+	for j, b := range to {
+		if b == remapWildcard {
+			to[j] = header[j]
+		}
+	}
+
+	return to, true
+}
+
+// Rewrite returns frame with its header replaced according to the table, and
+// its checksum recalculated. Frames with headers not matching any rule and
+// frames shorter than an empty frame are returned unchanged.
+func (t *RemapTable) Rewrite(frame Frame) Frame {
+	if len(frame) < minFrameLen {
+		return frame
+	}
+
+	var header [2]byte
+	copy(header[:], frame.Header())
+
+	to, ok := t.Lookup(header)
+	if !ok {
+		return frame
+	}
+
+	return rewriteHeader(frame, to)
+}
+
+// validRemapPattern reports whether pattern is a valid RemapTable pattern.
+func validRemapPattern(pattern string) bool {
+	if len(pattern) != 2 {
+		return false
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != remapWildcard && !validHeaderByte(pattern[i]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,71 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestRemapTable(t *testing.T) {
+	var table frames.RemapTable
+
+	for _, rule := range [][2]string{
+		{"L?", "X?"},
+		{"?1", "?9"},
+		{"LD", "DL"},
+		{"??", "ZZ"},
+		{"M1", "M2"},
+	} {
+		if err := table.Add(rule[0], rule[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{"LD", "DL", true},
+		{"L1", "X1", true}, // both wildcard rules match, the first one wins
+		{"A1", "A9", true},
+		{"M1", "M2", true},
+		{"QQ", "ZZ", true},
+	}
+
+	for _, tc := range tests {
+		var header [2]byte
+		copy(header[:], tc.header)
+
+		got, ok := table.Lookup(header)
+		if string(got[:]) != tc.want || ok != tc.ok {
+			t.Errorf("%s: got %s, %t, want %s, %t", tc.header, got[:], ok, tc.want, tc.ok)
+		}
+	}
+
+	// rules can be replaced and removed at runtime
+	if err := table.Add("M1", "M3"); err != nil {
+		t.Fatal(err)
+	}
+	if !table.Remove("??") || table.Remove("??") {
+		t.Error("got unexpected result of removing a rule")
+	}
+
+	frame := frames.Create([2]byte{'M', '1'}, []byte("abc"))
+	if got, want := table.Rewrite(frame), frames.Create([2]byte{'M', '3'}, []byte("abc")); !bytes.Equal(got, want) {
+		t.Errorf("got frame % x, want frame % x", []byte(got), []byte(want))
+	}
+
+	frame = frames.Create([2]byte{'Q', 'Q'}, []byte("abc"))
+	if got := table.Rewrite(frame); !bytes.Equal(got, frame) {
+		t.Errorf("got frame % x, want unchanged frame % x", []byte(got), []byte(frame))
+	}
+
+	for _, pattern := range []string{"L", "LDX", "l?", "L*"} {
+		if err := table.Add(pattern, "XX"); !errors.Is(err, frames.ErrRemapPattern) {
+			t.Errorf("%q: got error %v, want error %v", pattern, err, frames.ErrRemapPattern)
+		}
+	}
+}
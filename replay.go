@@ -0,0 +1,104 @@
+package frames
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// ErrRecordFormat is returned by ReadRecords when a line is not a valid record.
+var ErrRecordFormat = errors.New("frames: invalid record")
+
+// ReadRecords reads records in the format written by FlightRecorder.Dump.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	var records []Record
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, ErrRecordFormat
+		}
+
+		var record Record
+		var err error
+		if record.Time, err = time.Parse(time.RFC3339Nano, fields[0]); err != nil {
+			return nil, ErrRecordFormat
+		}
+
+		switch fields[1] {
+		case Received.String():
+			record.Direction = Received
+		case Sent.String():
+			record.Direction = Sent
+		default:
+			return nil, ErrRecordFormat
+		}
+
+		if record.Frame, err = hex.DecodeString(fields[2]); err != nil {
+			return nil, ErrRecordFormat
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+// ReplayConfig configures Replay.
+type ReplayConfig struct {
+	// Speed scales the original timing, e.g. 2 replays records twice as fast.
+	// Zero means the original speed.
+	Speed float64
+
+	// Jitter, if not zero, shifts every frame by a random duration from
+	// -Jitter to Jitter. Frames are never reordered.
+	Jitter time.Duration
+
+	// Seed seeds the jitter, so that a replay can be repeated with identical
+	// timing.
+	Seed int64
+}
+
+// Replay writes the frames of records to w, honoring the original time
+// between them, scaled according to config. The first frame is written
+// immediately. Frames are scheduled relative to the start of the replay, so
+// that delays do not accumulate.
+func Replay(w io.Writer, records []Record, config ReplayConfig) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	speed := config.Speed
+	if speed == 0 {
+		speed = 1
+	}
+	random := rand.New(rand.NewSource(config.Seed))
+
+	start := time.Now()
+	var previous time.Duration
+	for _, record := range records {
+		offset := time.Duration(float64(record.Time.Sub(records[0].Time)) / speed)
+		if config.Jitter > 0 {
+			offset += time.Duration(random.Int63n(2*int64(config.Jitter)+1)) - config.Jitter
+		}
+		if offset < previous {
+			offset = previous
+		}
+		previous = offset
+
+		time.Sleep(time.Until(start.Add(offset)))
+		if _, err := w.Write(record.Frame); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
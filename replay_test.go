@@ -0,0 +1,83 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestReadRecords(t *testing.T) {
+	recorder := frames.NewFlightRecorder(len(testCases))
+	for _, tc := range testCases {
+		recorder.Record(frames.Sent, tc.frame)
+	}
+
+	var buf bytes.Buffer
+	if err := recorder.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := frames.ReadRecords(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := recorder.Records()
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+
+	for i, record := range records {
+		if !record.Time.Equal(want[i].Time) || record.Direction != want[i].Direction || !bytes.Equal(record.Frame, want[i].Frame) {
+			t.Errorf("got record %+v, want record %+v", record, want[i])
+		}
+	}
+
+	if _, err := frames.ReadRecords(strings.NewReader("yesterday rx 4c44002b2300\n")); !errors.Is(err, frames.ErrRecordFormat) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrRecordFormat)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	start := time.Now()
+	var records []frames.Record
+	for i, tc := range testCases {
+		records = append(records, frames.Record{
+			Time:      start.Add(time.Duration(i) * 10 * time.Millisecond),
+			Direction: frames.Received,
+			Frame:     tc.frame,
+		})
+	}
+
+	for _, config := range []frames.ReplayConfig{
+		{},
+		{Speed: 2},
+		{Speed: 2, Jitter: 2 * time.Millisecond, Seed: 1},
+	} {
+		var r deliveryRecorder
+		begin := time.Now()
+		if err := frames.Replay(&r, records, config); err != nil {
+			t.Fatal(err)
+		}
+
+		speed := config.Speed
+		if speed == 0 {
+			speed = 1
+		}
+		total := time.Duration(float64(records[len(records)-1].Time.Sub(start))/speed) - config.Jitter
+
+		if elapsed := r.times[len(r.times)-1].Sub(begin); elapsed < total {
+			t.Errorf("config %+v: got replay taking %v, want at least %v", config, elapsed, total)
+		}
+
+		for i, seq := range r.seqs {
+			if seq != testCases[i].frame[0] {
+				t.Errorf("config %+v: got frame starting with %#02x at position %d, want %#02x", config, seq, i, testCases[i].frame[0])
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package frames
+
+import (
+	"sync"
+	"time"
+)
+
+// SamplerConfig configures a Sampler. Zero values disable the respective
+// policies.
+type SamplerConfig struct {
+	// EveryN keeps only every Nth frame.
+	EveryN int
+
+	// MaxPerSecond limits the number of frames kept in every second.
+	MaxPerSecond int
+
+	// KeepInvalid keeps all invalid frames, regardless of the other policies,
+	// so that errors are never missed.
+	KeepInvalid bool
+}
+
+// Sampler selects frames to be logged or recorded, so that high-volume traffic
+// does not fill the disk. Its Keep method is a Filter, e.g. for use with
+// TeeReader:
+//
+//	sampler := frames.NewSampler(frames.SamplerConfig{EveryN: 10, KeepInvalid: true})
+//	read := frames.TeeReader(session.ReadFrame, func(frame frames.Frame) {
+//		if sampler.Keep(frame) {
+//			recorder.Record(frames.Received, frame)
+//		}
+//	})
+//
+// Sampler is safe for concurrent use.
+type Sampler struct {
+	config SamplerConfig
+
+	mu     sync.Mutex
+	seen   int
+	window time.Time
+	kept   int
+}
+
+// NewSampler creates a Sampler applying the policies of config.
+func NewSampler(config SamplerConfig) *Sampler {
+	return &Sampler{config: config}
+}
+
+// Keep reports whether frame should be kept.
+func (s *Sampler) Keep(frame Frame) bool {
+	if s.config.KeepInvalid && !Verify(frame) {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seen++
+	if s.config.EveryN > 1 && s.seen%s.config.EveryN != 1 {
+		return false
+	}
+
+	if s.config.MaxPerSecond > 0 {
+		now := time.Now()
+		if now.Sub(s.window) >= time.Second {
+			s.window = now
+			s.kept = 0
+		}
+
+		if s.kept >= s.config.MaxPerSecond {
+			return false
+		}
+		s.kept++
+	}
+
+	return true
+}
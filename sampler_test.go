@@ -0,0 +1,45 @@
+package frames_test
+
+import (
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestSampler(t *testing.T) {
+	valid := frames.Create([2]byte{'L', 'D'}, []byte("test"))
+	invalid := frames.Recreate(valid)
+	invalid[len(invalid)-1] ^= 0xff
+
+	tests := []struct {
+		config frames.SamplerConfig
+		frame  frames.Frame
+		n      int
+		wantN  int
+		name   string
+	}{
+		{config: frames.SamplerConfig{}, frame: valid, n: 10, wantN: 10, name: "no policies"},
+		{config: frames.SamplerConfig{EveryN: 3}, frame: valid, n: 10, wantN: 4, name: "every 3rd frame"},
+		{config: frames.SamplerConfig{MaxPerSecond: 5}, frame: valid, n: 10, wantN: 5, name: "at most 5 per second"},
+		{config: frames.SamplerConfig{EveryN: 2, MaxPerSecond: 3}, frame: valid, n: 10, wantN: 3, name: "both policies"},
+		{config: frames.SamplerConfig{EveryN: 3, MaxPerSecond: 1}, frame: invalid, n: 10, wantN: 1, name: "invalid frames sampled"},
+		{config: frames.SamplerConfig{EveryN: 3, MaxPerSecond: 1, KeepInvalid: true}, frame: invalid, n: 10, wantN: 10, name: "invalid frames kept"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sampler := frames.NewSampler(test.config)
+
+			var kept int
+			for i := 0; i < test.n; i++ {
+				if sampler.Keep(test.frame) {
+					kept++
+				}
+			}
+
+			if kept != test.wantN {
+				t.Errorf("got %d frames kept, want %d", kept, test.wantN)
+			}
+		})
+	}
+}
@@ -0,0 +1,749 @@
+package frames
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default backoff between reconnection attempts of a Session.
+const (
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 10 * time.Second
+)
+
+// DefaultMaxUnacknowledged is the default number of unacknowledged frames kept
+// by a Session, see SessionConfig.Acknowledges.
+const DefaultMaxUnacknowledged = 256
+
+// berWindow is the number of observations a Session's bit error rate is
+// estimated from.
+const berWindow = 1000
+
+// ErrSessionClosed is returned by Session's methods after it is closed.
+var ErrSessionClosed = errors.New("frames: session closed")
+
+// ErrExpired is returned by Session.WriteFrameBefore when the frame's deadline
+// passes before it is written.
+var ErrExpired = errors.New("frames: frame expired")
+
+// Stats holds counters and estimates describing the traffic of a Session.
+type Stats struct {
+	FramesRead    uint64
+	FramesWritten uint64
+	BytesRead     uint64
+	BytesWritten  uint64
+
+	// DataBytesRead counts bytes of data of read frames, without headers,
+	// checksums and other framing.
+	DataBytesRead uint64
+
+	// SkippedBytes counts received bytes that were not part of any valid
+	// frame.
+	SkippedBytes uint64
+
+	// Reconnects counts successful reconnections.
+	Reconnects uint64
+
+	// Expired counts frames dropped by WriteFrameBefore.
+	Expired uint64
+
+	// CorrectedFrames counts read frames repaired with Codec.Correct.
+	CorrectedFrames uint64
+
+	// Replayed counts unacknowledged frames written again after
+	// reconnecting, see SessionConfig.Acknowledges. They are counted in
+	// FramesWritten and BytesWritten too.
+	Replayed uint64
+
+	// Dropped counts unacknowledged frames dropped because more than
+	// SessionConfig.MaxUnacknowledged frames were kept.
+	Dropped uint64
+
+	// BitErrorRate is estimated from the most recently received frames and
+	// skipped bytes, see BitErrorEstimator.
+	BitErrorRate float64
+}
+
+// SessionConfig configures a Session.
+type SessionConfig struct {
+	// Dial opens a new connection to the peer, e.g. a serial port or a TCP
+	// connection.
+	Dial func() (io.ReadWriteCloser, error)
+
+	// Capabilities are advertised to the peer in the handshake performed on
+	// every connection.
+	Capabilities Capabilities
+
+	// MinBackoff and MaxBackoff limit the delay between reconnection
+	// attempts. The delay starts at MinBackoff and doubles after every failed
+	// attempt, up to MaxBackoff. Zero values mean DefaultMinBackoff and
+	// DefaultMaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Limits guard reading frames. If a limit is exceeded with LimitAbort, the
+	// session reconnects.
+	Limits Limits
+
+	// CorrectErrors enables repairing received frames with single-bit errors,
+	// see Codec.Correct.
+	CorrectErrors bool
+
+	// Source identifies the session in envelopes returned by ReadEnvelope.
+	Source string
+
+	// Acknowledges, if not nil, makes the session keep written frames until
+	// the peer acknowledges them. Frames still kept when the connection fails
+	// are written again, in order, on the next connection, before any other
+	// frame. Acknowledges is called with every frame read from the peer and
+	// returns how many of the oldest kept frames it acknowledges, e.g. 1 for
+	// an acknowledgement of a peer acknowledging frames one by one, and 0 for
+	// frames which are not acknowledgements. Acknowledgements are therefore
+	// processed only while the application reads frames.
+	Acknowledges func(frame Frame) int
+
+	// MaxUnacknowledged limits the number of kept frames. When it is
+	// exceeded, the oldest frame is dropped. Zero means
+	// DefaultMaxUnacknowledged.
+	MaxUnacknowledged int
+}
+
+// Session is a link to a peer that survives connection failures. It owns the
+// connection: it dials it, performs the Handshake, and when reading or writing
+// fails, it reconnects with exponential backoff and carries on.
+//
+// A frame whose write failed is written again after reconnecting. Frames
+// written to a connection which failed afterwards may not have reached the
+// peer; if the peer acknowledges frames, see SessionConfig.Acknowledges, the
+// unacknowledged ones are written again too, so the peer receives every frame
+// at least once. Otherwise they are lost. Session is safe for concurrent use.
+type Session struct {
+	stats Stats // first field for 64-bit alignment of atomic operations
+
+	config  SessionConfig
+	done    chan struct{}
+	ber     *BitErrorEstimator
+	created time.Time
+
+	// mu guards the connection and the fields describing it
+	mu          sync.Mutex
+	conn        io.ReadWriteCloser
+	reader      *frameReader
+	negotiation Negotiation
+	generation  int
+	closed      bool
+	unacked     []Frame // written frames not acknowledged yet, oldest first
+
+	// pending counts writes in progress, draining is set by Shutdown, and
+	// idle, if not nil, is closed when pending drops to zero
+	pending  int
+	draining bool
+	idle     chan struct{}
+
+	// reconnecting and writing hold a token while a reconnection or a write
+	// is in progress, making sure that only one happens at a time. Unlike
+	// mutexes, waiting for them can be abandoned, see acquire. A reconnection
+	// takes the write token too while it connects, so that kept frames are
+	// written again before any other frame.
+	reconnecting chan struct{}
+	writing      chan struct{}
+
+	// countedMu guards the time since which the counters count
+	countedMu sync.Mutex
+	counted   time.Time
+}
+
+// NewSession dials the first connection and performs the handshake. Errors
+// returned by the first attempt are returned immediately, without retrying.
+func NewSession(config SessionConfig) (*Session, error) {
+	if config.MinBackoff == 0 {
+		config.MinBackoff = DefaultMinBackoff
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.MaxUnacknowledged == 0 {
+		config.MaxUnacknowledged = DefaultMaxUnacknowledged
+	}
+
+	s := &Session{
+		config:       config,
+		done:         make(chan struct{}),
+		ber:          NewBitErrorEstimator(berWindow),
+		created:      time.Now(),
+		reconnecting: make(chan struct{}, 1),
+		writing:      make(chan struct{}, 1),
+	}
+	s.counted = s.created
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Negotiation returns the outcome of the handshake on the current connection.
+func (s *Session) Negotiation() Negotiation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.negotiation
+}
+
+// WriteFrame writes frame. If writing fails, WriteFrame reconnects and tries
+// again, so it blocks until frame is written or the session is closed.
+//
+// Frame is given in the default format. If the handshake negotiated another
+// codec, frame is re-encoded with the codec of the connection it is written to,
+// so it is encoded correctly even if a reconnection negotiated a different one.
+// In that case, if frame is invalid, WriteFrame returns a *VerifyError. If the
+// default codec was negotiated, frame is written as it is.
+func (s *Session) WriteFrame(frame Frame) error {
+	return s.WriteFrameBefore(frame, time.Time{})
+}
+
+// WriteFrameBefore works like WriteFrame, but drops frame if it has not been
+// written before deadline, e.g. because the link stalled or other frames were
+// waiting to be written. In that case it returns ErrExpired. A zero deadline
+// means no deadline.
+//
+// The deadline is observed while waiting for other writes and for the link to
+// come back. If the connection has a SetWriteDeadline method, like net.Conn,
+// the deadline is set on it too, so that a write blocked by the peer is
+// abandoned; otherwise such a write may finish after the deadline. Dialing and
+// the handshake are not interrupted.
+func (s *Session) WriteFrameBefore(frame Frame, deadline time.Time) error {
+	if err := s.beginWrite(); err != nil {
+		return err
+	}
+	defer s.endWrite()
+
+	err := s.writeFrame(frame, deadline)
+	if errors.Is(err, ErrExpired) {
+		atomic.AddUint64(&s.stats.Expired, 1)
+	}
+
+	return err
+}
+
+// writeFrame implements WriteFrameBefore.
+func (s *Session) writeFrame(frame Frame, deadline time.Time) error {
+	var expired <-chan time.Time
+	if !deadline.IsZero() {
+		if !time.Now().Before(deadline) {
+			return ErrExpired
+		}
+
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return ErrExpired
+		}
+
+		generation, failed, err := s.tryWrite(frame, deadline, expired)
+		if !failed {
+			return err
+		}
+
+		if err := s.reconnect(generation, expired); err != nil {
+			return err
+		}
+	}
+}
+
+// tryWrite writes frame to the current connection once. If the connection
+// fails, it returns its generation and true.
+func (s *Session) tryWrite(frame Frame, deadline time.Time, expired <-chan time.Time) (generation int, failed bool, err error) {
+	if err := s.acquire(s.writing, expired); err != nil {
+		return 0, false, err
+	}
+	defer func() { <-s.writing }()
+
+	conn, reader, generation, err := s.current()
+	if err != nil {
+		return 0, false, err
+	}
+
+	encoded, err := encode(frame, reader.codec)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if d, ok := conn.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		d.SetWriteDeadline(deadline)
+	}
+
+	if _, err := conn.Write(encoded); err != nil {
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			// a part of the frame may have been written, the peer skips it
+			return 0, false, ErrExpired
+		}
+		return generation, true, nil
+	}
+
+	atomic.AddUint64(&s.stats.FramesWritten, 1)
+	atomic.AddUint64(&s.stats.BytesWritten, uint64(len(encoded)))
+	if s.config.Acknowledges != nil {
+		s.keep(frame)
+	}
+
+	return 0, false, nil
+}
+
+// keep keeps a copy of frame until the peer acknowledges it, dropping the
+// oldest kept frame if there are too many.
+func (s *Session) keep(frame Frame) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.unacked) >= s.config.MaxUnacknowledged {
+		s.unacked[0] = nil
+		s.unacked = s.unacked[1:]
+		atomic.AddUint64(&s.stats.Dropped, 1)
+	}
+	s.unacked = append(s.unacked, append(Frame(nil), frame...))
+}
+
+// acknowledge forgets the n oldest kept frames.
+func (s *Session) acknowledge(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n > len(s.unacked) {
+		n = len(s.unacked)
+	}
+	for i := 0; i < n; i++ {
+		s.unacked[i] = nil
+	}
+	s.unacked = s.unacked[n:]
+}
+
+// Unacknowledged returns the number of written frames kept until the peer
+// acknowledges them, see SessionConfig.Acknowledges.
+func (s *Session) Unacknowledged() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.unacked)
+}
+
+// encode returns frame, which is in the default format, encoded with codec.
+func encode(frame Frame, codec Codec) (Frame, error) {
+	if codec.isZero() {
+		return frame, nil
+	}
+
+	return Convert(frame, Codec{}, codec)
+}
+
+// decode returns frame, which is encoded with codec, in the default format.
+func decode(frame Frame, codec Codec) (Frame, error) {
+	if codec.isZero() {
+		return frame, nil
+	}
+
+	return Convert(frame, codec, Codec{})
+}
+
+// Expired returns the number of frames dropped by WriteFrameBefore because
+// their deadline passed.
+func (s *Session) Expired() uint64 {
+	return atomic.LoadUint64(&s.stats.Expired)
+}
+
+// Stats returns the current values of the session's counters.
+func (s *Session) Stats() Stats {
+	return Stats{
+		FramesRead:      atomic.LoadUint64(&s.stats.FramesRead),
+		FramesWritten:   atomic.LoadUint64(&s.stats.FramesWritten),
+		BytesRead:       atomic.LoadUint64(&s.stats.BytesRead),
+		BytesWritten:    atomic.LoadUint64(&s.stats.BytesWritten),
+		DataBytesRead:   atomic.LoadUint64(&s.stats.DataBytesRead),
+		SkippedBytes:    atomic.LoadUint64(&s.stats.SkippedBytes),
+		Reconnects:      atomic.LoadUint64(&s.stats.Reconnects),
+		Expired:         atomic.LoadUint64(&s.stats.Expired),
+		CorrectedFrames: atomic.LoadUint64(&s.stats.CorrectedFrames),
+		Replayed:        atomic.LoadUint64(&s.stats.Replayed),
+		Dropped:         atomic.LoadUint64(&s.stats.Dropped),
+		BitErrorRate:    s.ber.BitErrorRate(),
+	}
+}
+
+// SnapshotAndReset returns the current values of the session's counters and
+// zeroes them, so that periodic reporters can report every interval
+// separately. Every counter is swapped atomically, so that no event is lost or
+// counted twice.
+func (s *Session) SnapshotAndReset() Stats {
+	s.countedMu.Lock()
+	s.counted = time.Now()
+	s.countedMu.Unlock()
+
+	return Stats{
+		FramesRead:      atomic.SwapUint64(&s.stats.FramesRead, 0),
+		FramesWritten:   atomic.SwapUint64(&s.stats.FramesWritten, 0),
+		BytesRead:       atomic.SwapUint64(&s.stats.BytesRead, 0),
+		BytesWritten:    atomic.SwapUint64(&s.stats.BytesWritten, 0),
+		DataBytesRead:   atomic.SwapUint64(&s.stats.DataBytesRead, 0),
+		SkippedBytes:    atomic.SwapUint64(&s.stats.SkippedBytes, 0),
+		Reconnects:      atomic.SwapUint64(&s.stats.Reconnects, 0),
+		Expired:         atomic.SwapUint64(&s.stats.Expired, 0),
+		CorrectedFrames: atomic.SwapUint64(&s.stats.CorrectedFrames, 0),
+		Replayed:        atomic.SwapUint64(&s.stats.Replayed, 0),
+		Dropped:         atomic.SwapUint64(&s.stats.Dropped, 0),
+		BitErrorRate:    s.ber.BitErrorRate(),
+	}
+}
+
+// LinkQuality summarizes the state of a Session's link. Rates are averaged
+// over the time since the session was created, or since its counters were
+// last reset with SnapshotAndReset.
+type LinkQuality struct {
+	// Uptime is the time since the session was created.
+	Uptime time.Duration
+
+	// FramesPerSecond is the number of frames read and written per second.
+	FramesPerSecond float64
+
+	// Goodput is the number of data bytes of read frames per second.
+	Goodput float64
+
+	// ErrorRatio is the ratio of skipped bytes to all received bytes.
+	ErrorRatio float64
+}
+
+// LinkQuality returns a summary of the session's link, calculated from its
+// counters.
+func (s *Session) LinkQuality() LinkQuality {
+	s.countedMu.Lock()
+	counted := s.counted
+	s.countedMu.Unlock()
+
+	stats := s.Stats()
+	now := time.Now()
+	q := LinkQuality{Uptime: now.Sub(s.created)}
+
+	if seconds := now.Sub(counted).Seconds(); seconds > 0 {
+		q.FramesPerSecond = float64(stats.FramesRead+stats.FramesWritten) / seconds
+		q.Goodput = float64(stats.DataBytesRead) / seconds
+	}
+
+	if received := stats.BytesRead + stats.SkippedBytes; received > 0 {
+		q.ErrorRatio = float64(stats.SkippedBytes) / float64(received)
+	}
+
+	return q
+}
+
+// published maps names passed to Session.Publish to the sessions most
+// recently published under them.
+var published struct {
+	sync.Mutex
+	sessions map[string]*Session
+}
+
+// Publish publishes the session's counters with expvar under name, so that
+// they are served as a JSON object by the /debug/vars handler. Publishing
+// another session under the same name replaces the previous one, e.g. after
+// the application recreates its session. Like expvar.Publish, it panics if
+// name is already used by a variable not published by Publish.
+func (s *Session) Publish(name string) {
+	published.Lock()
+	defer published.Unlock()
+
+	if _, ok := published.sessions[name]; !ok {
+		expvar.Publish(name, expvar.Func(func() interface{} {
+			published.Lock()
+			session := published.sessions[name]
+			published.Unlock()
+			return session.Stats()
+		}))
+	}
+
+	if published.sessions == nil {
+		published.sessions = make(map[string]*Session)
+	}
+	published.sessions[name] = s
+}
+
+// ReadFrame returns the next valid frame received from the peer. If reading
+// fails, ReadFrame reconnects and tries again, so it blocks until a frame is
+// received or the session is closed.
+//
+// Like frames passed to WriteFrame, the returned frame is in the default
+// format, whichever codec the handshake negotiated, so its Header, Data and
+// Verify methods work as usual. Counters count the bytes actually received.
+func (s *Session) ReadFrame() (Frame, error) {
+	envelope, err := s.ReadEnvelope()
+	return envelope.Frame, err
+}
+
+// ReadEnvelope works like ReadFrame, but returns the frame in an Envelope.
+func (s *Session) ReadEnvelope() (Envelope, error) {
+	for {
+		_, reader, generation, err := s.current()
+		if err != nil {
+			return Envelope{}, err
+		}
+
+		frame, err := reader.ReadFrame()
+		if err == nil {
+			atomic.AddUint64(&s.stats.FramesRead, 1)
+			atomic.AddUint64(&s.stats.BytesRead, uint64(len(frame)))
+			atomic.AddUint64(&s.stats.DataBytesRead, uint64(len(reader.codec.Data(frame))))
+			s.ber.ObserveFrame(frame)
+
+			frame, err = decode(frame, reader.codec)
+			if err != nil {
+				return Envelope{}, err
+			}
+			if s.config.Acknowledges != nil {
+				s.acknowledge(s.config.Acknowledges(frame))
+			}
+
+			return Envelope{
+				Frame:     frame,
+				Time:      time.Now(),
+				Source:    s.config.Source,
+				Valid:     true,
+				Corrected: reader.corrected,
+			}, nil
+		}
+
+		if err := s.reconnect(generation, nil); err != nil {
+			return Envelope{}, err
+		}
+	}
+}
+
+// beginWrite registers a write in progress, unless the session is closed or
+// shutting down.
+func (s *Session) beginWrite() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.draining {
+		return ErrSessionClosed
+	}
+	s.pending++
+
+	return nil
+}
+
+// endWrite unregisters a write registered by beginWrite.
+func (s *Session) endWrite() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending--
+	if s.pending == 0 && s.idle != nil {
+		close(s.idle)
+		s.idle = nil
+	}
+}
+
+// Shutdown gracefully closes the session. New writes are rejected with
+// ErrSessionClosed immediately, while writes in progress are given time to
+// complete until ctx is done. Then the session is closed. If ctx is done first,
+// Shutdown returns the number of writes dropped because they were still in
+// progress, together with ctx's error.
+func (s *Session) Shutdown(ctx context.Context) (dropped int, err error) {
+	s.mu.Lock()
+	if s.closed || s.draining {
+		s.mu.Unlock()
+		return 0, ErrSessionClosed
+	}
+
+	s.draining = true
+	var idle chan struct{}
+	if s.pending > 0 {
+		s.idle = make(chan struct{})
+		idle = s.idle
+	}
+	s.mu.Unlock()
+
+	if idle != nil {
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			s.mu.Lock()
+			dropped = s.pending
+			s.mu.Unlock()
+
+			s.Close()
+			return dropped, ctx.Err()
+		}
+	}
+
+	return 0, s.Close()
+}
+
+// Close closes the session and its connection. Blocked calls to WriteFrame and
+// ReadFrame return ErrSessionClosed.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSessionClosed
+	}
+	s.closed = true
+	close(s.done)
+
+	return s.conn.Close()
+}
+
+// current returns the current connection, its reader and generation.
+func (s *Session) current() (io.ReadWriteCloser, *frameReader, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, nil, 0, ErrSessionClosed
+	}
+
+	return s.conn, s.reader, s.generation, nil
+}
+
+// acquire takes the token of sem, either s.reconnecting or s.writing, waiting
+// until it is released. If expired fires first, it returns ErrExpired. A nil
+// expired never fires.
+func (s *Session) acquire(sem chan struct{}, expired <-chan time.Time) error {
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-s.done:
+		return ErrSessionClosed
+	case <-expired:
+		return ErrExpired
+	}
+}
+
+// reconnect replaces the connection of the given generation with a new one,
+// retrying with backoff until it succeeds, the session is closed or expired
+// fires. In the last case it returns ErrExpired and leaves the connection
+// closed for the next caller to replace. If the connection has already been
+// replaced, it does nothing.
+func (s *Session) reconnect(generation int, expired <-chan time.Time) error {
+	if err := s.acquire(s.reconnecting, expired); err != nil {
+		return err
+	}
+	defer func() { <-s.reconnecting }()
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrSessionClosed
+	}
+	if s.generation != generation {
+		s.mu.Unlock()
+		return nil
+	}
+	s.conn.Close()
+	s.mu.Unlock()
+
+	backoff := s.config.MinBackoff
+	for {
+		select {
+		case <-s.done:
+			return ErrSessionClosed
+		case <-expired:
+			return ErrExpired
+		case <-time.After(backoff):
+		}
+
+		if err := s.acquire(s.writing, expired); err != nil {
+			return err
+		}
+		err := s.connect()
+		<-s.writing
+		if err == nil {
+			atomic.AddUint64(&s.stats.Reconnects, 1)
+			return nil
+		}
+		if errors.Is(err, ErrSessionClosed) {
+			return err
+		}
+
+		backoff *= 2
+		if backoff > s.config.MaxBackoff {
+			backoff = s.config.MaxBackoff
+		}
+	}
+}
+
+// connect dials a new connection, performs the handshake, writes the kept
+// frames again and makes the connection current. Except for the first
+// connection, the caller holds the write token.
+func (s *Session) connect() error {
+	conn, err := s.config.Dial()
+	if err != nil {
+		return err
+	}
+
+	negotiation, err := Handshake(conn, s.config.Capabilities)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	if err := s.replay(conn, negotiation.Codec); err != nil {
+		conn.Close()
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		conn.Close()
+		return ErrSessionClosed
+	}
+
+	s.conn = conn
+	s.reader = newFrameReader(conn, negotiation.Codec, s.config.Limits)
+	s.reader.onSkip = func(n int) {
+		atomic.AddUint64(&s.stats.SkippedBytes, uint64(n))
+		s.ber.ObserveSkipped(n)
+	}
+	s.reader.correct = s.config.CorrectErrors
+	s.reader.onCorrect = func() {
+		atomic.AddUint64(&s.stats.CorrectedFrames, 1)
+	}
+	s.negotiation = negotiation
+	s.generation++
+
+	return nil
+}
+
+// replay writes the kept frames to conn, encoded with codec. Kept frames that
+// cannot be encoded with codec, i.e. invalid ones, are skipped.
+func (s *Session) replay(conn io.Writer, codec Codec) error {
+	s.mu.Lock()
+	unacked := append([]Frame(nil), s.unacked...)
+	s.mu.Unlock()
+
+	for _, frame := range unacked {
+		encoded, err := encode(frame, codec)
+		if err != nil {
+			continue
+		}
+
+		if _, err := conn.Write(encoded); err != nil {
+			return err
+		}
+
+		atomic.AddUint64(&s.stats.FramesWritten, 1)
+		atomic.AddUint64(&s.stats.BytesWritten, uint64(len(encoded)))
+		atomic.AddUint64(&s.stats.Replayed, 1)
+	}
+
+	return nil
+}
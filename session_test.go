@@ -0,0 +1,668 @@
+package frames_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+var sessionCapabilities = frames.Capabilities{
+	Version:      1,
+	MaxFrameSize: 261,
+	Checksums:    []frames.ChecksumAlgorithm{frames.ChecksumXOR},
+}
+
+// echoPeer accepts connections dialed by a Session, performs the handshake and
+// echoes back everything it receives. Peer's ends of the connections are sent
+// to conns.
+func echoPeer(conns chan<- net.Conn) func() (io.ReadWriteCloser, error) {
+	return echoPeerWith(conns, sessionCapabilities)
+}
+
+// echoPeerWith works like echoPeer, but the peer advertises capabilities.
+func echoPeerWith(conns chan<- net.Conn, capabilities frames.Capabilities) func() (io.ReadWriteCloser, error) {
+	return func() (io.ReadWriteCloser, error) {
+		client, server := net.Pipe()
+		go func() {
+			if _, err := frames.Handshake(server, capabilities); err != nil {
+				server.Close()
+				return
+			}
+			conns <- server
+			io.Copy(server, server)
+		}()
+
+		return client, nil
+	}
+}
+
+func TestSessionReconnect(t *testing.T) {
+	conns := make(chan net.Conn, 2)
+	session, err := frames.NewSession(frames.SessionConfig{
+		Dial:         echoPeer(conns),
+		Capabilities: sessionCapabilities,
+		MinBackoff:   time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	for i, tc := range testCases {
+		if i == len(testCases)/2 {
+			// break the connection, the session must reconnect
+			(<-conns).Close()
+		}
+
+		if err := session.WriteFrame(tc.frame); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := session.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(got, tc.frame) {
+			t.Errorf("test %d: got frame % x, want frame % x", i, []byte(got), tc.frame)
+		}
+	}
+
+	if err := session.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := session.ReadFrame(); !errors.Is(err, frames.ErrSessionClosed) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrSessionClosed)
+	}
+}
+
+func TestSessionNegotiatedCodec(t *testing.T) {
+	capabilities := sessionCapabilities
+	capabilities.Checksums = []frames.ChecksumAlgorithm{frames.ChecksumXOR, frames.ChecksumXMODEM}
+	peer := capabilities
+	peer.Checksums = []frames.ChecksumAlgorithm{frames.ChecksumXMODEM}
+
+	conns := make(chan net.Conn, 2)
+	session, err := frames.NewSession(frames.SessionConfig{
+		Dial:         echoPeerWith(conns, peer),
+		Capabilities: capabilities,
+		MinBackoff:   time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	codec := session.Negotiation().Codec
+	if codec.ChecksumAlgorithm != frames.ChecksumXMODEM {
+		t.Fatalf("got checksum algorithm %v, want %v", codec.ChecksumAlgorithm, frames.ChecksumXMODEM)
+	}
+
+	for i, tc := range testCases {
+		if i == len(testCases)/2 {
+			// the frame must be encoded for the new connection too
+			(<-conns).Close()
+		}
+
+		if err := session.WriteFrame(tc.frame); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := session.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// received frames are decoded into the default format
+		if !bytes.Equal(got, tc.frame) {
+			t.Errorf("test %d: got frame % x, want frame % x", i, []byte(got), []byte(tc.frame))
+		}
+		if !bytes.Equal(got.Header(), tc.inputHeader[:]) || !bytes.Equal(got.Data(), tc.inputData) || !frames.Verify(got) {
+			t.Errorf("test %d: got header %q and data %q, valid %t", i, got.Header(), got.Data(), frames.Verify(got))
+		}
+	}
+
+	if stats := session.Stats(); stats.Reconnects != 1 {
+		t.Errorf("got %d reconnects, want 1", stats.Reconnects)
+	}
+
+	if err := session.WriteFrame(frames.Frame("LD1+x#")); !errors.As(err, new(*frames.VerifyError)) {
+		t.Errorf("got error %v, want *VerifyError", err)
+	}
+}
+
+// recordingPeer works like echoPeer, but instead of echoing frames, the peer
+// sends the frames it receives to received.
+func recordingPeer(conns chan<- net.Conn, received chan<- frames.Frame) func() (io.ReadWriteCloser, error) {
+	return func() (io.ReadWriteCloser, error) {
+		client, server := net.Pipe()
+		go func() {
+			if _, err := frames.Handshake(server, sessionCapabilities); err != nil {
+				server.Close()
+				return
+			}
+			conns <- server
+
+			reader := frames.NewReader(server, frames.Codec{}, frames.Limits{})
+			for {
+				frame, err := reader.ReadFrame()
+				if err != nil {
+					return
+				}
+				received <- frame
+			}
+		}()
+
+		return client, nil
+	}
+}
+
+func TestSessionAcknowledges(t *testing.T) {
+	conns := make(chan net.Conn, 2)
+	received := make(chan frames.Frame, 16)
+	session, err := frames.NewSession(frames.SessionConfig{
+		Dial:         recordingPeer(conns, received),
+		Capabilities: sessionCapabilities,
+		MinBackoff:   time.Millisecond,
+		Acknowledges: func(frame frames.Frame) int {
+			if string(frame.Header()) == "AK" {
+				return 1
+			}
+			return 0
+		},
+		MaxUnacknowledged: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	var written []frames.Frame
+	for i := 0; i < 4; i++ {
+		written = append(written, frames.Create([2]byte{'L', 'D'}, []byte{byte(i)}))
+	}
+
+	conn := <-conns
+	for i, frame := range written[:3] {
+		if err := session.WriteFrame(frame); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-received; !bytes.Equal(got, frame) {
+			t.Errorf("frame %d: got frame % x, want frame % x", i, []byte(got), []byte(frame))
+		}
+	}
+
+	// the first frame is dropped, the second one acknowledged
+	go conn.Write(frames.Create([2]byte{'A', 'K'}, nil))
+	if _, err := session.ReadFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if n := session.Unacknowledged(); n != 1 {
+		t.Errorf("got %d unacknowledged frames, want 1", n)
+	}
+
+	// the unacknowledged frame is written again before the next one
+	conn.Close()
+	if err := session.WriteFrame(written[3]); err != nil {
+		t.Fatal(err)
+	}
+	for i, frame := range written[2:] {
+		if got := <-received; !bytes.Equal(got, frame) {
+			t.Errorf("frame %d after reconnecting: got frame % x, want frame % x", i, []byte(got), []byte(frame))
+		}
+	}
+
+	stats := session.Stats()
+	if stats.Replayed != 1 || stats.Dropped != 1 || stats.FramesWritten != 5 {
+		t.Errorf("got %d replayed, %d dropped and %d written frames, want 1, 1 and 5", stats.Replayed, stats.Dropped, stats.FramesWritten)
+	}
+	if n := session.Unacknowledged(); n != 2 {
+		t.Errorf("got %d unacknowledged frames, want 2", n)
+	}
+}
+
+func TestSessionWriteFrameBefore(t *testing.T) {
+	conns := make(chan net.Conn, 2)
+	session, err := frames.NewSession(frames.SessionConfig{
+		Dial:         echoPeer(conns),
+		Capabilities: sessionCapabilities,
+		MinBackoff:   20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("stale"))
+
+	if err := session.WriteFrameBefore(frame, time.Now().Add(-time.Second)); !errors.Is(err, frames.ErrExpired) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrExpired)
+	}
+
+	// the link stalls for longer than the frame stays fresh
+	(<-conns).Close()
+	if err := session.WriteFrameBefore(frame, time.Now().Add(5*time.Millisecond)); !errors.Is(err, frames.ErrExpired) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrExpired)
+	}
+
+	if session.Expired() != 2 {
+		t.Errorf("got %d expired frames, want 2", session.Expired())
+	}
+
+	// fresh frames are still written after reconnecting
+	if err := session.WriteFrameBefore(frame, time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := session.ReadFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, frame) {
+		t.Errorf("got frame % x, want frame % x", []byte(got), []byte(frame))
+	}
+}
+
+func TestSessionWriteFrameBeforeLinkDown(t *testing.T) {
+	conns := make(chan net.Conn, 1)
+	dial := echoPeer(conns)
+	dialed := 0
+	session, err := frames.NewSession(frames.SessionConfig{
+		Dial: func() (io.ReadWriteCloser, error) {
+			// the link stays down after the first connection
+			if dialed++; dialed > 1 {
+				return nil, errors.New("link down")
+			}
+			return dial()
+		},
+		Capabilities: sessionCapabilities,
+		MinBackoff:   time.Millisecond,
+		MaxBackoff:   time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	(<-conns).Close()
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("stale"))
+	start := time.Now()
+	if err := session.WriteFrameBefore(frame, start.Add(20*time.Millisecond)); !errors.Is(err, frames.ErrExpired) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrExpired)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("got error after %v, want it soon after the deadline", elapsed)
+	}
+
+	if session.Expired() != 1 {
+		t.Errorf("got %d expired frames, want 1", session.Expired())
+	}
+}
+
+func TestSessionWriteFrameBeforeStalled(t *testing.T) {
+	session, err := frames.NewSession(frames.SessionConfig{
+		Dial: func() (io.ReadWriteCloser, error) {
+			// the peer stops reading after the handshake
+			client, server := net.Pipe()
+			go frames.Handshake(server, sessionCapabilities)
+			return client, nil
+		},
+		Capabilities: sessionCapabilities,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("stale"))
+	start := time.Now()
+	if err := session.WriteFrameBefore(frame, start.Add(20*time.Millisecond)); !errors.Is(err, frames.ErrExpired) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrExpired)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("got error after %v, want it soon after the deadline", elapsed)
+	}
+
+	// the connection is still usable, it is not replaced
+	if stats := session.Stats(); stats.Reconnects != 0 || stats.Expired != 1 {
+		t.Errorf("got %d reconnects and %d expired frames, want 0 and 1", stats.Reconnects, stats.Expired)
+	}
+}
+
+func TestSessionStats(t *testing.T) {
+	conns := make(chan net.Conn, 2)
+	session, err := frames.NewSession(frames.SessionConfig{
+		Dial:         echoPeer(conns),
+		Capabilities: sessionCapabilities,
+		MinBackoff:   time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("dondu"))
+
+	// garbage followed by a frame, echoed back by the peer
+	if err := session.WriteFrame(append(frames.Frame{'x', 'd'}, frame...)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.ReadFrame(); err != nil {
+		t.Fatal(err)
+	}
+
+	(<-conns).Close()
+	if err := session.WriteFrame(frame); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.ReadFrame(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := frames.Stats{
+		FramesRead:    2,
+		FramesWritten: 2,
+		BytesRead:     22,
+		BytesWritten:  24,
+		DataBytesRead: 10,
+		SkippedBytes:  2,
+		Reconnects:    1,
+		BitErrorRate:  1.0 / 192,
+	}
+	if got := session.Stats(); got != want {
+		t.Errorf("got stats %+v, want stats %+v", got, want)
+	}
+
+	// publishing again, e.g. when the test is run with -count, replaces the
+	// previously published session
+	session.Publish("frames_test_session")
+	got := expvar.Get("frames_test_session").String()
+	if !strings.Contains(got, `"Reconnects":1`) {
+		t.Errorf("got published stats %s, want them to include reconnects", got)
+	}
+}
+
+func TestSessionPublish(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		conns := make(chan net.Conn, 1)
+		session, err := frames.NewSession(frames.SessionConfig{
+			Dial:         echoPeer(conns),
+			Capabilities: sessionCapabilities,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer session.Close()
+
+		for j := 0; j <= i; j++ {
+			if err := session.WriteFrame(frames.Create([2]byte{'L', 'D'}, []byte("ok"))); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := session.ReadFrame(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		session.Publish("frames_test_session_publish")
+		got := expvar.Get("frames_test_session_publish").String()
+		if want := fmt.Sprintf(`"FramesWritten":%d`, i+1); !strings.Contains(got, want) {
+			t.Errorf("got published stats %s, want them to include %s", got, want)
+		}
+	}
+}
+
+func TestSessionLimits(t *testing.T) {
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("ok"))
+
+	t.Run("drop", func(t *testing.T) {
+		conns := make(chan net.Conn, 2)
+		session, err := frames.NewSession(frames.SessionConfig{
+			Dial:         echoPeer(conns),
+			Capabilities: sessionCapabilities,
+			MinBackoff:   time.Millisecond,
+			Limits:       frames.Limits{MaxDataLength: 4, Policy: frames.LimitDrop},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer session.Close()
+
+		long := frames.Create([2]byte{'L', 'D'}, []byte("dondu"))
+		if err := session.WriteFrame(append(long, frame...)); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := session.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(got, frame) {
+			t.Errorf("got frame % x, want frame % x", []byte(got), []byte(frame))
+		}
+
+		if stats := session.Stats(); stats.SkippedBytes != uint64(len(long)) || stats.Reconnects != 0 {
+			t.Errorf("got stats %+v, want %d skipped bytes and no reconnects", stats, len(long))
+		}
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		conns := make(chan net.Conn, 2)
+		session, err := frames.NewSession(frames.SessionConfig{
+			Dial:         echoPeer(conns),
+			Capabilities: sessionCapabilities,
+			MinBackoff:   time.Millisecond,
+			Limits:       frames.Limits{MaxGarbage: 4, Policy: frames.LimitAbort},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer session.Close()
+
+		if err := session.WriteFrame(append(frames.Frame("xxxxxxxx"), frame...)); err != nil {
+			t.Fatal(err)
+		}
+
+		// the session drops the connection flooded with garbage, the frame
+		// is written again after reconnecting
+		go func() {
+			for session.Stats().Reconnects == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			session.WriteFrame(frame)
+		}()
+
+		got, err := session.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(got, frame) {
+			t.Errorf("got frame % x, want frame % x", []byte(got), []byte(frame))
+		}
+	})
+}
+
+func TestSessionLinkQuality(t *testing.T) {
+	conns := make(chan net.Conn, 2)
+	session, err := frames.NewSession(frames.SessionConfig{
+		Dial:         echoPeer(conns),
+		Capabilities: sessionCapabilities,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("dondu"))
+	if err := session.WriteFrame(append(frames.Frame{'x', 'd'}, frame...)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.ReadFrame(); err != nil {
+		t.Fatal(err)
+	}
+
+	q := session.LinkQuality()
+	if q.Uptime <= 0 {
+		t.Fatalf("got uptime %v, want positive uptime", q.Uptime)
+	}
+
+	seconds := q.Uptime.Seconds()
+	if got := q.FramesPerSecond * seconds; math.Abs(got-2) > 1e-9 {
+		t.Errorf("got %v frames per second over %v, want 2 frames", q.FramesPerSecond, q.Uptime)
+	}
+	if got := q.Goodput * seconds; math.Abs(got-5) > 1e-9 {
+		t.Errorf("got goodput %v over %v, want 5 bytes", q.Goodput, q.Uptime)
+	}
+	if want := 2.0 / 13; q.ErrorRatio != want {
+		t.Errorf("got error ratio %v, want %v", q.ErrorRatio, want)
+	}
+}
+
+func TestSessionCorrectErrors(t *testing.T) {
+	conns := make(chan net.Conn, 2)
+	session, err := frames.NewSession(frames.SessionConfig{
+		Dial:          echoPeer(conns),
+		Capabilities:  sessionCapabilities,
+		CorrectErrors: true,
+		Source:        "echo",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("dondu"))
+	corrupted := frames.Recreate(frame)
+	corrupted[len(corrupted)-2] ^= 0x01 // hash sign
+
+	if err := session.WriteFrame(corrupted); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := session.ReadEnvelope()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got.Frame, frame) {
+		t.Errorf("got frame % x, want frame % x", []byte(got.Frame), []byte(frame))
+	}
+
+	if got.Source != "echo" || !got.Valid || !got.Corrected || got.Time.IsZero() {
+		t.Errorf("got envelope %+v, want a valid corrected frame from echo", got)
+	}
+
+	if stats := session.Stats(); stats.CorrectedFrames != 1 || stats.SkippedBytes != 0 {
+		t.Errorf("got stats %+v, want 1 corrected frame and no skipped bytes", stats)
+	}
+}
+
+func TestSessionSnapshotAndReset(t *testing.T) {
+	conns := make(chan net.Conn, 2)
+	session, err := frames.NewSession(frames.SessionConfig{
+		Dial:         echoPeer(conns),
+		Capabilities: sessionCapabilities,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("dondu"))
+	for i := 0; i < 2; i++ {
+		if err := session.WriteFrame(frame); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := session.ReadFrame(); err != nil {
+			t.Fatal(err)
+		}
+
+		snapshot := session.SnapshotAndReset()
+		if snapshot.FramesRead != 1 || snapshot.FramesWritten != 1 || snapshot.BytesRead != 11 {
+			t.Errorf("interval %d: got stats %+v, want 1 frame of 11 bytes each way", i, snapshot)
+		}
+	}
+
+	if got := session.Stats(); got.FramesRead != 0 || got.FramesWritten != 0 {
+		t.Errorf("got stats %+v after reset, want zero counters", got)
+	}
+}
+
+// stalledPeer performs the handshake and then reads nothing until resume is
+// closed.
+func stalledPeer(resume <-chan struct{}) func() (io.ReadWriteCloser, error) {
+	return func() (io.ReadWriteCloser, error) {
+		client, server := net.Pipe()
+		go func() {
+			if _, err := frames.Handshake(server, sessionCapabilities); err != nil {
+				server.Close()
+				return
+			}
+			<-resume
+			io.Copy(io.Discard, server)
+		}()
+
+		return client, nil
+	}
+}
+
+func TestSessionShutdown(t *testing.T) {
+	frame := frames.Create([2]byte{'L', 'D'}, []byte("off"))
+
+	for _, drain := range []bool{true, false} {
+		resume := make(chan struct{})
+		session, err := frames.NewSession(frames.SessionConfig{
+			Dial:         stalledPeer(resume),
+			Capabilities: sessionCapabilities,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		written := make(chan error)
+		go func() { written <- session.WriteFrame(frame) }()
+		time.Sleep(10 * time.Millisecond) // let the write block
+
+		if drain {
+			time.AfterFunc(10*time.Millisecond, func() { close(resume) })
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		dropped, err := session.Shutdown(ctx)
+		cancel()
+
+		if drain {
+			if dropped != 0 || err != nil {
+				t.Errorf("drain: got %d dropped frames and error %v, want none", dropped, err)
+			}
+			if err := <-written; err != nil {
+				t.Errorf("drain: got write error %v, want nil", err)
+			}
+		} else {
+			if dropped != 1 || !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("got %d dropped frames and error %v, want 1 and %v", dropped, err, context.DeadlineExceeded)
+			}
+			if err := <-written; !errors.Is(err, frames.ErrSessionClosed) {
+				t.Errorf("got write error %v, want %v", err, frames.ErrSessionClosed)
+			}
+			close(resume)
+		}
+
+		if err := session.WriteFrame(frame); !errors.Is(err, frames.ErrSessionClosed) {
+			t.Errorf("got error %v after shutdown, want %v", err, frames.ErrSessionClosed)
+		}
+	}
+}
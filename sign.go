@@ -0,0 +1,60 @@
+package frames
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"math"
+)
+
+// SignatureSize is the number of bytes Sign appends to frame's data.
+const SignatureSize = ed25519.SignatureSize
+
+// ErrSignature is returned when a frame's signature is missing or does not
+// match the public key.
+var ErrSignature = errors.New("frames: invalid signature")
+
+// Sign returns a new frame with the same header as frame and its data followed
+// by an Ed25519 signature of the header and the data. If frame's data length
+// plus SignatureSize overflows byte, it returns ErrDataLength.
+func Sign(frame Frame, key ed25519.PrivateKey) (Frame, error) {
+	if len(frame.Data())+SignatureSize > math.MaxUint8 {
+		return nil, ErrDataLength
+	}
+
+	var header [2]byte
+	copy(header[:], frame.Header())
+
+	signature := ed25519.Sign(key, signedMessage(frame.Header(), frame.Data()))
+	data := append(append([]byte{}, frame.Data()...), signature...)
+
+	return Create(header, data), nil
+}
+
+// VerifySignature checks the signature appended by Sign to frame's data against
+// key. If it matches, the frame without the signature is returned. The frame
+// itself must be valid, see Verify.
+func VerifySignature(frame Frame, key ed25519.PublicKey) (Frame, error) {
+	if err := Validate(frame); err != nil {
+		return nil, err
+	}
+
+	data := frame.Data()
+	if len(data) < SignatureSize {
+		return nil, ErrSignature
+	}
+
+	data, signature := data[:len(data)-SignatureSize], data[len(data)-SignatureSize:]
+	if !ed25519.Verify(key, signedMessage(frame.Header(), data), signature) {
+		return nil, ErrSignature
+	}
+
+	var header [2]byte
+	copy(header[:], frame.Header())
+
+	return Create(header, data), nil
+}
+
+// signedMessage returns the message covered by a frame's signature.
+func signedMessage(header, data []byte) []byte {
+	return append(append([]byte{}, header...), data...)
+}
@@ -0,0 +1,80 @@
+package frames_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestSign(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{0x42}, ed25519.SeedSize))
+	public := key.Public().(ed25519.PublicKey)
+	other := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{0x24}, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+
+	for i, tc := range testCases {
+		signed, err := frames.Sign(tc.frame, key)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if !frames.Verify(signed) {
+			t.Errorf("test %d: signed frame % x is invalid", i, []byte(signed))
+		}
+
+		got, err := frames.VerifySignature(signed, public)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+
+		if !bytes.Equal(got, tc.frame) {
+			t.Errorf("test %d: got frame % x, want frame % x", i, []byte(got), tc.frame)
+		}
+
+		if _, err := frames.VerifySignature(signed, other); !errors.Is(err, frames.ErrSignature) {
+			t.Errorf("test %d: got error %v with other key, want error %v", i, err, frames.ErrSignature)
+		}
+	}
+}
+
+func TestVerifySignatureTampered(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{0x42}, ed25519.SeedSize))
+	public := key.Public().(ed25519.PublicKey)
+
+	signed, err := frames.Sign(frames.Create([2]byte{'F', 'W'}, []byte("firmware")), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the checksum is recalculated, so only the signature can tell
+	tampered := frames.Create([2]byte{'F', 'X'}, signed.Data())
+	if _, err := frames.VerifySignature(tampered, public); !errors.Is(err, frames.ErrSignature) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrSignature)
+	}
+
+	short := frames.Create([2]byte{'F', 'W'}, []byte("firmware"))
+	if _, err := frames.VerifySignature(short, public); !errors.Is(err, frames.ErrSignature) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrSignature)
+	}
+
+	signed[len(signed)-1] ^= 0xff
+	var verifyErr *frames.VerifyError
+	if _, err := frames.VerifySignature(signed, public); !errors.As(err, &verifyErr) {
+		t.Errorf("got error %v, want *VerifyError", err)
+	}
+}
+
+func TestSignTooLong(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{0x42}, ed25519.SeedSize))
+
+	longest := frames.Create([2]byte{'F', 'W'}, make([]byte, 255-frames.SignatureSize))
+	if _, err := frames.Sign(longest, key); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+
+	tooLong := frames.Create([2]byte{'F', 'W'}, make([]byte, 256-frames.SignatureSize))
+	if _, err := frames.Sign(tooLong, key); err != frames.ErrDataLength {
+		t.Errorf("got error %v, want %v", err, frames.ErrDataLength)
+	}
+}
@@ -0,0 +1,135 @@
+package frames
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// sseBufferSize is how many frames may wait for a single slow client before
+// further frames are dropped for that client.
+const sseBufferSize = 64
+
+// SSEHandler is an http.Handler that streams frames to connected clients as
+// Server-Sent Events. Every event carries a single frame encoded as JSON.
+//
+// Frames are delivered to clients with Publish. Clients can request only
+// frames with specific headers using the "header" query parameter, which may
+// be repeated, e.g. "/frames?header=LD&header=MT".
+type SSEHandler struct {
+	mu      sync.Mutex
+	clients map[chan Frame]struct{}
+}
+
+// sseEvent is the JSON representation of a frame sent in a single event.
+type sseEvent struct {
+	Header   string `json:"header"`
+	Length   int    `json:"length"`
+	Data     string `json:"data"`
+	Checksum byte   `json:"checksum"`
+	Valid    bool   `json:"valid"`
+}
+
+// NewSSEHandler creates a new SSEHandler without any connected clients.
+func NewSSEHandler() *SSEHandler {
+	return &SSEHandler{clients: make(map[chan Frame]struct{})}
+}
+
+// Publish sends frame to all connected clients. It never blocks: clients that
+// do not keep up miss frames.
+func (h *SSEHandler) Publish(frame Frame) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.clients {
+		select {
+		case client <- frame:
+		default:
+		}
+	}
+}
+
+func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	headers := r.URL.Query()["header"]
+
+	client := make(chan Frame, sseBufferSize)
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, client)
+		h.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case frame := <-client:
+			if !matchHeader(frame, headers) {
+				continue
+			}
+
+			event, err := json.Marshal(newSSEEvent(frame))
+			if err != nil {
+				return
+			}
+
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// newSSEEvent describes frame. Frames shorter than an empty frame are described
+// as invalid with empty fields.
+func newSSEEvent(frame Frame) sseEvent {
+	if len(frame) < minFrameLen {
+		return sseEvent{Data: hex.EncodeToString(frame)}
+	}
+
+	return sseEvent{
+		Header:   string(frame.Header()),
+		Length:   frame.LenData(),
+		Data:     hex.EncodeToString(frame.Data()),
+		Checksum: frame.Checksum(),
+		Valid:    Verify(frame),
+	}
+}
+
+// matchHeader reports whether frame's header is one of headers. Empty headers
+// match every frame.
+func matchHeader(frame Frame, headers []string) bool {
+	if len(headers) == 0 {
+		return true
+	}
+
+	if len(frame) < 2 {
+		return false
+	}
+
+	for _, header := range headers {
+		if header == string(frame.Header()) {
+			return true
+		}
+	}
+
+	return false
+}
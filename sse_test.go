@@ -0,0 +1,45 @@
+package frames_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestSSEHandler(t *testing.T) {
+	handler := frames.NewSSEHandler()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?header=MT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("got content type %q, want %q", got, "text/event-stream")
+	}
+
+	for _, tc := range testCases {
+		handler.Publish(tc.frame)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	if !scanner.Scan() {
+		t.Fatal("no event received")
+	}
+
+	want := `data: {"header":"MT","length":5,"data":"646f6e6475","checksum":96,"valid":true}`
+	if got := scanner.Text(); got != want {
+		t.Errorf("got event %q, want %q", got, want)
+	}
+
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "" {
+		t.Errorf("event not terminated with an empty line")
+	}
+}
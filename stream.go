@@ -0,0 +1,189 @@
+package frames
+
+import (
+	"errors"
+	"io"
+)
+
+// readChunkSize is the number of bytes requested from the underlying reader by
+// a single read of frameReader.
+const readChunkSize = 512
+
+// readStoreSize is the initial size of frameReader's storage.
+const readStoreSize = 2 * readChunkSize
+
+// ErrLimitExceeded is returned when reading from a stream exceeds one of the
+// Limits and their policy is LimitAbort.
+var ErrLimitExceeded = errors.New("frames: decoder limit exceeded")
+
+// errTooLong marks a frame announcing more data than allowed by Limits.
+var errTooLong = errors.New("frames: frame too long")
+
+// LimitPolicy tells what happens when one of the Limits is exceeded.
+type LimitPolicy int
+
+const (
+	// LimitDrop drops the offending bytes and carries on reading.
+	LimitDrop LimitPolicy = iota
+
+	// LimitAbort makes reading fail with ErrLimitExceeded.
+	LimitAbort
+)
+
+// Limits guard a decoder reading frames from a stream against malfunctioning
+// or malicious peers. Zero values mean no limit.
+type Limits struct {
+	// MaxDataLength is the longest data a frame may announce in its length
+	// byte. Frames announcing more are treated as garbage.
+	MaxDataLength int
+
+	// MaxGarbage is the maximum number of bytes that may be skipped between
+	// two valid frames.
+	MaxGarbage int
+
+	// MaxPending is the maximum number of bytes that may be buffered while
+	// waiting for a frame to complete. When it is exceeded with LimitDrop,
+	// the whole buffer is dropped.
+	MaxPending int
+
+	// Policy applies to all limits.
+	Policy LimitPolicy
+}
+
+// frameReader reads frames encoded with codec from a byte stream, skipping
+// bytes that are not part of any frame.
+type frameReader struct {
+	r      io.Reader
+	codec  Codec
+	limits Limits
+	buf    []byte // pending bytes
+	store  []byte // backing storage of buf
+
+	// garbage counts bytes skipped since the last valid frame
+	garbage int
+
+	// onSkip, if not nil, is called with the number of skipped bytes
+	onSkip func(n int)
+
+	// correct enables repairing frames with Codec.Correct; onCorrect, if not
+	// nil, is called for every repaired frame
+	correct   bool
+	onCorrect func()
+
+	// corrected reports whether the last frame returned was repaired
+	corrected bool
+}
+
+func newFrameReader(r io.Reader, codec Codec, limits Limits) *frameReader {
+	return &frameReader{r: r, codec: codec, limits: limits, store: make([]byte, readStoreSize)}
+}
+
+// ReadFrame returns the next valid frame from the stream.
+func (fr *frameReader) ReadFrame() (Frame, error) {
+	frame, err := fr.next()
+	if err != nil {
+		return nil, err
+	}
+
+	return Recreate(frame), nil
+}
+
+// next returns the next valid frame from the stream. The frame may refer to
+// the reader's storage, so it is valid only until the next call.
+func (fr *frameReader) next() (Frame, error) {
+	for {
+		frame, n, corrected, err := fr.codec.parseFrame(fr.buf, fr.correct)
+		skipped := n - len(frame)
+		if err == nil && fr.tooLong(frame) {
+			frame, skipped, err = nil, n, errTooLong
+		} else if err != nil && fr.tooLong(fr.buf[n:]) {
+			// the incomplete frame would never be accepted, skip its start
+			n++
+			skipped, err = n, errTooLong
+		}
+		fr.buf = fr.buf[n:]
+
+		if err := fr.skip(skipped); err != nil {
+			return nil, err
+		}
+
+		if err == nil {
+			fr.corrected = corrected
+			if corrected && fr.onCorrect != nil {
+				fr.onCorrect()
+			}
+			fr.garbage = 0
+			return frame, nil
+		}
+
+		if err == errTooLong {
+			if fr.limits.Policy == LimitAbort {
+				return nil, ErrLimitExceeded
+			}
+			continue
+		}
+
+		if fr.limits.MaxPending > 0 && len(fr.buf) >= fr.limits.MaxPending {
+			if fr.limits.Policy == LimitAbort {
+				return nil, ErrLimitExceeded
+			}
+
+			pending := len(fr.buf)
+			fr.buf = fr.buf[:0]
+			if err := fr.skip(pending); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := fr.fill(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// fill reads more bytes from the stream. Pending bytes are moved to the front
+// of the storage first, so that it is reused instead of growing.
+func (fr *frameReader) fill() error {
+	store := fr.store
+	if len(fr.buf)+readChunkSize > len(store) {
+		store = make([]byte, 2*(len(fr.buf)+readChunkSize))
+	}
+	pending := copy(store, fr.buf)
+	fr.store = store
+
+	m, err := fr.r.Read(store[pending : pending+readChunkSize])
+	fr.buf = store[:pending+m]
+	if err != nil && m == 0 {
+		return err
+	}
+
+	return nil
+}
+
+// tooLong reports whether buf starts with a frame whose length byte exceeds
+// the limit.
+func (fr *frameReader) tooLong(buf []byte) bool {
+	length, present, _ := fr.codec.field(buf, 2)
+	return fr.limits.MaxDataLength > 0 && present && int(length) > fr.limits.MaxDataLength
+}
+
+// skip accounts for n skipped bytes.
+func (fr *frameReader) skip(n int) error {
+	if n == 0 {
+		return nil
+	}
+
+	if fr.onSkip != nil {
+		fr.onSkip(n)
+	}
+
+	fr.garbage += n
+	if fr.limits.MaxGarbage > 0 && fr.garbage > fr.limits.MaxGarbage {
+		fr.garbage = 0
+		if fr.limits.Policy == LimitAbort {
+			return ErrLimitExceeded
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package frames
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// PrintTable writes frames to w as an aligned table. Every row describes a
+// single frame: its index, header, length byte, data in hex and in ASCII,
+// checksum and whether it is valid.
+//
+// Frames shorter than an empty frame are printed with these columns left
+// empty.
+func PrintTable(w io.Writer, frames []Frame) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "INDEX\tHEADER\tLENGTH\tDATA\tASCII\tCHECKSUM\tVALID")
+
+	for i, frame := range frames {
+		if len(frame) < minFrameLen {
+			fmt.Fprintf(tw, "%d\t\t\t%x\t%s\t\t%t\n", i, []byte(frame), printableASCII(frame), false)
+			continue
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%x\t%s\t%02x\t%t\n",
+			i, frame.Header(), frame.LenData(), frame.Data(), printableASCII(frame.Data()), frame.Checksum(), Verify(frame))
+	}
+
+	return tw.Flush()
+}
+
+// printableASCII returns b as a string with every byte that is not printable
+// ASCII replaced by a dot.
+func printableASCII(b []byte) string {
+	s := make([]byte, len(b))
+	for i, c := range b {
+		if c >= ' ' && c <= '~' {
+			s[i] = c
+		} else {
+			s[i] = '.'
+		}
+	}
+
+	return string(s)
+}
@@ -0,0 +1,31 @@
+package frames_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestPrintTable(t *testing.T) {
+	input := []frames.Frame{
+		{'L', 'D', 0x1, '+', 'A', '#', 0x40},
+		{'M', 'T', 0x2, '+', 0x01, 'x', '#', 0x00},
+		{'x', 'd'},
+	}
+
+	want := "" +
+		"INDEX  HEADER  LENGTH  DATA  ASCII  CHECKSUM  VALID\n" +
+		"0      LD      1       41    A      40        true\n" +
+		"1      MT      2       0178  .x     00        false\n" +
+		"2                      7864  xd               false\n"
+
+	var buf bytes.Buffer
+	if err := frames.PrintTable(&buf, input); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Errorf("got table\n%s\nwant table\n%s", got, want)
+	}
+}
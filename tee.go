@@ -0,0 +1,22 @@
+package frames
+
+// TeeReader returns a function reading frames with read, e.g. Session.ReadFrame,
+// that also passes a copy of every frame to sink, e.g. to record traffic
+// without changing the code consuming the frames:
+//
+//	read := frames.TeeReader(session.ReadFrame, func(frame frames.Frame) {
+//		recorder.Record(frames.Received, frame)
+//	})
+//
+// Frames are returned unchanged, even if sink modifies its copy.
+func TeeReader(read func() (Frame, error), sink func(Frame)) func() (Frame, error) {
+	return func() (Frame, error) {
+		frame, err := read()
+		if err != nil {
+			return nil, err
+		}
+
+		sink(Recreate(frame))
+		return frame, nil
+	}
+}
@@ -0,0 +1,48 @@
+package frames_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestTeeReader(t *testing.T) {
+	var stream []byte
+	for _, tc := range testCases {
+		stream = append(stream, tc.frame...)
+	}
+
+	recorder := frames.NewFlightRecorder(len(testCases))
+	read := frames.TeeReader(frames.NewReader(bytes.NewReader(stream), frames.Codec{}, frames.Limits{}).ReadFrame, func(frame frames.Frame) {
+		recorder.Record(frames.Received, frame)
+		frame[0] = 'X'
+	})
+
+	for i, tc := range testCases {
+		got, err := read()
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+
+		if !bytes.Equal(got, tc.frame) {
+			t.Errorf("test %d: got frame % x, want frame % x", i, []byte(got), tc.frame)
+		}
+	}
+
+	if _, err := read(); err != io.EOF {
+		t.Errorf("got error %v, want %v", err, io.EOF)
+	}
+
+	records := recorder.Records()
+	if len(records) != len(testCases) {
+		t.Fatalf("got %d records, want %d", len(records), len(testCases))
+	}
+
+	for i, tc := range testCases {
+		if records[i].Direction != frames.Received || !bytes.Equal(records[i].Frame, tc.frame) {
+			t.Errorf("test %d: got record %v, want received frame % x", i, records[i], tc.frame)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package frames
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// Sizes of encoded timestamps in bytes.
+const (
+	EpochMillisSize = 8
+	BootMicrosSize  = 4
+)
+
+// bootMicrosPeriod is the time after which boot-relative timestamps wrap.
+const bootMicrosPeriod = (1 << 32) * time.Microsecond
+
+// PutEpochMillis encodes t into the first EpochMillisSize bytes of b in order,
+// as a signed number of milliseconds since the Unix epoch.
+func PutEpochMillis(b []byte, t time.Time, order binary.ByteOrder) {
+	order.PutUint64(b, uint64(t.UnixMilli()))
+}
+
+// GetEpochMillis decodes a time encoded with PutEpochMillis from the first
+// EpochMillisSize bytes of b in order.
+func GetEpochMillis(b []byte, order binary.ByteOrder) time.Time {
+	return time.UnixMilli(int64(order.Uint64(b)))
+}
+
+// PutBootMicros encodes d, the time since a device booted, into the first
+// BootMicrosSize bytes of b in order, as an unsigned number of microseconds.
+// Like microcontrollers' microsecond counters, it wraps about every 71.6
+// minutes, see BootClock.
+func PutBootMicros(b []byte, d time.Duration, order binary.ByteOrder) {
+	order.PutUint32(b, uint32(d.Microseconds()))
+}
+
+// GetBootMicros decodes a time encoded with PutBootMicros from the first
+// BootMicrosSize bytes of b in order. The result is less than the wrapping
+// period.
+func GetBootMicros(b []byte, order binary.ByteOrder) time.Duration {
+	return time.Duration(order.Uint32(b)) * time.Microsecond
+}
+
+// BootClock recovers the time since a device booted from boot-relative
+// timestamps, which wrap. It requires consecutive timestamps to be less than
+// the wrapping period apart. The zero value is ready to use.
+type BootClock struct {
+	last  time.Duration
+	epoch time.Duration // wrapping periods passed
+}
+
+// Elapsed returns the time since boot for a timestamp decoded with
+// GetBootMicros, which must not precede the previous one.
+func (c *BootClock) Elapsed(timestamp time.Duration) time.Duration {
+	if timestamp < c.last {
+		c.epoch += bootMicrosPeriod
+	}
+	c.last = timestamp
+
+	return c.epoch + timestamp
+}
@@ -0,0 +1,51 @@
+package frames_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestEpochMillis(t *testing.T) {
+	tm := time.Date(2021, 3, 14, 15, 9, 26, 535897932, time.UTC)
+
+	b := make([]byte, frames.EpochMillisSize)
+	frames.PutEpochMillis(b, tm, binary.BigEndian)
+	if want := []byte{0x00, 0x00, 0x01, 0x78, 0x31, 0x48, 0xee, 0x87}; !bytes.Equal(b, want) {
+		t.Errorf("got % x, want % x", b, want)
+	}
+
+	frame := frames.Create([2]byte{'T', 'S'}, b)
+	if got, want := frames.GetEpochMillis(frame.Data(), binary.BigEndian), tm.Truncate(time.Millisecond); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	before := time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC)
+	frames.PutEpochMillis(b, before, binary.LittleEndian)
+	if got := frames.GetEpochMillis(b, binary.LittleEndian); !got.Equal(before) {
+		t.Errorf("got %v, want %v", got, before)
+	}
+}
+
+func TestBootMicros(t *testing.T) {
+	b := make([]byte, frames.BootMicrosSize)
+	frames.PutBootMicros(b, 1500*time.Millisecond, binary.LittleEndian)
+	if want := []byte{0x60, 0xe3, 0x16, 0x00}; !bytes.Equal(b, want) {
+		t.Errorf("got % x, want % x", b, want)
+	}
+	if got := frames.GetBootMicros(b, binary.LittleEndian); got != 1500*time.Millisecond {
+		t.Errorf("got %v, want %v", got, 1500*time.Millisecond)
+	}
+
+	// the timestamp wraps, the clock does not
+	var clock frames.BootClock
+	for _, elapsed := range []time.Duration{time.Second, time.Hour, 2 * time.Hour, 3 * time.Hour, 4*time.Hour + 10*time.Minute} {
+		frames.PutBootMicros(b, elapsed, binary.BigEndian)
+		if got := clock.Elapsed(frames.GetBootMicros(b, binary.BigEndian)); got != elapsed {
+			t.Errorf("got %v, want %v", got, elapsed)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package frames
+
+import "errors"
+
+// ErrTLVTruncated is returned when TLV data ends in the middle of a field.
+var ErrTLVTruncated = errors.New("frames: truncated TLV field")
+
+// TLV is data made of type-length-value fields: a tag byte, a byte holding the
+// value's length and the value itself. It suits sparse messages, whose fields
+// may be omitted or come in any order.
+type TLV []byte
+
+// TLVField is a single field of TLV data.
+type TLVField struct {
+	Tag   byte
+	Value []byte
+}
+
+// AddField appends a field to t. If t would no longer fit in a frame's data,
+// ErrDataLength is returned and t is left unchanged.
+func (t *TLV) AddField(tag byte, value []byte) error {
+	if len(*t)+2+len(value) > 0xff {
+		return ErrDataLength
+	}
+
+	*t = append(*t, tag, byte(len(value)))
+	*t = append(*t, value...)
+
+	return nil
+}
+
+// Field returns the value of the first field tagged tag and whether it is
+// present.
+func (t TLV) Field(tag byte) (value []byte, ok bool) {
+	for it := t.Fields(); it.Next(); {
+		if it.Field().Tag == tag {
+			return it.Field().Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// Fields returns an iterator over the fields of t.
+func (t TLV) Fields() *TLVIterator {
+	return &TLVIterator{data: t}
+}
+
+// TLVIterator iterates over the fields of TLV data. Values returned by Field
+// refer to the data.
+//
+//	for it := frames.TLV(frame.Data()).Fields(); it.Next(); {
+//		field := it.Field()
+//		...
+//	}
+type TLVIterator struct {
+	data  []byte
+	field TLVField
+	err   error
+}
+
+// Next advances the iterator to the next field, which is then available
+// through Field. It returns false when there are no more fields or the data is
+// truncated, see Err.
+func (it *TLVIterator) Next() bool {
+	if len(it.data) == 0 || it.err != nil {
+		return false
+	}
+
+	if len(it.data) < 2 || len(it.data) < 2+int(it.data[1]) {
+		it.err = ErrTLVTruncated
+		return false
+	}
+
+	end := 2 + int(it.data[1])
+	it.field = TLVField{Tag: it.data[0], Value: it.data[2:end]}
+	it.data = it.data[end:]
+
+	return true
+}
+
+// Field returns the current field.
+func (it *TLVIterator) Field() TLVField {
+	return it.field
+}
+
+// Err returns ErrTLVTruncated if iteration stopped at a truncated field, or
+// nil.
+func (it *TLVIterator) Err() error {
+	return it.err
+}
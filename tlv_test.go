@@ -0,0 +1,81 @@
+package frames_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestTLV(t *testing.T) {
+	var data frames.TLV
+	if err := data.AddField('A', []byte{0x01, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+	if err := data.AddField('B', nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := data.AddField('C', []byte("xyz")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []byte{'A', 0x02, 0x01, 0x02, 'B', 0x00, 'C', 0x03, 'x', 'y', 'z'}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("got data % x, want % x", []byte(data), want)
+	}
+
+	frame := frames.Create([2]byte{'T', 'L'}, data)
+	it := frames.TLV(frame.Data()).Fields()
+	var got []frames.TLVField
+	for it.Next() {
+		got = append(got, it.Field())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	wantFields := []frames.TLVField{{'A', []byte{0x01, 0x02}}, {'B', []byte{}}, {'C', []byte("xyz")}}
+	if len(got) != len(wantFields) {
+		t.Fatalf("got %d fields, want %d", len(got), len(wantFields))
+	}
+	for i := range got {
+		if got[i].Tag != wantFields[i].Tag || !bytes.Equal(got[i].Value, wantFields[i].Value) {
+			t.Errorf("field %d: got %c % x, want %c % x", i, got[i].Tag, got[i].Value, wantFields[i].Tag, wantFields[i].Value)
+		}
+	}
+
+	if value, ok := data.Field('C'); !ok || !bytes.Equal(value, []byte("xyz")) {
+		t.Errorf("got field %q, %t, want %q, true", value, ok, "xyz")
+	}
+	if _, ok := data.Field('D'); ok {
+		t.Errorf("got field 'D', want none")
+	}
+}
+
+func TestTLVTruncated(t *testing.T) {
+	for _, data := range [][]byte{
+		{'A'},
+		{'A', 0x02, 0x01},
+		{'A', 0x00, 'B', 0x05, 0x01},
+	} {
+		it := frames.TLV(data).Fields()
+		for it.Next() {
+		}
+		if err := it.Err(); err != frames.ErrTLVTruncated {
+			t.Errorf("data % x: got error %v, want %v", data, err, frames.ErrTLVTruncated)
+		}
+	}
+}
+
+func TestTLVTooLong(t *testing.T) {
+	var data frames.TLV
+	if err := data.AddField('A', make([]byte, 0xfd)); err != nil {
+		t.Fatal(err)
+	}
+	if err := data.AddField('B', nil); err != frames.ErrDataLength {
+		t.Errorf("got error %v, want %v", err, frames.ErrDataLength)
+	}
+	if len(data) != 0xff {
+		t.Errorf("got length %d, want %d", len(data), 0xff)
+	}
+}
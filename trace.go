@@ -0,0 +1,79 @@
+package frames
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+)
+
+// traceEvent is a single event in the Chrome trace event format.
+type traceEvent struct {
+	Name      string      `json:"name"`
+	Category  string      `json:"cat,omitempty"`
+	Phase     string      `json:"ph"`
+	Timestamp float64     `json:"ts"`
+	Scope     string      `json:"s,omitempty"`
+	PID       int         `json:"pid"`
+	TID       int         `json:"tid"`
+	Args      interface{} `json:"args,omitempty"`
+}
+
+// traceFrameArgs are the arguments of a frame's event.
+type traceFrameArgs struct {
+	Length int    `json:"length"`
+	Data   string `json:"data"`
+	Valid  bool   `json:"valid"`
+}
+
+// traceInvalidTrack names the track of frames shorter than an empty frame.
+const traceInvalidTrack = "invalid"
+
+// WriteChromeTrace writes records to w as a JSON trace in the Chrome trace
+// event format, which can be opened in Perfetto or chrome://tracing. Every
+// frame is an instant event named after its direction, on a track named after
+// its header. Timestamps are relative to the first record.
+func WriteChromeTrace(w io.Writer, records []Record) error {
+	events := []traceEvent{}
+	tracks := make(map[string]int)
+
+	for _, record := range records {
+		track := traceInvalidTrack
+		args := traceFrameArgs{Data: hex.EncodeToString(record.Frame)}
+		if len(record.Frame) >= minFrameLen {
+			track = string(record.Frame.Header())
+			args = traceFrameArgs{
+				Length: record.Frame.LenData(),
+				Data:   hex.EncodeToString(record.Frame.Data()),
+				Valid:  Verify(record.Frame),
+			}
+		}
+
+		tid, ok := tracks[track]
+		if !ok {
+			tid = len(tracks) + 1
+			tracks[track] = tid
+			events = append(events, traceEvent{
+				Name:  "thread_name",
+				Phase: "M",
+				PID:   1,
+				TID:   tid,
+				Args:  map[string]string{"name": track},
+			})
+		}
+
+		events = append(events, traceEvent{
+			Name:      record.Direction.String(),
+			Category:  "frame",
+			Phase:     "i",
+			Timestamp: float64(record.Time.Sub(records[0].Time).Nanoseconds()) / 1000,
+			Scope:     "t",
+			PID:       1,
+			TID:       tid,
+			Args:      args,
+		})
+	}
+
+	return json.NewEncoder(w).Encode(struct {
+		TraceEvents []traceEvent `json:"traceEvents"`
+	}{events})
+}
@@ -0,0 +1,69 @@
+package frames_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestWriteChromeTrace(t *testing.T) {
+	start := time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC)
+	records := []frames.Record{
+		{Time: start, Direction: frames.Sent, Frame: frames.Create([2]byte{'L', 'D'}, []byte("on"))},
+		{Time: start.Add(1500 * time.Microsecond), Direction: frames.Received, Frame: frames.Create([2]byte{'M', 'T'}, []byte{0x01})},
+		{Time: start.Add(3 * time.Millisecond), Direction: frames.Received, Frame: frames.Frame{'x', 'd'}},
+		{Time: start.Add(4 * time.Millisecond), Direction: frames.Sent, Frame: frames.Create([2]byte{'L', 'D'}, []byte("off"))},
+	}
+
+	var buf bytes.Buffer
+	if err := frames.WriteChromeTrace(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+
+	var trace struct {
+		TraceEvents []struct {
+			Name  string                 `json:"name"`
+			Phase string                 `json:"ph"`
+			TS    float64                `json:"ts"`
+			TID   int                    `json:"tid"`
+			Args  map[string]interface{} `json:"args"`
+		} `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name  string
+		phase string
+		ts    float64
+		tid   int
+		arg   string
+	}{
+		{"thread_name", "M", 0, 1, "LD"},
+		{"tx", "i", 0, 1, "6f6e"},
+		{"thread_name", "M", 0, 2, "MT"},
+		{"rx", "i", 1500, 2, "01"},
+		{"thread_name", "M", 0, 3, "invalid"},
+		{"rx", "i", 3000, 3, "7864"},
+		{"tx", "i", 4000, 1, "6f6666"},
+	}
+
+	if len(trace.TraceEvents) != len(want) {
+		t.Fatalf("got %d events, want %d", len(trace.TraceEvents), len(want))
+	}
+
+	for i, event := range trace.TraceEvents {
+		arg := event.Args["data"]
+		if event.Phase == "M" {
+			arg = event.Args["name"]
+		}
+
+		if event.Name != want[i].name || event.Phase != want[i].phase || event.TS != want[i].ts || event.TID != want[i].tid || arg != want[i].arg {
+			t.Errorf("event %d: got %+v, want %+v", i, event, want[i])
+		}
+	}
+}
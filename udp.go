@@ -0,0 +1,186 @@
+package frames
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+)
+
+// maxDatagramSize is the size of the largest datagram a DatagramConn can
+// produce: a 2-byte sequence number followed by the longest possible frame.
+const maxDatagramSize = 2 + 2 + 1 + 1 + 255 + 2
+
+var (
+	// ErrShortDatagram is returned by DatagramConn.ReadFrame when a received
+	// datagram is too short to carry a sequence number.
+	ErrShortDatagram = errors.New("frames: datagram too short")
+
+	// ErrNoPeer is returned by DatagramConn.WriteFrame of a DatagramConn
+	// created by ListenUDP before it has received any datagram.
+	ErrNoPeer = errors.New("frames: no datagram received yet")
+)
+
+// DatagramConn sends and receives frames over a packet-oriented connection,
+// such as UDP. Each datagram carries exactly one frame.
+//
+// If sequencing is enabled, every datagram starts with a 2-byte big-endian
+// sequence number, which lets the receiving side detect lost datagrams. Both
+// sides must agree on whether sequencing is enabled.
+type DatagramConn struct {
+	conn     net.Conn
+	packet   net.PacketConn // set if conn is not connected to a peer
+	sequence bool
+
+	mu      sync.Mutex
+	peer    net.Addr // sender of the last datagram received by packet
+	sendSeq uint16
+	recvSeq uint16
+	started bool
+	lost    uint64
+}
+
+// NewDatagramConn creates a DatagramConn that uses conn to send and receive
+// datagrams.
+func NewDatagramConn(conn net.Conn, sequence bool) *DatagramConn {
+	return &DatagramConn{conn: conn, sequence: sequence}
+}
+
+// DialUDP connects to the UDP address addr and returns a DatagramConn that
+// can both send and receive frames.
+func DialUDP(addr string, sequence bool) (*DatagramConn, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDatagramConn(conn, sequence), nil
+}
+
+// ListenUDP listens on the UDP address addr and returns a DatagramConn that
+// receives frames sent to that address from any peer. Its WriteFrame sends
+// frames to the sender of the last received datagram, so that it can respond
+// to requests; before the first datagram is received, it returns ErrNoPeer.
+func ListenUDP(addr string, sequence bool) (*DatagramConn, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DatagramConn{conn: conn, packet: conn, sequence: sequence}, nil
+}
+
+// WriteFrame sends frame in a single datagram.
+func (c *DatagramConn) WriteFrame(frame Frame) error {
+	if !c.sequence {
+		return c.write(frame)
+	}
+
+	c.mu.Lock()
+	seq := c.sendSeq
+	c.sendSeq++
+	c.mu.Unlock()
+
+	buf := make([]byte, 2+len(frame))
+	binary.BigEndian.PutUint16(buf, seq)
+	copy(buf[2:], frame)
+
+	return c.write(buf)
+}
+
+// write sends b in a single datagram to the peer.
+func (c *DatagramConn) write(b []byte) error {
+	if c.packet == nil {
+		_, err := c.conn.Write(b)
+		return err
+	}
+
+	c.mu.Lock()
+	peer := c.peer
+	c.mu.Unlock()
+	if peer == nil {
+		return ErrNoPeer
+	}
+
+	_, err := c.packet.WriteTo(b, peer)
+	return err
+}
+
+// read receives a single datagram into b, remembering its sender if the
+// connection is not connected to a peer.
+func (c *DatagramConn) read(b []byte) (int, error) {
+	if c.packet == nil {
+		return c.conn.Read(b)
+	}
+
+	n, peer, err := c.packet.ReadFrom(b)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.peer = peer
+	c.mu.Unlock()
+
+	return n, nil
+}
+
+// ReadFrame receives a single datagram and returns the frame it carries. It
+// does not check whether the frame is correct. To check it, use Verify
+// function.
+func (c *DatagramConn) ReadFrame() (Frame, error) {
+	buf := make([]byte, maxDatagramSize)
+	n, err := c.read(buf)
+	if err != nil {
+		return nil, err
+	}
+	buf = buf[:n]
+
+	if !c.sequence {
+		return Recreate(buf), nil
+	}
+
+	if len(buf) < 2 {
+		return nil, ErrShortDatagram
+	}
+
+	seq := binary.BigEndian.Uint16(buf)
+	c.mu.Lock()
+	// Sequence numbers wrap around, so the gap is computed modulo 2^16.
+	// Datagrams arriving late or duplicated are neither counted as lost nor
+	// move the expected sequence number back.
+	gap := seq - c.recvSeq
+	if !c.started || gap < 1<<15 {
+		if c.started {
+			c.lost += uint64(gap)
+		}
+		c.recvSeq = seq + 1
+		c.started = true
+	}
+	c.mu.Unlock()
+
+	return Recreate(buf[2:]), nil
+}
+
+// Lost returns the number of datagrams detected as lost so far. It is always 0
+// if sequencing is disabled.
+func (c *DatagramConn) Lost() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lost
+}
+
+// LocalAddr returns the local network address.
+func (c *DatagramConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// Close closes the underlying connection.
+func (c *DatagramConn) Close() error {
+	return c.conn.Close()
+}
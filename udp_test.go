@@ -0,0 +1,123 @@
+package frames_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestDatagramConn(t *testing.T) {
+	for _, sequence := range []bool{false, true} {
+		testName := fmt.Sprintf("sequence %t", sequence)
+		t.Run(testName, func(t *testing.T) {
+			receiver, err := frames.ListenUDP("127.0.0.1:0", sequence)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer receiver.Close()
+
+			sender, err := frames.DialUDP(receiver.LocalAddr().String(), sequence)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sender.Close()
+
+			for i, tc := range testCases {
+				if err := sender.WriteFrame(tc.frame); err != nil {
+					t.Fatal(err)
+				}
+
+				got, err := receiver.ReadFrame()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !bytes.Equal(got, tc.frame) {
+					t.Errorf("test %d: got frame % x, want frame % x", i, []byte(got), tc.frame)
+				}
+			}
+
+			if receiver.Lost() != 0 {
+				t.Errorf("got %d lost datagrams, want 0", receiver.Lost())
+			}
+		})
+	}
+}
+
+func TestListenUDPRespond(t *testing.T) {
+	listener, err := frames.ListenUDP("127.0.0.1:0", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	if err := listener.WriteFrame(testCases[0].frame); !errors.Is(err, frames.ErrNoPeer) {
+		t.Errorf("got error %v, want error %v", err, frames.ErrNoPeer)
+	}
+
+	client, err := frames.DialUDP(listener.LocalAddr().String(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	for i, tc := range testCases {
+		if err := client.WriteFrame(tc.frame); err != nil {
+			t.Fatal(err)
+		}
+		request, err := listener.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the listener responds to the client
+		if err := listener.WriteFrame(request); err != nil {
+			t.Fatal(err)
+		}
+		got, err := client.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(got, tc.frame) {
+			t.Errorf("test %d: got frame % x, want frame % x", i, []byte(got), tc.frame)
+		}
+	}
+}
+
+func TestDatagramConnLost(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	receiver := frames.NewDatagramConn(server, true)
+
+	// sequence numbers 0, 1, 4, 2 (late), 5
+	datagrams := [][]byte{
+		{0x00, 0x00, 'L', 'D', 0x0, '+', '#', 0x00},
+		{0x00, 0x01, 'L', 'D', 0x0, '+', '#', 0x00},
+		{0x00, 0x04, 'L', 'D', 0x0, '+', '#', 0x00},
+		{0x00, 0x02, 'L', 'D', 0x0, '+', '#', 0x00},
+		{0x00, 0x05, 'L', 'D', 0x0, '+', '#', 0x00},
+	}
+
+	go func() {
+		for _, d := range datagrams {
+			client.Write(d)
+		}
+	}()
+
+	for range datagrams {
+		if _, err := receiver.ReadFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if receiver.Lost() != 2 {
+		t.Errorf("got %d lost datagrams, want 2", receiver.Lost())
+	}
+}
@@ -0,0 +1,172 @@
+package frames
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// QuantitySize is the number of bytes taken by an encoded Angle, Distance or
+// Temperature.
+const QuantitySize = 2
+
+// ErrQuantityRange is returned when a quantity cannot be encoded in
+// QuantitySize bytes.
+var ErrQuantityRange = errors.New("frames: quantity out of range")
+
+// Angle is an angle stored as an integer number of centidegrees. It is
+// encoded as an unsigned number of centidegrees normalized to [0°, 360°).
+//
+// To convert from degrees, multiply by Degree, e.g. 90 * frames.Degree.
+type Angle int32
+
+// Angle units.
+const (
+	Centidegree Angle = 1
+	Degree            = 100 * Centidegree
+)
+
+// AngleFromRadians returns the angle closest to rad radians.
+func AngleFromRadians(rad float64) Angle {
+	return Angle(math.Round(rad * 18000 / math.Pi))
+}
+
+// Degrees returns a in degrees.
+func (a Angle) Degrees() float64 {
+	return float64(a) / float64(Degree)
+}
+
+// Radians returns a in radians.
+func (a Angle) Radians() float64 {
+	return float64(a) * math.Pi / 18000
+}
+
+// Normalize returns a within [0°, 360°).
+func (a Angle) Normalize() Angle {
+	a %= 360 * Degree
+	if a < 0 {
+		a += 360 * Degree
+	}
+
+	return a
+}
+
+// Put encodes a into the first QuantitySize bytes of b in order.
+func (a Angle) Put(b []byte, order binary.ByteOrder) error {
+	order.PutUint16(b, uint16(a.Normalize()))
+	return nil
+}
+
+// GetAngle decodes an angle from the first QuantitySize bytes of b in order.
+func GetAngle(b []byte, order binary.ByteOrder) Angle {
+	return Angle(order.Uint16(b))
+}
+
+func (a Angle) String() string {
+	return fmt.Sprintf("%.2f°", a.Degrees())
+}
+
+// Distance is a distance stored as an integer number of millimeters. It is
+// encoded as an unsigned number of millimeters, up to 65.535 m.
+//
+// To convert from meters, multiply by Meter, e.g. 2 * frames.Meter.
+type Distance int32
+
+// Distance units.
+const (
+	Millimeter Distance = 1
+	Centimeter          = 10 * Millimeter
+	Meter               = 1000 * Millimeter
+)
+
+// DistanceFromMeters returns the distance closest to m meters.
+func DistanceFromMeters(m float64) Distance {
+	return Distance(math.Round(m * float64(Meter)))
+}
+
+// Meters returns d in meters.
+func (d Distance) Meters() float64 {
+	return float64(d) / float64(Meter)
+}
+
+// Put encodes d into the first QuantitySize bytes of b in order. If d is
+// negative or too long, it returns ErrQuantityRange.
+func (d Distance) Put(b []byte, order binary.ByteOrder) error {
+	if d < 0 || d > math.MaxUint16 {
+		return ErrQuantityRange
+	}
+
+	order.PutUint16(b, uint16(d))
+	return nil
+}
+
+// GetDistance decodes a distance from the first QuantitySize bytes of b in
+// order.
+func GetDistance(b []byte, order binary.ByteOrder) Distance {
+	return Distance(order.Uint16(b))
+}
+
+func (d Distance) String() string {
+	return fmt.Sprintf("%.3fm", d.Meters())
+}
+
+// Temperature is a temperature stored as an integer number of hundredths of a
+// degree Celsius. It is encoded as a signed number of centidegrees Celsius,
+// from -327.68 °C to 327.67 °C.
+//
+// To convert from degrees Celsius, multiply by Celsius, e.g.
+// 20 * frames.Celsius.
+type Temperature int32
+
+// Temperature units.
+const (
+	CentiCelsius Temperature = 1
+	Celsius                  = 100 * CentiCelsius
+)
+
+// TemperatureFromCelsius returns the temperature closest to c degrees Celsius.
+func TemperatureFromCelsius(c float64) Temperature {
+	return Temperature(math.Round(c * float64(Celsius)))
+}
+
+// TemperatureFromKelvin returns the temperature closest to k kelvins.
+func TemperatureFromKelvin(k float64) Temperature {
+	return TemperatureFromCelsius(k - 273.15)
+}
+
+// Celsius returns t in degrees Celsius.
+func (t Temperature) Celsius() float64 {
+	return float64(t) / float64(Celsius)
+}
+
+// Kelvin returns t in kelvins.
+func (t Temperature) Kelvin() float64 {
+	return t.Celsius() + 273.15
+}
+
+// Fahrenheit returns t in degrees Fahrenheit.
+func (t Temperature) Fahrenheit() float64 {
+	return t.Celsius()*9/5 + 32
+}
+
+// Put encodes t into the first QuantitySize bytes of b in order. If t does not
+// fit, it returns ErrQuantityRange.
+func (t Temperature) Put(b []byte, order binary.ByteOrder) error {
+	if t < math.MinInt16 || t > math.MaxInt16 {
+		return ErrQuantityRange
+	}
+
+	order.PutUint16(b, uint16(int16(t)))
+	return nil
+}
+
+// GetTemperature decodes a temperature from the first QuantitySize bytes of b
+// in order.
+func GetTemperature(b []byte, order binary.ByteOrder) Temperature {
+	return Temperature(int16(order.Uint16(b)))
+}
+
+func (t Temperature) String() string {
+	return fmt.Sprintf("%.2f°C", t.Celsius())
+}
@@ -0,0 +1,94 @@
+package frames_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestAngle(t *testing.T) {
+	if got, want := 90*frames.Degree+50*frames.Centidegree, frames.Angle(9050); got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+	if got := frames.AngleFromRadians(math.Pi / 2); got != 90*frames.Degree {
+		t.Errorf("got %v, want 90°", got)
+	}
+	if got := (45 * frames.Degree).Radians(); math.Abs(got-math.Pi/4) > 1e-12 {
+		t.Errorf("got %g rad, want %g", got, math.Pi/4)
+	}
+	if got, want := (-90 * frames.Degree).String(), "-90.00°"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	for angle, want := range map[frames.Angle][]byte{
+		90 * frames.Degree:    {0x23, 0x28},
+		-90 * frames.Degree:   {0x69, 0x78},
+		720 * frames.Degree:   {0x00, 0x00},
+		359*frames.Degree + 1: {0x8c, 0x3d},
+	} {
+		b := make([]byte, frames.QuantitySize)
+		if err := angle.Put(b, binary.BigEndian); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, want) {
+			t.Errorf("%v: got % x, want % x", angle, b, want)
+		}
+		if got := frames.GetAngle(b, binary.BigEndian); got != angle.Normalize() {
+			t.Errorf("got %v, want %v", got, angle.Normalize())
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	if got := frames.DistanceFromMeters(1.2345); got != 1235*frames.Millimeter {
+		t.Errorf("got %v, want 1.235m", got)
+	}
+	if got := (25 * frames.Centimeter).Meters(); got != 0.25 {
+		t.Errorf("got %gm, want 0.25m", got)
+	}
+
+	frame := frames.Create([2]byte{'L', 'D'}, []byte{0x10, 0x27})
+	if got := frames.GetDistance(frame.Data(), binary.LittleEndian); got != 10*frames.Meter {
+		t.Errorf("got %v, want 10m", got)
+	}
+
+	b := make([]byte, frames.QuantitySize)
+	if err := (65535 * frames.Millimeter).Put(b, binary.LittleEndian); err != nil || !bytes.Equal(b, []byte{0xff, 0xff}) {
+		t.Errorf("got % x, %v, want ff ff, nil", b, err)
+	}
+	for _, d := range []frames.Distance{-frames.Millimeter, 66 * frames.Meter} {
+		if err := d.Put(b, binary.LittleEndian); err != frames.ErrQuantityRange {
+			t.Errorf("%v: got error %v, want %v", d, err, frames.ErrQuantityRange)
+		}
+	}
+}
+
+func TestTemperature(t *testing.T) {
+	temp := frames.TemperatureFromCelsius(-12.345)
+	if temp != -1235*frames.CentiCelsius {
+		t.Errorf("got %v, want -12.35°C", temp)
+	}
+	if got := frames.TemperatureFromKelvin(300); got != 2685*frames.CentiCelsius {
+		t.Errorf("got %v, want 26.85°C", got)
+	}
+	if got := (100 * frames.Celsius).Fahrenheit(); got != 212 {
+		t.Errorf("got %g°F, want 212°F", got)
+	}
+	if got := (0 * frames.Celsius).Kelvin(); got != 273.15 {
+		t.Errorf("got %gK, want 273.15K", got)
+	}
+
+	b := make([]byte, frames.QuantitySize)
+	if err := temp.Put(b, binary.BigEndian); err != nil || !bytes.Equal(b, []byte{0xfb, 0x2d}) {
+		t.Errorf("got % x, %v, want fb 2d, nil", b, err)
+	}
+	if got := frames.GetTemperature(b, binary.BigEndian); got != temp {
+		t.Errorf("got %v, want %v", got, temp)
+	}
+	if err := (400 * frames.Celsius).Put(b, binary.BigEndian); err != frames.ErrQuantityRange {
+		t.Errorf("got error %v, want %v", err, frames.ErrQuantityRange)
+	}
+}
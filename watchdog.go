@@ -0,0 +1,73 @@
+package frames
+
+import "sync"
+
+// Watchdog counts consecutive link errors, such as invalid checksums or lost
+// synchronization, and triggers a recovery action when there are too many of
+// them in a row. Results of all link operations are reported to it with
+// Observe.
+//
+// Watchdog is safe for concurrent use. Its fields must not be changed after
+// the first call to Observe.
+type Watchdog struct {
+	// Threshold is the number of consecutive errors that triggers recovery.
+	Threshold int
+
+	// Recover is the recovery action, e.g. flushing buffers, toggling DTR or
+	// reconnecting.
+	Recover func() error
+
+	// OnError, if not nil, is called for every observed error with the number
+	// of consecutive errors so far.
+	OnError func(err error, consecutive int)
+
+	// OnRecover, if not nil, is called after every recovery attempt with the
+	// error returned by Recover.
+	OnRecover func(err error)
+
+	mu          sync.Mutex
+	consecutive int
+}
+
+// Observe reports the result of a link operation. A nil err resets the count
+// of consecutive errors. When the count reaches Threshold, Recover is called
+// and the count is reset. Callbacks are called without holding the watchdog's
+// lock, so they may call its methods; nil callbacks are skipped.
+func (w *Watchdog) Observe(err error) {
+	w.mu.Lock()
+	if err == nil {
+		w.consecutive = 0
+		w.mu.Unlock()
+		return
+	}
+
+	w.consecutive++
+	consecutive := w.consecutive
+	recovering := consecutive >= w.Threshold
+	if recovering {
+		w.consecutive = 0
+	}
+	w.mu.Unlock()
+
+	if w.OnError != nil {
+		w.OnError(err, consecutive)
+	}
+	if !recovering {
+		return
+	}
+
+	var recoverErr error
+	if w.Recover != nil {
+		recoverErr = w.Recover()
+	}
+	if w.OnRecover != nil {
+		w.OnRecover(recoverErr)
+	}
+}
+
+// Consecutive returns the current number of consecutive errors.
+func (w *Watchdog) Consecutive() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.consecutive
+}
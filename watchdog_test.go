@@ -0,0 +1,77 @@
+package frames_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+func TestWatchdog(t *testing.T) {
+	recoveries, errorsSeen, reported := 0, 0, 0
+	errFlush := errors.New("flush failed")
+
+	watchdog := frames.Watchdog{
+		Threshold: 3,
+		Recover: func() error {
+			recoveries++
+			return errFlush
+		},
+		OnError: func(err error, consecutive int) {
+			errorsSeen++
+		},
+		OnRecover: func(err error) {
+			if err == errFlush {
+				reported++
+			}
+		},
+	}
+
+	invalid := frames.Validate(frames.Frame{'L', 'D', 0x1, '+', 'A', '#', 0x00})
+
+	// errors interrupted by a success do not trigger recovery
+	for _, err := range []error{invalid, invalid, nil, invalid, invalid} {
+		watchdog.Observe(err)
+	}
+	if recoveries != 0 || watchdog.Consecutive() != 2 {
+		t.Fatalf("got %d recoveries and %d consecutive errors, want 0 and 2", recoveries, watchdog.Consecutive())
+	}
+
+	watchdog.Observe(invalid)
+	if recoveries != 1 || watchdog.Consecutive() != 0 {
+		t.Errorf("got %d recoveries and %d consecutive errors, want 1 and 0", recoveries, watchdog.Consecutive())
+	}
+
+	if errorsSeen != 5 || reported != 1 {
+		t.Errorf("got %d errors and %d recoveries reported, want 5 and 1", errorsSeen, reported)
+	}
+}
+
+func TestWatchdogCallbacks(t *testing.T) {
+	invalid := frames.Validate(frames.Frame{'L', 'D', 0x1, '+', 'A', '#', 0x00})
+
+	// callbacks may use the watchdog
+	var watchdog frames.Watchdog
+	watchdog.Threshold = 2
+	watchdog.OnError = func(err error, consecutive int) {
+		if got := watchdog.Consecutive(); got != consecutive && got != 0 {
+			t.Errorf("got %d consecutive errors in OnError, want %d", got, consecutive)
+		}
+	}
+	watchdog.OnRecover = func(err error) {
+		watchdog.Observe(nil)
+	}
+	for i := 0; i < 4; i++ {
+		watchdog.Observe(invalid)
+	}
+	if watchdog.Consecutive() != 0 {
+		t.Errorf("got %d consecutive errors, want 0", watchdog.Consecutive())
+	}
+
+	// a zero watchdog has no recovery action
+	var zero frames.Watchdog
+	zero.Observe(invalid)
+	if zero.Consecutive() != 0 {
+		t.Errorf("got %d consecutive errors, want 0", zero.Consecutive())
+	}
+}
@@ -0,0 +1,45 @@
+package frames
+
+import (
+	"io"
+	"sync"
+)
+
+// Writer writes frames to an underlying writer shared by several goroutines,
+// e.g. a serial port used for telemetry, commands and logs at once. Every frame
+// is written completely before the next one starts, so frames written
+// concurrently never interleave, even if the underlying writer accepts only a
+// part of a frame at a time.
+//
+// Writer is safe for concurrent use.
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriter creates a Writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteFrame writes frame. If the underlying writer accepts only a part of the
+// frame, WriteFrame writes the rest with further calls. If it accepts nothing
+// without returning an error, WriteFrame returns io.ErrShortWrite.
+func (w *Writer) WriteFrame(frame Frame) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for len(frame) > 0 {
+		n, err := w.w.Write(frame)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return io.ErrShortWrite
+		}
+
+		frame = frame[n:]
+	}
+
+	return nil
+}
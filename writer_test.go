@@ -0,0 +1,68 @@
+package frames_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/knei-knurow/frames"
+)
+
+// trickleWriter accepts at most 3 bytes per call.
+type trickleWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *trickleWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(p) > 3 {
+		p = p[:3]
+	}
+	return w.buf.Write(p)
+}
+
+func TestWriterConcurrent(t *testing.T) {
+	var tw trickleWriter
+	writer := frames.NewWriter(&tw)
+
+	const goroutines, perGoroutine = 8, 50
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			frame := frames.Create([2]byte{'G', byte('0' + g)}, bytes.Repeat([]byte{byte(g)}, 10))
+			for i := 0; i < perGoroutine; i++ {
+				if err := writer.WriteFrame(frame); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	buf := tw.buf.Bytes()
+	count := 0
+	for len(buf) > 0 {
+		frame, n, err := frames.ParseFrame(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(frame) {
+			t.Fatalf("got %d bytes between frames, want frames written back to back", n-len(frame))
+		}
+
+		buf = buf[n:]
+		count++
+	}
+
+	if count != goroutines*perGoroutine {
+		t.Errorf("got %d frames, want %d", count, goroutines*perGoroutine)
+	}
+}